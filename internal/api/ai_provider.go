@@ -304,7 +304,7 @@ func (s *Server) buildActiveBruteforceWordlist(ctx context.Context, projectID st
 	if maxWords <= 0 {
 		maxWords = 800
 	}
-	baselineWords := commonpkg.BuildBruteforceWordlist(passiveSubdomains, rootDomains, maxWords)
+	baselineWords := s.applyLearnedWordlist(projectID, commonpkg.BuildBruteforceWordlist(passiveSubdomains, rootDomains, maxWords), maxWords)
 
 	cfg, aiEnabled, _, err := s.resolveAISubdictRuntimeConfig(projectID)
 	if err != nil {
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"hunter/internal/db"
+	"hunter/internal/engine"
+	"hunter/internal/secrets"
+)
+
+type apiKeyResponse struct {
+	ID           int    `json:"id"`
+	ProjectID    string `json:"projectId,omitempty"`
+	Provider     string `json:"provider"`
+	Label        string `json:"label,omitempty"`
+	Hint         string `json:"hint"`
+	Enabled      bool   `json:"enabled"`
+	MonthlyLimit int    `json:"monthlyLimit"`
+	UsageCount   int64  `json:"usageCount"`
+	MonthUsage   int    `json:"monthUsage"`
+	Exhausted    bool   `json:"exhausted"`
+	LastUsedAt   string `json:"lastUsedAt,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+type apiKeysResponse struct {
+	Providers []string         `json:"providers"`
+	Keys      []apiKeyResponse `json:"keys"`
+}
+
+type apiKeyRequest struct {
+	ID           int     `json:"id"`
+	ProjectID    string  `json:"projectId"`
+	Provider     string  `json:"provider"`
+	Label        *string `json:"label"`
+	Key          string  `json:"key"`
+	Enabled      *bool   `json:"enabled"`
+	MonthlyLimit *int    `json:"monthlyLimit"`
+}
+
+func toAPIKeyResponse(key db.APIKey, now time.Time) apiKeyResponse {
+	resp := apiKeyResponse{
+		ID:           int(key.ID),
+		ProjectID:    key.ProjectID,
+		Provider:     key.Provider,
+		Label:        key.Label,
+		Hint:         key.Hint,
+		Enabled:      key.Enabled,
+		MonthlyLimit: key.MonthlyLimit,
+		UsageCount:   key.UsageCount,
+		Exhausted:    key.Exhausted(now),
+		LastUsedAt:   timePtrToISO(key.LastUsedAt),
+		CreatedAt:    timeToISO(key.CreatedAt),
+	}
+	if key.PeriodStart != nil && !key.PeriodStart.Before(db.MonthStart(now)) {
+		resp.MonthUsage = key.PeriodUsage
+	}
+	return resp
+}
+
+// apiKeyHint keeps the last four characters of key for display.
+func apiKeyHint(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// withAPIKeys attaches the managed API keys of projectID to ctx. For each
+// provider the enabled, non-exhausted key with the lowest monthly usage is
+// chosen, project keys before global ones. Every use is counted on the key.
+func (s *Server) withAPIKeys(ctx context.Context, projectID, jobID string) context.Context {
+	rows, err := s.db.ListAPIKeys(projectID, true)
+	if err != nil {
+		log.Printf("[APIKeys] load keys failed project=%s: %v", projectID, err)
+		return ctx
+	}
+	if len(rows) == 0 {
+		return ctx
+	}
+	box, err := secrets.NewBoxFromEnv()
+	if err != nil {
+		s.appendJobLogf(projectID, jobID, "warn", "Managed API keys not used: %v", err)
+		return ctx
+	}
+	now := time.Now()
+	chosen := make(map[string]bool, len(rows))
+	var keys []engine.Credential
+	var exhausted []string
+	for _, row := range rows {
+		if !row.Enabled || chosen[row.Provider] {
+			continue
+		}
+		if row.Exhausted(now) {
+			exhausted = append(exhausted, row.Provider+"#"+strconv.Itoa(int(row.ID)))
+			continue
+		}
+		value, err := box.Open(row.Secret)
+		if err != nil {
+			s.appendJobLogf(projectID, jobID, "warn", "API key %s#%d cannot be decrypted: %v", row.Provider, row.ID, err)
+			continue
+		}
+		chosen[row.Provider] = true
+		keys = append(keys, engine.Credential{ID: row.ID, Provider: row.Provider, Value: value})
+	}
+	if len(exhausted) > 0 {
+		s.appendJobLogf(projectID, jobID, "warn", "API keys over monthly limit skipped: %s", strings.Join(exhausted, ", "))
+	}
+	if len(keys) == 0 {
+		return ctx
+	}
+	creds := engine.NewCredentials(keys, func(c engine.Credential) {
+		if err := s.db.RecordAPIKeyUse(c.ID); err != nil {
+			log.Printf("[APIKeys] record use of key %d failed: %v", c.ID, err)
+		}
+	})
+	s.appendJobLogf(projectID, jobID, "info", "Managed API keys: %s", strings.Join(creds.Providers(), ", "))
+	return engine.WithCredentials(ctx, creds)
+}
+
+// handleAPIKeys manages passive-source API keys. Keys are encrypted with
+// SECRETS_KEY and never returned; responses carry a hint and usage counters.
+func (s *Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+		rows, err := s.db.ListAPIKeys(projectID, projectID != "")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		now := time.Now()
+		resp := apiKeysResponse{Providers: engine.CredentialProviders, Keys: make([]apiKeyResponse, 0, len(rows))}
+		for _, row := range rows {
+			resp.Keys = append(resp.Keys, toAPIKeyResponse(row, now))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodPost:
+		var req apiKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		provider := strings.ToLower(strings.TrimSpace(req.Provider))
+		if !engine.IsCredentialProvider(provider) {
+			writeError(w, http.StatusBadRequest, "unknown provider: "+req.Provider)
+			return
+		}
+		value := strings.TrimSpace(req.Key)
+		if value == "" {
+			writeError(w, http.StatusBadRequest, "key is required")
+			return
+		}
+		box, err := secrets.NewBoxFromEnv()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		sealed, err := box.Seal(value)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		key := db.APIKey{
+			ProjectID: strings.TrimSpace(req.ProjectID),
+			Provider:  provider,
+			Secret:    sealed,
+			Hint:      apiKeyHint(value),
+			Enabled:   req.Enabled == nil || *req.Enabled,
+		}
+		if req.Label != nil {
+			key.Label = strings.TrimSpace(*req.Label)
+		}
+		if req.MonthlyLimit != nil && *req.MonthlyLimit > 0 {
+			key.MonthlyLimit = *req.MonthlyLimit
+		}
+		if err := s.db.DB.Create(&key).Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit(key.ProjectID, actorFromRequest(r), "api_key_create", "api_key", strconv.Itoa(int(key.ID)), map[string]interface{}{
+			"provider": key.Provider, "label": key.Label,
+		}, r)
+		writeJSON(w, http.StatusOK, toAPIKeyResponse(key, time.Now()))
+	case http.MethodPut:
+		var req apiKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		var key db.APIKey
+		if err := s.db.DB.Where("id = ?", req.ID).First(&key).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				writeError(w, http.StatusNotFound, "api key not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		updates := map[string]interface{}{}
+		if req.Label != nil {
+			updates["label"] = strings.TrimSpace(*req.Label)
+		}
+		if req.Enabled != nil {
+			updates["enabled"] = *req.Enabled
+		}
+		if req.MonthlyLimit != nil {
+			updates["monthly_limit"] = clampIntRange(*req.MonthlyLimit, 0, 1<<30)
+		}
+		if value := strings.TrimSpace(req.Key); value != "" {
+			box, err := secrets.NewBoxFromEnv()
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			sealed, err := box.Seal(value)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			updates["secret"] = sealed
+			updates["hint"] = apiKeyHint(value)
+			updates["period_usage"] = 0
+		}
+		if len(updates) > 0 {
+			if err := s.db.DB.Model(&key).Updates(updates).Error; err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		if err := s.db.DB.Where("id = ?", key.ID).First(&key).Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit(key.ProjectID, actorFromRequest(r), "api_key_update", "api_key", strconv.Itoa(int(key.ID)), map[string]interface{}{
+			"provider": key.Provider, "rotated": strings.TrimSpace(req.Key) != "",
+		}, r)
+		writeJSON(w, http.StatusOK, toAPIKeyResponse(key, time.Now()))
+	case http.MethodDelete:
+		id, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("id")))
+		if err != nil || id <= 0 {
+			writeError(w, http.StatusBadRequest, "id is required")
+			return
+		}
+		var key db.APIKey
+		if err := s.db.DB.Where("id = ?", id).First(&key).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				writeError(w, http.StatusNotFound, "api key not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := s.db.DB.Delete(&key).Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit(key.ProjectID, actorFromRequest(r), "api_key_delete", "api_key", strconv.Itoa(id), map[string]interface{}{"provider": key.Provider}, r)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
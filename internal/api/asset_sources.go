@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"hunter/internal/db"
+)
+
+// assetSourceGroups expands source filter aliases to module names.
+var assetSourceGroups = map[string][]string{
+	"bruteforce": {"dnsx_bruteforce", "dns_bruteforce", "permutations"},
+	"passive":    {"subfinder", "chaos", "findomain", "bbot", "shosubgo", "ctlogs", "zonetransfer"},
+}
+
+type assetSourceStatResponse struct {
+	Source      string  `json:"source"`
+	Hosts       int64   `json:"hosts"`
+	UniqueHosts int64   `json:"uniqueHosts"`
+	LiveHosts   int64   `json:"liveHosts"`
+	Coverage    float64 `json:"coverage"`
+	FirstSeenAt string  `json:"firstSeenAt"`
+	LastSeenAt  string  `json:"lastSeenAt"`
+}
+
+type assetSourceStatsResponse struct {
+	ProjectID  string                    `json:"projectId"`
+	RootDomain string                    `json:"rootDomain,omitempty"`
+	TotalHosts int64                     `json:"totalHosts"`
+	Sources    []assetSourceStatResponse `json:"sources"`
+}
+
+// parseAssetSourceFilter splits the "source" query value into module names,
+// expanding group aliases such as "bruteforce".
+func parseAssetSourceFilter(raw string) []string {
+	seen := make(map[string]bool, 8)
+	out := make([]string, 0, 8)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		names, ok := assetSourceGroups[part]
+		if !ok {
+			names = []string{part}
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+// applyAssetSourceFilter keeps rows of table reported by any of sources.
+// With only set, rows also reported by any other source are dropped, which
+// answers "found only by bruteforce".
+func (s *Server) applyAssetSourceFilter(base *gorm.DB, table, projectID string, sources []string, only bool) *gorm.DB {
+	if len(sources) == 0 {
+		return base
+	}
+	match := s.db.DB.Model(&db.AssetSource{}).
+		Select("1").
+		Where("asset_sources.project_id = ? AND asset_sources.domain = "+table+".domain AND asset_sources.source IN ?", projectID, sources)
+	base = base.Where("EXISTS (?)", match)
+	if only {
+		other := s.db.DB.Model(&db.AssetSource{}).
+			Select("1").
+			Where("asset_sources.project_id = ? AND asset_sources.domain = "+table+".domain AND asset_sources.source NOT IN ?", projectID, sources)
+		base = base.Where("NOT EXISTS (?)", other)
+	}
+	return base
+}
+
+// attachAssetSources fills the Sources field of each item.
+func (s *Server) attachAssetSources(projectID string, items []assetResponse) {
+	if len(items) == 0 {
+		return
+	}
+	domains := make([]string, 0, len(items))
+	for _, it := range items {
+		if d := strings.ToLower(strings.TrimSpace(it.Domain)); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	bySource, err := s.db.ListAssetSources(projectID, domains)
+	if err != nil {
+		return
+	}
+	for i := range items {
+		rows := bySource[strings.ToLower(strings.TrimSpace(items[i].Domain))]
+		for _, row := range rows {
+			items[i].Sources = append(items[i].Sources, row.Source)
+		}
+	}
+}
+
+func (s *Server) handleAssetSourceStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+	rootDomain := normalizeRootDomain(r.URL.Query().Get("root_domain"))
+	stats, total, err := s.db.AssetSourceStats(projectID, rootDomain)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := assetSourceStatsResponse{
+		ProjectID:  projectID,
+		RootDomain: rootDomain,
+		TotalHosts: total,
+		Sources:    make([]assetSourceStatResponse, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		coverage := 0.0
+		if total > 0 {
+			coverage = float64(stat.Hosts) / float64(total)
+		}
+		resp.Sources = append(resp.Sources, assetSourceStatResponse{
+			Source:      stat.Source,
+			Hosts:       stat.Hosts,
+			UniqueHosts: stat.UniqueHosts,
+			LiveHosts:   stat.LiveHosts,
+			Coverage:    coverage,
+			FirstSeenAt: timeToISO(stat.FirstSeenAt),
+			LastSeenAt:  timeToISO(stat.LastSeenAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
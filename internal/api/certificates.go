@@ -0,0 +1,365 @@
+package api
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hunter/internal/db"
+	"hunter/internal/engine"
+	"hunter/internal/plugins"
+)
+
+// Monitor event types opened from the certificate inventory.
+const (
+	monitorEventCertExpiring   = "cert_expiring"
+	monitorEventCertSelfSigned = "cert_self_signed"
+	monitorEventCertChanged    = "cert_changed"
+)
+
+// defaultCertNonProdLabels mark a host as non-production when one of its
+// labels, or a dash-separated part of one, matches. CERT_NONPROD_LABELS
+// replaces the list.
+var defaultCertNonProdLabels = []string{"dev", "test", "testing", "qa", "uat", "stage", "staging", "preprod", "sandbox", "demo", "local", "lab"}
+
+type certificateResponse struct {
+	Host               string   `json:"host"`
+	Port               int      `json:"port"`
+	IP                 string   `json:"ip"`
+	URL                string   `json:"url"`
+	AssetID            int      `json:"assetId"`
+	PortID             int      `json:"portId"`
+	Subject            string   `json:"subject"`
+	SubjectCN          string   `json:"subjectCn"`
+	Issuer             string   `json:"issuer"`
+	IssuerCN           string   `json:"issuerCn"`
+	SANs               []string `json:"sans"`
+	NotBefore          string   `json:"notBefore"`
+	NotAfter           string   `json:"notAfter"`
+	DaysLeft           int      `json:"daysLeft"` // negative once expired
+	Serial             string   `json:"serial"`
+	KeyType            string   `json:"keyType"`
+	SignatureAlgorithm string   `json:"signatureAlgorithm"`
+	FingerprintSHA256  string   `json:"fingerprintSha256"`
+	SelfSigned         bool     `json:"selfSigned"`
+	Source             string   `json:"source"`
+	FirstSeenAt        string   `json:"firstSeenAt"`
+	ChangedAt          string   `json:"changedAt"`
+	LastSeen           string   `json:"lastSeen"`
+}
+
+func toCertificateResponses(rows []db.Certificate) []certificateResponse {
+	now := time.Now()
+	out := make([]certificateResponse, 0, len(rows))
+	for _, row := range rows {
+		sans := []string{}
+		if len(row.SANs) > 0 {
+			_ = json.Unmarshal(row.SANs, &sans)
+		}
+		out = append(out, certificateResponse{
+			Host:               row.Host,
+			Port:               row.Port,
+			IP:                 row.IP,
+			URL:                row.URL,
+			AssetID:            int(row.AssetID),
+			PortID:             int(row.PortID),
+			Subject:            row.Subject,
+			SubjectCN:          row.SubjectCN,
+			Issuer:             row.Issuer,
+			IssuerCN:           row.IssuerCN,
+			SANs:               sans,
+			NotBefore:          timeToISO(row.NotBefore),
+			NotAfter:           timeToISO(row.NotAfter),
+			DaysLeft:           certificateDaysLeft(row.NotAfter, now),
+			Serial:             row.Serial,
+			KeyType:            row.KeyType,
+			SignatureAlgorithm: row.SignatureAlgorithm,
+			FingerprintSHA256:  row.FingerprintSHA256,
+			SelfSigned:         row.SelfSigned,
+			Source:             row.Source,
+			FirstSeenAt:        timeToISO(row.FirstSeenAt),
+			ChangedAt:          timeToISO(row.ChangedAt),
+			LastSeen:           timeToISO(row.LastSeen),
+		})
+	}
+	return out
+}
+
+// certificateDaysLeft returns the whole days until notAfter, rounded down.
+func certificateDaysLeft(notAfter, now time.Time) int {
+	if notAfter.IsZero() {
+		return 0
+	}
+	return int(notAfter.Sub(now).Hours() / 24)
+}
+
+// saveCertificate stores a tls_certificate result and adds its in-scope
+// SANs to the candidate pool. It returns the decoded certificate and the
+// stored row as it was before, nil for an endpoint seen for the first time.
+func (s *Server) saveCertificate(scope *engine.Scope, projectID, rootDomain, jobID string, result engine.Result) (engine.TLSCertificate, *db.Certificate, error) {
+	cert, err := engine.DecodeResult[engine.TLSCertificate](result)
+	if err != nil {
+		return cert, nil, err
+	}
+	data := cert.Result().Data.(map[string]interface{})
+	data["project_id"] = projectID
+	data["root_domain"] = rootDomain
+	data["source_job_id"] = jobID
+	previous, err := s.db.SaveCertificate(data)
+	if err != nil {
+		return cert, nil, err
+	}
+	for _, san := range CertificateSANCandidates(scope, cert, rootDomain) {
+		sanRoot := plugins.ExtractRootDomain(san)
+		if err := s.db.SaveOrUpdateAssetCandidate(map[string]interface{}{
+			"project_id":    projectID,
+			"root_domain":   sanRoot,
+			"source_job_id": jobID,
+			"source_module": engine.ResultTypeTLSCertificate,
+			"domain":        san,
+			"verify_status": "pending",
+		}); err != nil {
+			log.Printf("[Scan][DB] save SAN candidate %s failed: %v", san, err)
+			continue
+		}
+		if err := s.db.RecordAssetSources(projectID, sanRoot, san, jobID, []string{engine.ResultTypeTLSCertificate}); err != nil {
+			log.Printf("[Scan][DB] record sources for %s failed: %v", san, err)
+		}
+	}
+	return cert, previous, nil
+}
+
+// saveCTCertificate stores a ct_certificate result under each of its
+// in-scope names.
+func (s *Server) saveCTCertificate(scope *engine.Scope, projectID, rootDomain, jobID string, result engine.Result) error {
+	cert, err := engine.DecodeResult[engine.CTCertificate](result)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(cert.Names))
+	for _, name := range cert.Names {
+		if scope.Allows(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	cert.Names = names
+	if cert.RootDomain == "" {
+		cert.RootDomain = rootDomain
+	}
+	data := cert.Result().Data.(map[string]interface{})
+	data["project_id"] = projectID
+	data["source_job_id"] = jobID
+	return s.db.SaveCTCertificate(data)
+}
+
+// CertificateSANCandidates returns the DNS names of cert other than its own
+// host that are worth verifying as assets. Wildcards are reduced to their
+// base name and IP SANs are skipped. With include rules the scope decides;
+// without them a name must also share the root domain of the host or of
+// rootDomain, so shared-hosting and CDN certificates do not flood the pool.
+func CertificateSANCandidates(scope *engine.Scope, cert engine.TLSCertificate, rootDomain string) []string {
+	host := strings.ToLower(strings.TrimSpace(cert.Host))
+	roots := map[string]bool{}
+	for _, root := range []string{plugins.ExtractRootDomain(host), normalizeRootDomain(rootDomain)} {
+		if root != "" {
+			roots[root] = true
+		}
+	}
+	var out []string
+	seen := map[string]bool{host: true}
+	for _, name := range cert.SANs {
+		name = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*."), ".")
+		if name == "" || seen[name] || !strings.Contains(name, ".") || net.ParseIP(name) != nil {
+			continue
+		}
+		seen[name] = true
+		if !scope.Allows(name) {
+			continue
+		}
+		if !scope.HasIncludes() && !roots[plugins.ExtractRootDomain(name)] {
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// syncMonitorCertificates stores the certificates of a monitor run and opens
+// events for certificates that expire within CERT_EXPIRY_WARN_DAYS,
+// self-signed certificates on production hosts and certificates replaced
+// since the last run. The first two are conditions: they are reported once
+// and resolved when the certificate is fixed. Changes are not reported on
+// baseline runs or for endpoints seen for the first time. It returns the
+// number of events opened.
+func (s *Server) syncMonitorCertificates(scope *engine.Scope, projectID, rootDomain, jobID string, runID uint, results []engine.Result, baseline bool) int {
+	warnBefore := time.Now().AddDate(0, 0, certExpiryWarnDays())
+	nonProd := certNonProdLabels()
+	opened := 0
+	for _, result := range results {
+		if result.Type != engine.ResultTypeTLSCertificate {
+			continue
+		}
+		if ok, reason := scope.CheckResult(result); !ok {
+			s.logOutOfScopeResult(projectID, jobID, result, reason)
+			continue
+		}
+		cert, previous, err := s.saveCertificate(scope, projectID, rootDomain, jobID, result)
+		if err != nil {
+			log.Printf("[Monitor] save certificate failed host=%s port=%d: %v", cert.Host, cert.Port, err)
+			continue
+		}
+		endpoint := net.JoinHostPort(cert.Host, strconv.Itoa(cert.Port))
+		event := monitorChangeEvent{host: cert.Host, ip: cert.IP, port: cert.Port, service: "tls"}
+
+		event.eventType = monitorEventCertExpiring
+		event.key = monitorEventCertExpiring + "|" + endpoint
+		if !cert.NotAfter.IsZero() && cert.NotAfter.Before(warnBefore) {
+			event.title = certificateExpiryTitle(endpoint, cert.NotAfter)
+			if s.holdMonitorEvent(projectID, rootDomain, runID, event) {
+				opened++
+			}
+		} else {
+			s.resolveMonitorEvent(projectID, runID, event.key)
+		}
+
+		event.eventType = monitorEventCertSelfSigned
+		event.key = monitorEventCertSelfSigned + "|" + endpoint
+		if cert.SelfSigned && isProductionHost(cert.Host, nonProd) {
+			event.title = fmt.Sprintf("Self-signed certificate on %s (%s)", endpoint, cmp.Or(cert.SubjectCN, cert.Subject))
+			if s.holdMonitorEvent(projectID, rootDomain, runID, event) {
+				opened++
+			}
+		} else {
+			s.resolveMonitorEvent(projectID, runID, event.key)
+		}
+
+		if baseline || previous == nil || previous.FingerprintSHA256 == cert.FingerprintSHA256 {
+			continue
+		}
+		event.eventType = monitorEventCertChanged
+		event.key = monitorEventCertChanged + "|" + endpoint
+		event.title = fmt.Sprintf("Certificate on %s changed: %s (%s) -> %s (%s), expires %s",
+			endpoint, shortFingerprint(previous.FingerprintSHA256), cmp.Or(previous.IssuerCN, previous.Issuer),
+			shortFingerprint(cert.FingerprintSHA256), cmp.Or(cert.IssuerCN, cert.Issuer), cert.NotAfter.Format("2006-01-02"))
+		if s.openMonitorEvent(projectID, rootDomain, runID, event) {
+			opened++
+		}
+	}
+	return opened
+}
+
+// withoutResultType returns results without those of type resultType.
+func withoutResultType(results []engine.Result, resultType string) []engine.Result {
+	out := make([]engine.Result, 0, len(results))
+	for _, result := range results {
+		if result.Type != resultType {
+			out = append(out, result)
+		}
+	}
+	return out
+}
+
+func certificateExpiryTitle(endpoint string, notAfter time.Time) string {
+	days := certificateDaysLeft(notAfter, time.Now())
+	if notAfter.Before(time.Now()) {
+		return fmt.Sprintf("Certificate on %s expired on %s", endpoint, notAfter.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Certificate on %s expires on %s (%d days)", endpoint, notAfter.Format("2006-01-02"), days)
+}
+
+func shortFingerprint(fingerprint string) string {
+	if len(fingerprint) > 16 {
+		return fingerprint[:16]
+	}
+	return fingerprint
+}
+
+// certExpiryWarnDays reads CERT_EXPIRY_WARN_DAYS, 30 by default.
+func certExpiryWarnDays() int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("CERT_EXPIRY_WARN_DAYS"))); err == nil && n > 0 {
+		return n
+	}
+	return 30
+}
+
+func certNonProdLabels() map[string]bool {
+	labels := defaultCertNonProdLabels
+	if raw := strings.TrimSpace(os.Getenv("CERT_NONPROD_LABELS")); raw != "" {
+		labels = strings.Split(raw, ",")
+	}
+	out := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		if label = strings.ToLower(strings.TrimSpace(label)); label != "" {
+			out[label] = true
+		}
+	}
+	return out
+}
+
+// isProductionHost reports whether host looks like a production name: a
+// hostname none of whose labels (or dash-separated parts of a label below
+// the root domain) is in nonProd. IP endpoints carry no name to judge by and
+// are not treated as production.
+func isProductionHost(host string, nonProd map[string]bool) bool {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || net.ParseIP(host) != nil {
+		return false
+	}
+	sub := strings.TrimSuffix(strings.TrimSuffix(host, plugins.ExtractRootDomain(host)), ".")
+	for _, label := range strings.Split(sub, ".") {
+		if nonProd[label] {
+			return false
+		}
+		for _, part := range strings.Split(label, "-") {
+			if nonProd[part] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// handleAssetCertificates lists the certificate inventory of a project.
+// host= restricts it to one host, expiring_days= to certificates expiring
+// within that many days (expired included), self_signed=1 to self-signed
+// ones.
+func (s *Server) handleAssetCertificates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	projectID := strings.TrimSpace(q.Get("project_id"))
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+	var expiresBefore *time.Time
+	if raw := strings.TrimSpace(q.Get("expiring_days")); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			writeError(w, http.StatusBadRequest, "expiring_days must be a non-negative integer")
+			return
+		}
+		before := time.Now().AddDate(0, 0, days)
+		expiresBefore = &before
+	}
+	selfSigned := isTruthy(q.Get("self_signed"))
+	limit := parseBoundedInt(q.Get("limit"), 200, 1, maxListRows)
+	rows, err := s.db.ListCertificates(projectID, strings.TrimSpace(q.Get("host")), expiresBefore, selfSigned, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toCertificateResponses(rows))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+
+	"hunter/internal/engine"
+)
+
+// scanCheckpointStore keeps completed stage outputs of one scan job in
+// scan_stages so a restarted or resumed job can skip them.
+type scanCheckpointStore struct {
+	server    *Server
+	projectID string
+	jobID     string
+	prefix    string
+}
+
+func newScanCheckpointStore(s *Server, projectID, jobID string) *scanCheckpointStore {
+	return &scanCheckpointStore{server: s, projectID: projectID, jobID: jobID}
+}
+
+// scoped returns a store whose stage names are namespaced by prefix.
+func (c *scanCheckpointStore) scoped(prefix string) *scanCheckpointStore {
+	if c == nil {
+		return nil
+	}
+	return &scanCheckpointStore{server: c.server, projectID: c.projectID, jobID: c.jobID, prefix: prefix + ":"}
+}
+
+// Load implements engine.CheckpointStore.
+func (c *scanCheckpointStore) Load(stage string) ([]engine.Result, bool) {
+	var results []engine.Result
+	if !c.load(stage, &results) {
+		return nil, false
+	}
+	return results, true
+}
+
+// Save implements engine.CheckpointStore.
+func (c *scanCheckpointStore) Save(stage string, inputCount int, results []engine.Result) {
+	kept := make([]engine.Result, 0, len(results))
+	for _, result := range results {
+		if result.Type == "plugin_status" {
+			continue
+		}
+		kept = append(kept, result)
+	}
+	c.save(stage, stage, inputCount, len(kept), kept)
+}
+
+func (c *scanCheckpointStore) loadDomains(stage string) ([]string, bool) {
+	var domains []string
+	if !c.load(stage, &domains) {
+		return nil, false
+	}
+	return domains, true
+}
+
+func (c *scanCheckpointStore) saveDomains(stage, module string, inputCount int, domains []string) {
+	c.save(stage, module, inputCount, len(domains), domains)
+}
+
+func (c *scanCheckpointStore) load(stage string, out interface{}) bool {
+	if c == nil {
+		return false
+	}
+	name := c.prefix + stage
+	row, err := c.server.db.GetScanStageCheckpoint(c.projectID, c.jobID, name)
+	if err != nil {
+		log.Printf("[Checkpoint] job=%s load %s failed: %v", c.jobID, name, err)
+		return false
+	}
+	if row == nil || len(row.Output) == 0 {
+		return false
+	}
+	if err := json.Unmarshal(row.Output, out); err != nil {
+		log.Printf("[Checkpoint] job=%s decode %s failed: %v", c.jobID, name, err)
+		return false
+	}
+	c.server.appendJobLogf(c.projectID, c.jobID, "info", "Checkpoint restored: stage=%s items=%d", name, row.OutputCount)
+	return true
+}
+
+func (c *scanCheckpointStore) save(stage, module string, inputCount, outputCount int, payload interface{}) {
+	if c == nil {
+		return
+	}
+	name := c.prefix + stage
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("[Checkpoint] job=%s encode %s failed: %v", c.jobID, name, err)
+		return
+	}
+	if err := c.server.db.SaveScanStageCheckpoint(c.projectID, c.jobID, name, module, inputCount, outputCount, raw); err != nil {
+		log.Printf("[Checkpoint] job=%s save %s failed: %v", c.jobID, name, err)
+		c.server.appendJobLogf(c.projectID, c.jobID, "warn", "Checkpoint save failed: stage=%s err=%v", name, err)
+		return
+	}
+	c.server.appendJobLogf(c.projectID, c.jobID, "debug", "Checkpoint saved: stage=%s items=%d", name, outputCount)
+}
+
+func domainsAsResults(domains []string) []engine.Result {
+	out := make([]engine.Result, 0, len(domains))
+	for _, domain := range domains {
+		out = append(out, engine.Result{Type: "domain", Data: domain})
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"hunter/internal/db"
+	"hunter/internal/engine"
+	"hunter/internal/plugins"
+)
+
+// Monitor event types opened by DNS record changes.
+const (
+	monitorEventDNSCNAMEChanged = "dns_cname_changed"
+	monitorEventDNSMXAdded      = "dns_mx_added"
+	monitorEventDNSNSChanged    = "dns_ns_changed"
+	monitorEventDNSSPFChanged   = "dns_spf_changed"
+)
+
+type dnsRecordResponse struct {
+	Host        string `json:"host"`
+	RootDomain  string `json:"rootDomain"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	JobID       string `json:"jobId"`
+	FirstSeenAt string `json:"firstSeenAt"`
+	LastSeenAt  string `json:"lastSeenAt"`
+}
+
+// collectDNSRecords resolves the record inventory of hosts. Results are
+// passed to emit as they are produced.
+func (s *Server) collectDNSRecords(ctx context.Context, projectID, jobID string, hosts []string, dnsResolvers string, emit engine.ResultHandler) []engine.Result {
+	s.appendJobLogf(projectID, jobID, "info", "Stage started: DNS records (hosts=%d)", len(hosts))
+	results, err := engine.ExecuteScanner(ctx, plugins.NewDNSRecordsPlugin(dnsResolvers), hosts, emit)
+	if err != nil {
+		s.appendJobLogf(projectID, jobID, "warn", "DNS records warning: %v", err)
+	}
+	s.appendJobLogf(projectID, jobID, "info", "DNS records done: hosts_with_records=%d", len(results))
+	return results
+}
+
+// syncMonitorDNSRecords stores the DNS inventory of a monitor run and opens
+// monitor events for CNAME retargets, new MX hosts, NS changes and SPF
+// changes. Hosts seen for the first time and baseline runs only store
+// records. It returns the number of events opened.
+func (s *Server) syncMonitorDNSRecords(projectID, rootDomain, jobID string, runID uint, results []engine.Result, baseline bool) int {
+	opened := 0
+	for _, result := range results {
+		if result.Type != engine.ResultTypeDNSRecords {
+			continue
+		}
+		records, err := engine.DecodeResult[engine.DNSRecords](result)
+		if err != nil {
+			log.Printf("[Monitor] decode dns records failed: %v", err)
+			continue
+		}
+		changes, known, err := s.db.SyncDNSRecords(projectID, rootDomain, records.Host, jobID, records.ByType(), records.Failed)
+		if err != nil {
+			log.Printf("[Monitor] save dns records failed host=%s: %v", records.Host, err)
+			continue
+		}
+		if baseline || !known {
+			continue
+		}
+		for _, event := range dnsChangeEvents(changes) {
+			if s.openMonitorEvent(projectID, rootDomain, runID, event) {
+				opened++
+			}
+		}
+	}
+	return opened
+}
+
+// dnsChangeEvents maps record changes to monitor events. A/AAAA and
+// non-SPF TXT churn is recorded in dns_records but does not open events.
+func dnsChangeEvents(changes []db.DNSRecordChange) []monitorChangeEvent {
+	var out []monitorChangeEvent
+	for _, change := range changes {
+		switch change.RecordType {
+		case "CNAME":
+			out = append(out, monitorChangeEvent{
+				eventType: monitorEventDNSCNAMEChanged,
+				key:       monitorEventDNSCNAMEChanged + "|" + change.Host,
+				host:      change.Host,
+				service:   change.RecordType,
+				title:     fmt.Sprintf("CNAME %s -> %s", joinOrNone(change.Removed), joinOrNone(change.Added)),
+			})
+		case "MX":
+			for _, mx := range change.Added {
+				out = append(out, monitorChangeEvent{
+					eventType: monitorEventDNSMXAdded,
+					key:       monitorEventDNSMXAdded + "|" + change.Host + "|" + mx,
+					host:      change.Host,
+					service:   change.RecordType,
+					title:     "MX added: " + mx,
+				})
+			}
+		case "NS":
+			out = append(out, monitorChangeEvent{
+				eventType: monitorEventDNSNSChanged,
+				key:       monitorEventDNSNSChanged + "|" + change.Host,
+				host:      change.Host,
+				service:   change.RecordType,
+				title:     fmt.Sprintf("NS %s -> %s", joinOrNone(change.Removed), joinOrNone(change.Added)),
+			})
+		case "TXT":
+			added, removed := spfRecords(change.Added), spfRecords(change.Removed)
+			if len(added) == 0 && len(removed) == 0 {
+				continue
+			}
+			out = append(out, monitorChangeEvent{
+				eventType: monitorEventDNSSPFChanged,
+				key:       monitorEventDNSSPFChanged + "|" + change.Host,
+				host:      change.Host,
+				service:   change.RecordType,
+				title:     fmt.Sprintf("SPF %s -> %s", joinOrNone(removed), joinOrNone(added)),
+			})
+		}
+	}
+	return out
+}
+
+func spfRecords(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(v)), "v=spf1") {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return strings.Join(values, ", ")
+}
+
+func toDNSRecordResponses(rows []db.DNSRecord) []dnsRecordResponse {
+	out := make([]dnsRecordResponse, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, dnsRecordResponse{
+			Host:        row.Host,
+			RootDomain:  row.RootDomain,
+			Type:        row.RecordType,
+			Value:       row.Value,
+			JobID:       row.JobID,
+			FirstSeenAt: timeToISO(row.FirstSeenAt),
+			LastSeenAt:  timeToISO(row.LastSeenAt),
+		})
+	}
+	return out
+}
+
+func (s *Server) handleDNSRecords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+	rows, err := s.db.ListDNSRecords(
+		projectID,
+		r.URL.Query().Get("domain"),
+		normalizeRootDomain(r.URL.Query().Get("root_domain")),
+		r.URL.Query().Get("type"),
+		maxListRows,
+	)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toDNSRecordResponses(rows))
+}
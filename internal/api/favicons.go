@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// faviconGroupSampleHosts caps the hosts listed per favicon group.
+const faviconGroupSampleHosts = 20
+
+// faviconLabelCache holds the hash-to-product mapping named by
+// FAVICON_HASH_DB and reloads it when the file changes.
+type faviconLabelCache struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	labels  map[string]string
+}
+
+type faviconHostResponse struct {
+	AssetID    int    `json:"assetId"`
+	Domain     string `json:"domain"`
+	URL        string `json:"url"`
+	FaviconURL string `json:"faviconUrl"`
+	LastSeen   string `json:"lastSeen"`
+}
+
+type faviconGroupResponse struct {
+	MMH3     string                `json:"mmh3"`
+	MD5      string                `json:"md5"`
+	Product  string                `json:"product,omitempty"`
+	Hosts    int64                 `json:"hosts"`
+	LastSeen string                `json:"lastSeen"`
+	Items    []faviconHostResponse `json:"items"`
+}
+
+type faviconGroupsResponse struct {
+	LabelsFile string                 `json:"labelsFile,omitempty"`
+	Groups     []faviconGroupResponse `json:"groups"`
+}
+
+// faviconLabels returns the hash-to-product mapping, keyed by mmh3 hash or
+// lower-case MD5. It is empty when FAVICON_HASH_DB is not set; a file that
+// cannot be read is logged and treated as empty.
+func (s *Server) faviconLabels() (map[string]string, string) {
+	path := strings.TrimSpace(os.Getenv("FAVICON_HASH_DB"))
+	if path == "" {
+		return nil, ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("[Favicon] hash database %s: %v", path, err)
+		return nil, path
+	}
+	c := &s.faviconData
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.labels != nil && c.path == path && c.modTime.Equal(info.ModTime()) {
+		return c.labels, path
+	}
+	labels, err := loadFaviconLabels(path)
+	if err != nil {
+		log.Printf("[Favicon] hash database %s: %v", path, err)
+		return nil, path
+	}
+	c.path, c.modTime, c.labels = path, info.ModTime(), labels
+	log.Printf("[Favicon] loaded %s: hashes=%d", path, len(labels))
+	return labels, path
+}
+
+// loadFaviconLabels reads a JSON object {"<hash>": "<product>"} or a text
+// file with one "<hash>,<product>" (or tab-separated) entry per line.
+// Hashes are Shodan mmh3 values or MD5 hex digests; # starts a comment.
+func loadFaviconLabels(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string)
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "{") {
+		var obj map[string]string
+		if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+			return nil, err
+		}
+		for hash, product := range obj {
+			addFaviconLabel(labels, hash, product)
+		}
+		return labels, nil
+	}
+	sc := bufio.NewScanner(strings.NewReader(string(raw)))
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, product, ok := strings.Cut(text, "\t")
+		if !ok {
+			hash, product, ok = strings.Cut(text, ",")
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected <hash>,<product>", line)
+		}
+		addFaviconLabel(labels, hash, product)
+	}
+	return labels, sc.Err()
+}
+
+func addFaviconLabel(labels map[string]string, hash, product string) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	product = strings.TrimSpace(product)
+	if hash != "" && product != "" {
+		labels[hash] = product
+	}
+}
+
+// faviconProduct looks a favicon up by mmh3 hash, then by MD5.
+func faviconProduct(labels map[string]string, mmh3, md5 string) string {
+	if product := labels[mmh3]; product != "" {
+		return product
+	}
+	if md5 != "" {
+		return labels[strings.ToLower(md5)]
+	}
+	return ""
+}
+
+// labelWebResponses fills FaviconProduct of each item.
+func (s *Server) labelWebResponses(items []webResponseResponse) {
+	labels, _ := s.faviconLabels()
+	if len(labels) == 0 {
+		return
+	}
+	for i := range items {
+		if items[i].FaviconMMH3 != "" {
+			items[i].FaviconProduct = faviconProduct(labels, items[i].FaviconMMH3, items[i].FaviconMD5)
+		}
+	}
+}
+
+// handleAssetFavicons groups the hosts of a project by favicon hash.
+// hash= restricts the result to one favicon, to pivot on it.
+func (s *Server) handleAssetFavicons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+	hash := strings.TrimSpace(r.URL.Query().Get("hash"))
+	limit := parseBoundedInt(r.URL.Query().Get("limit"), 50, 1, 500)
+	groups, err := s.db.FaviconGroups(projectID, hash, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	labels, path := s.faviconLabels()
+	resp := faviconGroupsResponse{LabelsFile: path, Groups: make([]faviconGroupResponse, 0, len(groups))}
+	for _, g := range groups {
+		rows, err := s.db.ListFaviconResponses(projectID, g.MMH3, faviconGroupSampleHosts)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		group := faviconGroupResponse{
+			MMH3:     g.MMH3,
+			MD5:      g.MD5,
+			Product:  faviconProduct(labels, g.MMH3, g.MD5),
+			Hosts:    g.Hosts,
+			LastSeen: timeToISO(g.LastSeen),
+			Items:    make([]faviconHostResponse, 0, len(rows)),
+		}
+		for _, row := range rows {
+			group.Items = append(group.Items, faviconHostResponse{
+				AssetID:    int(row.AssetID),
+				Domain:     row.Domain,
+				URL:        row.URL,
+				FaviconURL: row.FaviconURL,
+				LastSeen:   timeToISO(row.LastSeen),
+			})
+		}
+		resp.Groups = append(resp.Groups, group)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
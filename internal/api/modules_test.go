@@ -0,0 +1,39 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"hunter/internal/plugins"
+)
+
+func TestEveryPluginSelectsAStage(t *testing.T) {
+	for _, info := range plugins.ListPlugins() {
+		for _, name := range append([]string{info.Name}, info.Aliases...) {
+			if !isValidModule(name) {
+				t.Errorf("plugin module %q selects no scan stage", name)
+			}
+		}
+	}
+}
+
+func TestResolveScanStages(t *testing.T) {
+	tests := []struct {
+		modules []string
+		want    scanStages
+	}{
+		{[]string{"chaos"}, scanStages{PassiveSubs: true, Subs: true}},
+		{[]string{"http_probe"}, scanStages{Httpx: true}},
+		{[]string{"tscan"}, scanStages{Ports: true}},
+		{[]string{"tls_grab"}, scanStages{Ports: true}},
+		{[]string{"cors"}, scanStages{Cors: true, Httpx: true}},
+	}
+	for _, tt := range tests {
+		if got := resolveScanStages(tt.modules, false, false); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolveScanStages(%v) = %+v, want %+v", tt.modules, got, tt.want)
+		}
+	}
+	if got := unknownModules([]string{"httpx", "Bogus", "monitor"}); !reflect.DeepEqual(got, []string{"bogus"}) {
+		t.Errorf("unknownModules() = %v, want [bogus]", got)
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"hunter/internal/db"
+)
+
+// monitorChangeEvent is one monitor event derived from a change in the
+// inventory (DNS records, certificates) rather than from the live snapshot.
+type monitorChangeEvent struct {
+	eventType string
+	key       string
+	host      string
+	ip        string
+	port      int
+	service   string
+	title     string
+}
+
+// openMonitorEvent creates or reopens the event for e and records it as an
+// asset change of the run.
+func (s *Server) openMonitorEvent(projectID, rootDomain string, runID uint, e monitorChangeEvent) bool {
+	now := time.Now()
+	var existing db.MonitorEvent
+	err := s.db.DB.Where("project_id = ? AND event_key = ?", projectID, e.key).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		event := db.MonitorEvent{
+			ProjectID:       projectID,
+			RootDomain:      rootDomain,
+			EventKey:        e.key,
+			EventType:       e.eventType,
+			Status:          monitorEventStatusOpen,
+			Domain:          e.host,
+			IP:              e.ip,
+			Port:            e.port,
+			Service:         e.service,
+			Title:           e.title,
+			FirstSeenAt:     now,
+			LastSeenAt:      now,
+			LastChangedAt:   now,
+			OccurrenceCount: 1,
+			LastRunID:       runID,
+		}
+		if err := s.db.DB.Create(&event).Error; err != nil {
+			log.Printf("[Monitor] create %s event failed key=%s: %v", e.eventType, e.key, err)
+			return false
+		}
+	case err != nil:
+		log.Printf("[Monitor] query %s event failed key=%s: %v", e.eventType, e.key, err)
+		return false
+	default:
+		if err := s.db.DB.Model(&db.MonitorEvent{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+			"status":           monitorEventStatusOpen,
+			"resolved_at":      nil,
+			"title":            e.title,
+			"last_seen_at":     now,
+			"last_changed_at":  now,
+			"occurrence_count": existing.OccurrenceCount + 1,
+			"last_run_id":      runID,
+		}).Error; err != nil {
+			log.Printf("[Monitor] update %s event failed id=%d: %v", e.eventType, existing.ID, err)
+			return false
+		}
+	}
+	_ = s.db.SaveAssetChange(&db.AssetChange{
+		ProjectID:  projectID,
+		RunID:      runID,
+		RootDomain: rootDomain,
+		ChangeType: e.eventType,
+		Domain:     e.host,
+		IP:         e.ip,
+		Port:       e.port,
+		Title:      e.title,
+	})
+	return true
+}
+
+// holdMonitorEvent keeps the event for a condition that is still true. The
+// event is opened when it does not exist or was resolved, and only refreshed
+// when it is already open, so a standing condition is reported once. It
+// reports whether the event was opened.
+func (s *Server) holdMonitorEvent(projectID, rootDomain string, runID uint, e monitorChangeEvent) bool {
+	var existing db.MonitorEvent
+	err := s.db.DB.Where("project_id = ? AND event_key = ?", projectID, e.key).First(&existing).Error
+	if err == nil && existing.Status == monitorEventStatusOpen {
+		if err := s.db.DB.Model(&db.MonitorEvent{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+			"title":        e.title,
+			"last_seen_at": time.Now(),
+			"last_run_id":  runID,
+		}).Error; err != nil {
+			log.Printf("[Monitor] update %s event failed id=%d: %v", e.eventType, existing.ID, err)
+		}
+		return false
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[Monitor] query %s event failed key=%s: %v", e.eventType, e.key, err)
+		return false
+	}
+	return s.openMonitorEvent(projectID, rootDomain, runID, e)
+}
+
+// resolveMonitorEvent resolves the open event with key, if any, once its
+// condition no longer holds.
+func (s *Server) resolveMonitorEvent(projectID string, runID uint, key string) {
+	now := time.Now()
+	if err := s.db.DB.Model(&db.MonitorEvent{}).
+		Where("project_id = ? AND event_key = ? AND status = ?", projectID, key, monitorEventStatusOpen).
+		Updates(map[string]interface{}{
+			"status":          monitorEventStatusResolved,
+			"resolved_at":     now,
+			"last_changed_at": now,
+			"last_run_id":     runID,
+		}).Error; err != nil {
+		log.Printf("[Monitor] resolve event failed key=%s: %v", key, err)
+	}
+}
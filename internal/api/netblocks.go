@@ -0,0 +1,484 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"hunter/internal/asn"
+	"hunter/internal/db"
+	"hunter/internal/engine"
+)
+
+const (
+	// asnGroupSampleIPs caps the IPs listed per AS in the ASN view.
+	asnGroupSampleIPs = 50
+	// netblockRuleNote marks scope rules created by approving a netblock.
+	netblockRuleNote = "netblock"
+)
+
+var errASNDatasetUnset = errors.New("ASN_DATASET is not configured")
+
+// asnDatasetCache holds the ASN dataset named by ASN_DATASET and reloads it
+// when the file changes.
+type asnDatasetCache struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	data    *asn.DB
+}
+
+type asnGroupResponse struct {
+	ASN      uint32   `json:"asn"`
+	Org      string   `json:"org"`
+	Country  string   `json:"country"`
+	IPCount  int      `json:"ipCount"`
+	IPs      []string `json:"ips"`
+	Prefixes int      `json:"prefixes"`
+}
+
+type asnMappingResponse struct {
+	Dataset  string             `json:"dataset"`
+	TotalIPs int                `json:"totalIps"`
+	Unmapped []string           `json:"unmapped"`
+	Groups   []asnGroupResponse `json:"groups"`
+}
+
+type netblockResponse struct {
+	ID         int    `json:"id"`
+	CIDR       string `json:"cidr"`
+	ASN        uint32 `json:"asn"`
+	Org        string `json:"org"`
+	Country    string `json:"country"`
+	Status     string `json:"status"`
+	Hosts      uint64 `json:"hosts"`
+	MatchedIPs int    `json:"matchedIps"`
+	ReviewedBy string `json:"reviewedBy,omitempty"`
+	ReviewedAt string `json:"reviewedAt,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type netblockSuggestRequest struct {
+	ProjectID string   `json:"projectId"`
+	Org       string   `json:"org"`
+	ASNs      []uint32 `json:"asns"`
+}
+
+type netblockReviewRequest struct {
+	ProjectID string `json:"projectId"`
+	IDs       []int  `json:"ids"`
+	Status    string `json:"status"`
+}
+
+// asnDataset returns the dataset at ASN_DATASET, loading it on first use and
+// whenever the file's modification time changes.
+func (s *Server) asnDataset() (*asn.DB, string, error) {
+	path := strings.TrimSpace(os.Getenv("ASN_DATASET"))
+	if path == "" {
+		return nil, "", errASNDatasetUnset
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, path, err
+	}
+	c := &s.asnData
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data != nil && c.path == path && c.modTime.Equal(info.ModTime()) {
+		return c.data, path, nil
+	}
+	start := time.Now()
+	data, err := asn.Open(path)
+	if err != nil {
+		return nil, path, err
+	}
+	c.path, c.modTime, c.data = path, info.ModTime(), data
+	log.Printf("[ASN] loaded %s: ranges=%d in %v", path, data.Len(), time.Since(start).Round(time.Millisecond))
+	return data, path, nil
+}
+
+// projectAddrs returns the parsed, de-duplicated asset IPs of projectID.
+// Asset rows may hold several comma-separated addresses.
+func (s *Server) projectAddrs(projectID string) ([]netip.Addr, error) {
+	raw, err := s.db.ListProjectIPs(projectID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[netip.Addr]bool, len(raw))
+	var out []netip.Addr
+	for _, item := range raw {
+		for _, field := range strings.FieldsFunc(item, func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+			addr, err := netip.ParseAddr(strings.TrimSpace(field))
+			if err != nil {
+				continue
+			}
+			addr = addr.Unmap()
+			if !seen[addr] {
+				seen[addr] = true
+				out = append(out, addr)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Less(out[j]) })
+	return out, nil
+}
+
+// prefixHosts returns the number of addresses in p, saturating for IPv6.
+func prefixHosts(p netip.Prefix) uint64 {
+	host := p.Addr().BitLen() - p.Bits()
+	if host >= 64 {
+		return ^uint64(0)
+	}
+	return 1 << uint(host)
+}
+
+// suggestNetblocks stores every IPv4 prefix announced by the ASes whose owner
+// contains org, or by asns, as pending netblock suggestions of projectID.
+// IPv6 prefixes are left out as they cannot be swept. At most
+// NETBLOCK_MAX_SUGGESTIONS prefixes (default 512) are stored per call,
+// those holding known asset IPs first.
+func (s *Server) suggestNetblocks(projectID, org string, asns []uint32) (added int, rows []db.NetblockSuggestion, err error) {
+	data, _, err := s.asnDataset()
+	if err != nil {
+		return 0, nil, err
+	}
+	owners := data.MatchOrg(org)
+	for _, n := range asns {
+		if _, ok := owners[n]; !ok && n != 0 {
+			owners[n] = ""
+		}
+	}
+	if len(owners) == 0 {
+		return 0, nil, nil
+	}
+	addrs, err := s.projectAddrs(projectID)
+	if err != nil {
+		return 0, nil, err
+	}
+	for n := range owners {
+		for _, rec := range data.Ranges(n) {
+			if !rec.Start.Is4() {
+				continue
+			}
+			for _, p := range rec.Prefixes() {
+				matched := 0
+				for _, addr := range addrs {
+					if p.Contains(addr) {
+						matched++
+					}
+				}
+				rows = append(rows, db.NetblockSuggestion{
+					CIDR:       p.String(),
+					ASN:        rec.ASN,
+					Org:        rec.Org,
+					Country:    rec.Country,
+					MatchedIPs: matched,
+				})
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].MatchedIPs != rows[j].MatchedIPs {
+			return rows[i].MatchedIPs > rows[j].MatchedIPs
+		}
+		return rows[i].ASN < rows[j].ASN
+	})
+	if limit := envIntOrDefault("NETBLOCK_MAX_SUGGESTIONS", 512); limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	added, err = s.db.AddNetblockSuggestions(projectID, rows)
+	return added, rows, err
+}
+
+// netblockTargets expands netblocks into IP targets, stopping after
+// maxHosts addresses. It reports whether the list was cut short.
+func netblockTargets(rows []db.NetblockSuggestion, maxHosts int) ([]string, bool) {
+	var out []string
+	seen := make(map[netip.Addr]bool)
+	for _, row := range rows {
+		p, err := netip.ParsePrefix(row.CIDR)
+		if err != nil || !p.Addr().Is4() {
+			continue
+		}
+		p = p.Masked()
+		for addr := p.Addr(); addr.IsValid() && p.Contains(addr); addr = addr.Next() {
+			if seen[addr] {
+				continue
+			}
+			if len(out) >= maxHosts {
+				return out, true
+			}
+			seen[addr] = true
+			out = append(out, addr.String())
+		}
+	}
+	return out, false
+}
+
+// approvedNetblockTargets returns the IP targets of the approved netblocks
+// of projectID, capped by NETBLOCK_MAX_HOSTS (default 4096).
+func approvedNetblockTargets(database *db.Database, projectID string) ([]string, int, bool, error) {
+	rows, err := database.ListNetblockSuggestions(projectID, db.NetblockApproved, 0)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	targets, truncated := netblockTargets(rows, envIntOrDefault("NETBLOCK_MAX_HOSTS", 4096))
+	return targets, len(rows), truncated, nil
+}
+
+// scanApprovedNetblocks port scans the addresses of the approved netblocks
+// of projectID as IP-only targets.
+func (s *Server) scanApprovedNetblocks(ctx context.Context, projectID, jobID string, scope *engine.Scope, emit engine.ResultHandler, checkpoints engine.CheckpointStore) ([]engine.Result, error) {
+	targets, netblocks, truncated, err := approvedNetblockTargets(s.db, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("load approved netblocks: %v", err)
+	}
+	if len(targets) == 0 {
+		s.appendJobLog(projectID, jobID, "info", "Netblock scan skipped: no approved IPv4 netblocks")
+		return nil, nil
+	}
+	if truncated {
+		s.appendJobLogf(projectID, jobID, "warn", "Netblock targets capped at %d addresses (NETBLOCK_MAX_HOSTS)", len(targets))
+	}
+	s.appendJobLogf(projectID, jobID, "info", "Stage started: netblock port scan (netblocks=%d ips=%d)", netblocks, len(targets))
+	results, err := s.runNetworkPipeline(ctx, targets, false, true, false, false, false, false, "", engine.Recursion{}, scope, emit, checkpoints)
+	counts := countResults(results)
+	s.appendJobLogf(projectID, jobID, "info", "Netblock port scan done: ports=%d", counts["ports"])
+	return results, err
+}
+
+// ensureNetblockScopeRule adds an include CIDR scope rule for an approved
+// netblock unless one already exists. CIDR includes only add addresses, so
+// approval never takes hostnames out of scope.
+func (s *Server) ensureNetblockScopeRule(projectID string, row db.NetblockSuggestion) error {
+	var count int64
+	if err := s.db.DB.Model(&db.ProjectScopeRule{}).
+		Where("project_id = ? AND action = ? AND kind = ? AND pattern = ?", projectID, engine.ScopeInclude, engine.ScopeKindCIDR, row.CIDR).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	rule := netblockScopeRule(projectID, row)
+	return s.db.DB.Create(&rule).Error
+}
+
+// netblockScopeRule is the scope rule recorded for an approved netblock.
+func netblockScopeRule(projectID string, row db.NetblockSuggestion) db.ProjectScopeRule {
+	return db.ProjectScopeRule{
+		ProjectID: projectID,
+		Action:    engine.ScopeInclude,
+		Kind:      engine.ScopeKindCIDR,
+		Pattern:   row.CIDR,
+		Note:      fmt.Sprintf("%s AS%d %s", netblockRuleNote, row.ASN, row.Org),
+		Enabled:   true,
+	}
+}
+
+// removeNetblockScopeRule drops the scope rule added when a netblock was
+// approved. Rules written by hand are kept.
+func (s *Server) removeNetblockScopeRule(projectID string, row db.NetblockSuggestion) error {
+	return s.db.DB.Where("project_id = ? AND action = ? AND kind = ? AND pattern = ? AND note LIKE ?",
+		projectID, engine.ScopeInclude, engine.ScopeKindCIDR, row.CIDR, netblockRuleNote+" %").
+		Delete(&db.ProjectScopeRule{}).Error
+}
+
+func toNetblockResponse(row db.NetblockSuggestion) netblockResponse {
+	resp := netblockResponse{
+		ID:         int(row.ID),
+		CIDR:       row.CIDR,
+		ASN:        row.ASN,
+		Org:        row.Org,
+		Country:    row.Country,
+		Status:     row.Status,
+		MatchedIPs: row.MatchedIPs,
+		ReviewedBy: row.ReviewedBy,
+		ReviewedAt: timePtrToISO(row.ReviewedAt),
+		CreatedAt:  timeToISO(row.CreatedAt),
+	}
+	if p, err := netip.ParsePrefix(row.CIDR); err == nil {
+		resp.Hosts = prefixHosts(p)
+	}
+	return resp
+}
+
+// handleAssetASN maps the asset IPs of a project to their AS and owner.
+func (s *Server) handleAssetASN(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+	data, path, err := s.asnDataset()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errASNDatasetUnset) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	addrs, err := s.projectAddrs(projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := asnMappingResponse{Dataset: path, TotalIPs: len(addrs), Unmapped: []string{}, Groups: []asnGroupResponse{}}
+	groups := make(map[uint32]*asnGroupResponse)
+	for _, addr := range addrs {
+		rec, ok := data.Lookup(addr)
+		if !ok {
+			resp.Unmapped = append(resp.Unmapped, addr.String())
+			continue
+		}
+		g := groups[rec.ASN]
+		if g == nil {
+			g = &asnGroupResponse{ASN: rec.ASN, Org: rec.Org, Country: rec.Country, IPs: []string{}}
+			for _, rng := range data.Ranges(rec.ASN) {
+				g.Prefixes += len(rng.Prefixes())
+			}
+			groups[rec.ASN] = g
+		}
+		g.IPCount++
+		if len(g.IPs) < asnGroupSampleIPs {
+			g.IPs = append(g.IPs, addr.String())
+		}
+	}
+	for _, g := range groups {
+		resp.Groups = append(resp.Groups, *g)
+	}
+	sort.Slice(resp.Groups, func(i, j int) bool {
+		if resp.Groups[i].IPCount != resp.Groups[j].IPCount {
+			return resp.Groups[i].IPCount > resp.Groups[j].IPCount
+		}
+		return resp.Groups[i].ASN < resp.Groups[j].ASN
+	})
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleProjectNetblocks lists the netblock suggestions of a project (GET)
+// and records reviews (PUT). Approving a netblock adds an include CIDR scope
+// rule; rejecting it removes that rule again.
+func (s *Server) handleProjectNetblocks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+		if projectID == "" {
+			writeError(w, http.StatusBadRequest, "project_id is required")
+			return
+		}
+		rows, err := s.db.ListNetblockSuggestions(projectID, r.URL.Query().Get("status"), maxListRows)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := make([]netblockResponse, 0, len(rows))
+		for _, row := range rows {
+			resp = append(resp, toNetblockResponse(row))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodPut:
+		var req netblockReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		projectID := strings.TrimSpace(req.ProjectID)
+		status := strings.ToLower(strings.TrimSpace(req.Status))
+		if projectID == "" || len(req.IDs) == 0 {
+			writeError(w, http.StatusBadRequest, "projectId and ids are required")
+			return
+		}
+		switch status {
+		case db.NetblockApproved, db.NetblockRejected, db.NetblockPending:
+		default:
+			writeError(w, http.StatusBadRequest, "status must be approved, rejected or pending")
+			return
+		}
+		ids := make([]uint, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			if id > 0 {
+				ids = append(ids, uint(id))
+			}
+		}
+		actor := actorFromRequest(r)
+		rows, err := s.db.ReviewNetblockSuggestions(projectID, ids, status, actor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := make([]netblockResponse, 0, len(rows))
+		cidrs := make([]string, 0, len(rows))
+		for _, row := range rows {
+			var err error
+			if status == db.NetblockApproved {
+				err = s.ensureNetblockScopeRule(projectID, row)
+			} else {
+				err = s.removeNetblockScopeRule(projectID, row)
+			}
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			resp = append(resp, toNetblockResponse(row))
+			cidrs = append(cidrs, row.CIDR)
+		}
+		s.writeAudit(projectID, actor, "netblock_review", "netblock", strings.Join(cidrs, ","), map[string]interface{}{"status": status}, r)
+		writeJSON(w, http.StatusOK, resp)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleProjectNetblocksSuggest suggests the netblocks of the ASes whose
+// owner contains org, plus any ASNs given explicitly.
+func (s *Server) handleProjectNetblocksSuggest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req netblockSuggestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	projectID := strings.TrimSpace(req.ProjectID)
+	org := strings.TrimSpace(req.Org)
+	if projectID == "" || (len([]rune(org)) < 3 && len(req.ASNs) == 0) {
+		writeError(w, http.StatusBadRequest, "projectId and an org of at least 3 characters or asns are required")
+		return
+	}
+	added, rows, err := s.suggestNetblocks(projectID, org, req.ASNs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errASNDatasetUnset) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	asns := make(map[uint32]bool)
+	for _, row := range rows {
+		asns[row.ASN] = true
+	}
+	s.writeAudit(projectID, actorFromRequest(r), "netblock_suggest", "netblock", projectID, map[string]interface{}{
+		"org": org, "asns": req.ASNs, "suggested": len(rows), "added": added,
+	}, r)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "ok", "asns": len(asns), "suggested": len(rows), "added": added,
+	})
+}
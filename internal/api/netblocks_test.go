@@ -0,0 +1,31 @@
+package api
+
+import (
+	"testing"
+
+	"hunter/internal/db"
+	"hunter/internal/engine"
+)
+
+func TestApprovedNetblockKeepsHostnames(t *testing.T) {
+	roots := []db.ProjectScope{{RootDomain: "example.com"}}
+	row := db.NetblockSuggestion{CIDR: "192.0.2.0/24", ASN: 64500, Org: "Example Org"}
+	rules := []db.ProjectScopeRule{netblockScopeRule("p1", row)}
+	scope, err := engine.NewScope(projectScopeRules("p1", roots, rules))
+	if err != nil {
+		t.Fatalf("NewScope: %v", err)
+	}
+	for target, want := range map[string]bool{
+		"example.com":                   true,
+		"www.example.com":               true,
+		"https://app.example.com/login": true,
+		"192.0.2.7":                     true,
+		"192.0.2.7:443:edge.other.test": true,
+		"198.51.100.1":                  false,
+		"other.test":                    false,
+	} {
+		if got, reason := scope.Check(target); got != want {
+			t.Errorf("Check(%q) = %v (%s), want %v", target, got, reason, want)
+		}
+	}
+}
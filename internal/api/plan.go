@@ -0,0 +1,366 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"hunter/internal/db"
+	"hunter/internal/pipelines"
+	"hunter/internal/plugins"
+)
+
+// scanPlanHistoryJobs is how many recent successful jobs feed the estimate.
+const scanPlanHistoryJobs = 20
+
+// ScanPlanInput describes a job to plan. Modules use the same names as
+// POST /api/jobs.
+type ScanPlanInput struct {
+	ProjectID    string
+	RootDomains  []string
+	Hosts        []string // explicit targets that skip subdomain collection
+	Modules      []string
+	Pipeline     string
+	EnableNuclei bool
+	ActiveSubs   bool
+	DictSize     int
+	RecurseDepth int
+	PortEngine   string // "tscan" or "naabu_nmap"; empty uses PORT_SCANNER_ENGINE
+}
+
+// ScanPlan is what a job would do if it were queued now.
+type ScanPlan struct {
+	ProjectID            string          `json:"projectId"`
+	RootDomains          []string        `json:"rootDomains"`
+	Pipeline             string          `json:"pipeline,omitempty"`
+	Modules              []string        `json:"modules"`
+	EnableNuclei         bool            `json:"enableNuclei"`
+	ActiveSubs           bool            `json:"activeSubs"`
+	RecurseDepth         int             `json:"recurseDepth"`
+	KnownSubdomains      int             `json:"knownSubdomains"`
+	LiveURLs             int             `json:"liveUrls"`
+	NetblockHosts        int             `json:"netblockHosts,omitempty"`
+	Stages               []ScanPlanStage `json:"stages"`
+	MissingTools         []string        `json:"missingTools"`
+	EstimatedDurationSec int             `json:"estimatedDurationSec"`
+	EstimateSource       string          `json:"estimateSource"` // stages, jobs, partial or none
+	Warnings             []string        `json:"warnings,omitempty"`
+}
+
+// ScanPlanStage is one stage of a ScanPlan. InputCount is taken from data
+// already in the database, so it is a lower bound for a first scan.
+type ScanPlanStage struct {
+	Stage        string   `json:"stage"`
+	Tools        []string `json:"tools"`
+	MissingTools []string `json:"missingTools,omitempty"`
+	InputCount   int      `json:"inputCount"`
+	EstimatedSec int      `json:"estimatedSec"`
+	Samples      int      `json:"samples"`
+}
+
+// BuildScanPlan works out the stages, inputs, missing tools and a duration
+// estimate for in. database may be nil, in which case input counts and
+// estimates are left empty.
+func BuildScanPlan(database *db.Database, in ScanPlanInput) (*ScanPlan, error) {
+	plan := &ScanPlan{
+		ProjectID:    in.ProjectID,
+		RootDomains:  in.RootDomains,
+		Pipeline:     in.Pipeline,
+		Modules:      in.Modules,
+		EnableNuclei: in.EnableNuclei,
+		ActiveSubs:   in.ActiveSubs,
+		RecurseDepth: in.RecurseDepth,
+		MissingTools: []string{},
+	}
+
+	if database != nil {
+		subs, live, err := database.CountKnownHosts(in.ProjectID, in.RootDomains)
+		if err != nil {
+			return nil, fmt.Errorf("count known hosts: %v", err)
+		}
+		plan.KnownSubdomains, plan.LiveURLs = int(subs), int(live)
+		if in.Pipeline == "" && resolveScanStages(in.Modules, in.EnableNuclei, in.ActiveSubs).Netblocks {
+			targets, _, truncated, err := approvedNetblockTargets(database, in.ProjectID)
+			if err != nil {
+				return nil, fmt.Errorf("load approved netblocks: %v", err)
+			}
+			plan.NetblockHosts = len(targets)
+			if truncated {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("netblock targets are capped at %d addresses (NETBLOCK_MAX_HOSTS)", len(targets)))
+			}
+		}
+	} else {
+		plan.Warnings = append(plan.Warnings, "database unavailable; input counts and estimates are empty")
+	}
+
+	if in.Pipeline != "" {
+		def, err := pipelines.Get(in.Pipeline)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range def.Nodes {
+			inputCount := 0
+			if info, ok := plugins.LookupPlugin(node.Scanner); ok && len(info.Inputs) > 0 {
+				inputCount = plan.inputCountFor(info.Inputs[0], in)
+			}
+			plan.addStage("pipeline:node:"+node.ID, inputCount, node.Scanner)
+		}
+	} else {
+		plan.addModuleStages(in)
+	}
+	if plan.KnownSubdomains == 0 && len(in.Hosts) == 0 {
+		plan.Warnings = append(plan.Warnings, "no known subdomains yet; network stage inputs will grow with what enumeration finds")
+	}
+	if in.RecurseDepth > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("recursive discovery may re-run the network stage up to %d more times", in.RecurseDepth))
+	}
+
+	missing := make(map[string]bool)
+	for i := range plan.Stages {
+		for _, tool := range plan.Stages[i].Tools {
+			info, ok := plugins.LookupPlugin(tool)
+			if !ok || info.Available() {
+				continue
+			}
+			binary := info.Binary
+			if binary == "" {
+				binary = info.Name
+			}
+			plan.Stages[i].MissingTools = append(plan.Stages[i].MissingTools, binary)
+			missing[binary] = true
+		}
+	}
+	for tool := range missing {
+		plan.MissingTools = append(plan.MissingTools, tool)
+	}
+	sort.Strings(plan.MissingTools)
+
+	if database != nil {
+		if err := plan.estimate(database, in); err != nil {
+			return nil, fmt.Errorf("estimate duration: %v", err)
+		}
+	}
+	if plan.EstimateSource == "" {
+		plan.EstimateSource = "none"
+	}
+	return plan, nil
+}
+
+// addModuleStages mirrors the stage order of runScanAsync.
+func (p *ScanPlan) addModuleStages(in ScanPlanInput) {
+	st := resolveScanStages(in.Modules, in.EnableNuclei, in.ActiveSubs)
+	networkInput := len(in.Hosts)
+	if networkInput == 0 {
+		networkInput = len(in.RootDomains)
+	}
+	if st.DNS && !st.Subs {
+		p.addStage("dns_records", len(in.RootDomains), "dns_records")
+	}
+	if st.Subs {
+		tools := []string{"subfinder", "chaos", "findomain"}
+		if !st.BbotActive {
+			tools = append(tools, "bbot")
+		}
+		tools = append(tools, "shosubgo", "ctlogs")
+		if st.ZoneTransfer {
+			tools = append(tools, "zonetransfer")
+		}
+		tools = append(tools, externalPluginNames(plugins.CategorySubdomain)...)
+		p.addStage("subs_passive", len(in.RootDomains), tools...)
+		if st.BbotActive {
+			p.addStage("subs_bbot_active", len(in.RootDomains), "bbot_active")
+		}
+		if st.ActiveSubs {
+			p.addStage("subs_active", clampDictSize(in.DictSize), plugins.ActiveBruteforceEngine())
+		}
+		if st.Permute {
+			p.addStage("subs_permutations", p.KnownSubdomains, "permutations")
+		}
+		if st.DNS {
+			p.addStage("dns_records", p.KnownSubdomains, "dns_records")
+		}
+		if p.KnownSubdomains > networkInput {
+			networkInput = p.KnownSubdomains
+		}
+	}
+	if st.Ports || st.Httpx || st.SubTakeover {
+		p.addNetworkStages(st, in, networkInput)
+	}
+	if st.Netblocks {
+		p.addStage("netblocks:ports", p.NetblockHosts, portScanTools(in.PortEngine)...)
+	}
+}
+
+// addNetworkStages adds the httpx, port, vuln and screenshot stages.
+func (p *ScanPlan) addNetworkStages(st scanStages, in ScanPlanInput, networkInput int) {
+	if st.Httpx {
+		p.addStage("network:httpx", networkInput, plugins.HTTPProbeEngine())
+		for _, name := range externalPluginNames(plugins.CategoryWeb) {
+			p.addStage("network:web:"+name, networkInput, name)
+		}
+	}
+	if st.Ports {
+		p.addStage("network:ports", networkInput, portScanTools(in.PortEngine)...)
+		for _, name := range externalPluginNames(plugins.CategoryPort) {
+			p.addStage("network:ports:"+name, networkInput, name)
+		}
+	}
+	if st.Nuclei {
+		p.addStage("network:vuln:nuclei", p.LiveURLs, "nuclei")
+	}
+	if st.Cors {
+		p.addStage("network:vuln:cors", p.LiveURLs, "cors")
+	}
+	if st.SubTakeover {
+		p.addStage("network:vuln:subtakeover", networkInput, "subtakeover")
+	}
+	if st.Nuclei || st.Cors || st.SubTakeover {
+		for _, name := range externalPluginNames(plugins.CategoryVuln) {
+			p.addStage("network:vuln:"+name, p.LiveURLs, name)
+		}
+	}
+	if st.Witness {
+		p.addStage("network:screenshot", p.LiveURLs, "gowitness")
+	}
+}
+
+// portScanTools returns the port plugins of engineName, defaulting to
+// PORT_SCANNER_ENGINE, followed by the certificate grab on open ports.
+func portScanTools(engineName string) []string {
+	if engineName == "" {
+		engineName = configuredPortScannerEngine()
+	}
+	if engineName == "naabu_nmap" {
+		return []string{"naabu", "nmap", "tls_grab"}
+	}
+	return []string{"tscan", "tls_grab"}
+}
+
+func (p *ScanPlan) addStage(stage string, inputCount int, tools ...string) {
+	p.Stages = append(p.Stages, ScanPlanStage{Stage: stage, Tools: tools, InputCount: inputCount})
+}
+
+func (p *ScanPlan) inputCountFor(resultType string, in ScanPlanInput) int {
+	switch resultType {
+	case plugins.InputRootDomain:
+		return len(in.RootDomains)
+	case "domain":
+		return p.KnownSubdomains
+	case "web_service":
+		return p.LiveURLs
+	case "dict_word":
+		return clampDictSize(in.DictSize)
+	default:
+		return 0
+	}
+}
+
+// estimate fills per-stage estimates from past stage timings, scaled by
+// input size, and falls back to the mean duration of past jobs with the same
+// modules when some stage has no history.
+func (p *ScanPlan) estimate(database *db.Database, in ScanPlanInput) error {
+	timings, err := database.ListScanStageTimings(in.ProjectID, scanPlanHistoryJobs)
+	if err != nil {
+		return err
+	}
+	type stageHistory struct {
+		seconds float64
+		inputs  int
+		samples int
+	}
+	history := make(map[string]*stageHistory)
+	for _, t := range timings {
+		h := history[t.Stage]
+		if h == nil {
+			h = &stageHistory{}
+			history[t.Stage] = h
+		}
+		h.seconds += t.DurationSec
+		h.inputs += max(t.InputCount, 1)
+		h.samples++
+	}
+
+	total := 0
+	complete := true
+	for i := range p.Stages {
+		h := history[p.Stages[i].Stage]
+		if h == nil {
+			complete = false
+			continue
+		}
+		perInput := h.seconds / float64(h.inputs)
+		p.Stages[i].EstimatedSec = int(math.Ceil(perInput * float64(max(p.Stages[i].InputCount, 1))))
+		p.Stages[i].Samples = h.samples
+		total += p.Stages[i].EstimatedSec
+	}
+	p.EstimatedDurationSec = total
+	switch {
+	case complete && len(p.Stages) > 0:
+		p.EstimateSource = "stages"
+		return nil
+	case total > 0:
+		p.EstimateSource = "partial"
+	}
+
+	avg, jobs, err := database.AverageScanJobDuration(in.ProjectID, strings.Join(in.Modules, ","), in.Pipeline, scanPlanHistoryJobs)
+	if err != nil {
+		return err
+	}
+	if jobs > 0 && int(avg) > total {
+		p.EstimatedDurationSec = int(math.Ceil(avg))
+		p.EstimateSource = "jobs"
+	}
+	return nil
+}
+
+func externalPluginNames(category string) []string {
+	var out []string
+	for _, info := range plugins.ListPlugins() {
+		if info.External && info.Category == category {
+			out = append(out, info.Name)
+		}
+	}
+	return out
+}
+
+func (s *Server) handleJobPlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req createJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	job, status, err := s.resolveJobRequest(req)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	dictSize := req.DictSize
+	if dictSize <= 0 {
+		s.settingsMu.RLock()
+		dictSize = s.settings.Scanner.DefaultDictSize
+		s.settingsMu.RUnlock()
+	}
+	plan, err := BuildScanPlan(s.db, ScanPlanInput{
+		ProjectID:    job.ProjectID,
+		RootDomains:  []string{job.RootDomain},
+		Modules:      job.Modules,
+		Pipeline:     job.Pipeline,
+		EnableNuclei: job.EnableNuclei,
+		ActiveSubs:   job.ActiveSubs,
+		DictSize:     dictSize,
+		RecurseDepth: job.RecurseDepth,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"hunter/internal/db"
+	"hunter/internal/resolver"
+)
+
+const resolverCheckConcurrency = 20
+
+type resolverResponse struct {
+	Address       string `json:"address"`
+	Status        string `json:"status"`
+	LatencyMs     int    `json:"latencyMs"`
+	Failures      int    `json:"failures"`
+	LastError     string `json:"lastError,omitempty"`
+	LastCheckedAt string `json:"lastCheckedAt,omitempty"`
+}
+
+type resolverPoolResponse struct {
+	Total     int                `json:"total"`
+	Healthy   int                `json:"healthy"`
+	Resolvers []resolverResponse `json:"resolvers"`
+}
+
+type resolverAddRequest struct {
+	Addresses []string `json:"addresses"`
+	Text      string   `json:"text"`
+}
+
+type resolverCheckResponse struct {
+	Address   string `json:"address"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMs int    `json:"latencyMs"`
+	Poisoned  bool   `json:"poisoned"`
+	Hijacked  bool   `json:"hijacked"`
+	Removed   bool   `json:"removed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// resolverHealthCheck builds the health check configuration from the
+// environment: RESOLVER_NXDOMAIN_ZONE and RESOLVER_CHECK_TIMEOUT_MS.
+func resolverHealthCheck() resolver.HealthCheck {
+	return resolver.HealthCheck{
+		NXDomainZone: strings.TrimSpace(os.Getenv("RESOLVER_NXDOMAIN_ZONE")),
+		Timeout:      time.Duration(envIntOrDefault("RESOLVER_CHECK_TIMEOUT_MS", 3000)) * time.Millisecond,
+	}
+}
+
+// runResolverHealthChecks checks the managed resolver pool every
+// RESOLVER_CHECK_INTERVAL_SEC seconds (default 3600, 0 disables).
+func (s *Server) runResolverHealthChecks() {
+	intervalSec := envIntOrDefault("RESOLVER_CHECK_INTERVAL_SEC", 3600)
+	if intervalSec <= 0 {
+		log.Printf("[Resolvers] periodic health checks disabled")
+		return
+	}
+	interval := time.Duration(intervalSec) * time.Second
+	log.Printf("[Resolvers] health checker started (interval=%v)", interval)
+	for {
+		if _, err := s.checkDNSResolvers(context.Background(), nil); err != nil {
+			log.Printf("[Resolvers] health check failed: %v", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// checkDNSResolvers health-checks addresses, or the whole pool when none are
+// given. For pool members, resolvers that answer incorrectly are removed at
+// once and resolvers that fail RESOLVER_MAX_FAILURES consecutive checks
+// (default 3) are removed as dead. Addresses outside the pool are only
+// checked.
+func (s *Server) checkDNSResolvers(ctx context.Context, addresses []string) ([]resolverCheckResponse, error) {
+	pool, err := s.db.ListDNSResolvers("")
+	if err != nil {
+		return nil, err
+	}
+	failures := make(map[string]int, len(pool))
+	inPool := make(map[string]bool, len(pool))
+	for _, row := range pool {
+		failures[row.Address] = row.Failures
+		inPool[row.Address] = true
+	}
+	if len(addresses) == 0 {
+		for _, row := range pool {
+			addresses = append(addresses, row.Address)
+		}
+	}
+	if len(addresses) == 0 {
+		return []resolverCheckResponse{}, nil
+	}
+
+	maxFailures := envIntOrDefault("RESOLVER_MAX_FAILURES", 3)
+	checks := resolver.CheckHealthAll(ctx, addresses, resolverHealthCheck(), resolverCheckConcurrency)
+	// A cancelled run says nothing about the resolvers.
+	record := ctx.Err() == nil
+	out := make([]resolverCheckResponse, 0, len(checks))
+	var removed []string
+	healthy := 0
+	for _, h := range checks {
+		item := resolverCheckResponse{
+			Address:   h.Server,
+			Healthy:   h.Healthy,
+			LatencyMs: int(h.Latency / time.Millisecond),
+			Poisoned:  h.Poisoned,
+			Hijacked:  h.Hijacked,
+		}
+		if h.Err != nil {
+			item.Error = h.Err.Error()
+		}
+		if h.Healthy {
+			healthy++
+		}
+		if !record || !inPool[h.Server] {
+			out = append(out, item)
+			continue
+		}
+		if h.Bad() || (!h.Healthy && maxFailures > 0 && failures[h.Server]+1 >= maxFailures) {
+			item.Removed = true
+			removed = append(removed, h.Server)
+		} else if err := s.db.RecordDNSResolverCheck(h.Server, h.Healthy, item.LatencyMs, item.Error); err != nil {
+			log.Printf("[Resolvers] save check of %s failed: %v", h.Server, err)
+		}
+		out = append(out, item)
+	}
+	if len(removed) > 0 {
+		if _, err := s.db.DeleteDNSResolvers(removed); err != nil {
+			log.Printf("[Resolvers] remove unhealthy resolvers failed: %v", err)
+		}
+	}
+	log.Printf("[Resolvers] checked=%d healthy=%d removed=%d", len(checks), healthy, len(removed))
+	return out, nil
+}
+
+// jobResolversFile returns the resolvers file of a job. An explicit file
+// configured on the job wins; otherwise a temporary file listing the healthy
+// managed resolvers is written. It returns "" when the pool has no healthy
+// resolvers. cleanup removes the generated file.
+func (s *Server) jobResolversFile(projectID, jobID, configured string) (string, func()) {
+	noop := func() {}
+	if configured = strings.TrimSpace(configured); configured != "" || s.db == nil {
+		return configured, noop
+	}
+	rows, err := s.db.ListDNSResolvers(db.ResolverHealthy)
+	if err != nil {
+		log.Printf("[Resolvers] load healthy resolvers failed: %v", err)
+		return "", noop
+	}
+	if len(rows) == 0 {
+		return "", noop
+	}
+	f, err := os.CreateTemp("", "hunter-resolvers-*.txt")
+	if err != nil {
+		log.Printf("[Resolvers] create resolvers file failed: %v", err)
+		return "", noop
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		b.WriteString(row.Address)
+		b.WriteByte('\n')
+	}
+	_, err = f.WriteString(b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	cleanup := func() { _ = os.Remove(f.Name()) }
+	if err != nil {
+		cleanup()
+		log.Printf("[Resolvers] write resolvers file failed: %v", err)
+		return "", noop
+	}
+	s.appendJobLogf(projectID, jobID, "info", "Using %d healthy resolvers from the managed pool", len(rows))
+	return f.Name(), cleanup
+}
+
+// parseResolverAddresses normalizes resolver addresses from a list and a
+// pasted resolvers file. Entries that are not IP addresses are returned as
+// invalid.
+func parseResolverAddresses(items []string, text string) (valid, invalid []string) {
+	seen := make(map[string]bool, len(items))
+	add := func(raw string) {
+		if i := strings.Index(raw, "#"); i >= 0 {
+			raw = raw[:i]
+		}
+		for _, field := range strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+		}) {
+			addr := resolver.NormalizeServer(field)
+			if addr == "" {
+				invalid = append(invalid, field)
+				continue
+			}
+			if !seen[addr] {
+				seen[addr] = true
+				valid = append(valid, addr)
+			}
+		}
+	}
+	for _, item := range items {
+		add(item)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		add(line)
+	}
+	return valid, invalid
+}
+
+func toResolverResponse(row db.DNSResolver) resolverResponse {
+	return resolverResponse{
+		Address:       row.Address,
+		Status:        row.Status,
+		LatencyMs:     row.LatencyMs,
+		Failures:      row.Failures,
+		LastError:     row.LastError,
+		LastCheckedAt: timePtrToISO(row.LastCheckedAt),
+	}
+}
+
+// handleResolvers manages the resolver pool: GET lists it, POST adds
+// servers (checked in the background) and DELETE removes servers.
+func (s *Server) handleResolvers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rows, err := s.db.ListDNSResolvers(r.URL.Query().Get("status"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := resolverPoolResponse{Resolvers: make([]resolverResponse, 0, len(rows))}
+		for _, row := range rows {
+			if row.Status == db.ResolverHealthy {
+				resp.Healthy++
+			}
+			resp.Resolvers = append(resp.Resolvers, toResolverResponse(row))
+		}
+		resp.Total = len(rows)
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodPost:
+		var req resolverAddRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		addresses, invalid := parseResolverAddresses(req.Addresses, req.Text)
+		if len(addresses) == 0 {
+			writeError(w, http.StatusBadRequest, "no valid resolver addresses")
+			return
+		}
+		added, err := s.db.AddDNSResolvers(addresses)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if len(added) > 0 {
+			go func() {
+				if _, err := s.checkDNSResolvers(context.Background(), added); err != nil {
+					log.Printf("[Resolvers] check of added resolvers failed: %v", err)
+				}
+			}()
+		}
+		s.writeAudit("", actorFromRequest(r), "resolver_add", "resolver", "", map[string]interface{}{"added": len(added)}, r)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "added": added, "invalid": invalid})
+	case http.MethodDelete:
+		addresses, _ := parseResolverAddresses(nil, r.URL.Query().Get("address"))
+		if len(addresses) == 0 {
+			writeError(w, http.StatusBadRequest, "address is required")
+			return
+		}
+		removed, err := s.db.DeleteDNSResolvers(addresses)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit("", actorFromRequest(r), "resolver_delete", "resolver", strings.Join(addresses, ","), nil, r)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "removed": removed})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleResolversCheck health-checks the pool, or the addresses in the
+// request body, and returns the outcome of every check.
+func (s *Server) handleResolversCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req resolverAddRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	addresses, _ := parseResolverAddresses(req.Addresses, req.Text)
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+	results, err := s.checkDNSResolvers(ctx, addresses)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"hunter/internal/engine"
+	"hunter/internal/plugins"
+)
+
+// scanResultSink persists scan results as plugins emit them, so long-running
+// jobs expose partial data and keep it if the worker dies mid-run.
+type scanResultSink struct {
+	server     *Server
+	projectID  string
+	rootDomain string
+	jobID      string
+	scope      *engine.Scope
+
+	mu        sync.Mutex
+	persisted int
+	failures  int
+	// domains maps each streamed host to the sources already recorded for
+	// it. Domains reach the sink before the pipeline de-duplicates them.
+	domains map[string]map[string]bool
+	// hostLocks serializes writes about the same host, whose find-or-create
+	// upserts would otherwise race; other hosts are written concurrently.
+	hostLocks map[string]*sync.Mutex
+}
+
+func newScanResultSink(s *Server, projectID, rootDomain, jobID string, scope *engine.Scope) *scanResultSink {
+	return &scanResultSink{
+		server:     s,
+		projectID:  projectID,
+		rootDomain: rootDomain,
+		jobID:      jobID,
+		scope:      scope,
+		domains:    make(map[string]map[string]bool),
+		hostLocks:  make(map[string]*sync.Mutex),
+	}
+}
+
+// Handle saves one result. It is safe to call from concurrent scanners; the
+// database write happens outside the lock.
+func (k *scanResultSink) Handle(result engine.Result) {
+	if k == nil {
+		return
+	}
+	switch result.Type {
+	case "domain", "web_service", "open_port", "port_service", "vulnerability", engine.ResultTypeZoneExposure, engine.ResultTypeDNSRecords, engine.ResultTypeTLSCertificate, engine.ResultTypeCTCertificate:
+	default:
+		return
+	}
+
+	if result.Type == "domain" {
+		domain, _ := result.Data.(string)
+		first, fresh := k.claimDomain(domain, plugins.ResultSources(result))
+		if !first {
+			k.recordDomainSources(result, domain, fresh)
+			return
+		}
+	}
+
+	hostLock := k.hostLock(sinkResultHost(result))
+	hostLock.Lock()
+	err := k.server.saveScopedResultsToDB(k.scope, k.projectID, k.rootDomain, k.jobID, []engine.Result{result})
+	hostLock.Unlock()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err != nil {
+		k.failures++
+		log.Printf("[Scan][Stream] job=%s persist %s failed: %v", k.jobID, result.Type, err)
+		return
+	}
+	k.persisted++
+}
+
+func (k *scanResultSink) hostLock(host string) *sync.Mutex {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	lock, ok := k.hostLocks[host]
+	if !ok {
+		lock = &sync.Mutex{}
+		k.hostLocks[host] = lock
+	}
+	return lock
+}
+
+// sinkResultHost returns the host a result is stored under.
+func sinkResultHost(result engine.Result) string {
+	if domain, ok := result.Data.(string); ok {
+		return engine.HostKey(domain)
+	}
+	data, _ := result.Data.(map[string]interface{})
+	for _, key := range []string{"domain", "host", "ip"} {
+		if v := mapString(data, key); v != "" {
+			return engine.HostKey(v)
+		}
+	}
+	return ""
+}
+
+// claimDomain reports whether domain is new to the sink and which of
+// sources were not yet recorded for it.
+func (k *scanResultSink) claimDomain(domain string, sources []string) (bool, []string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	known, seen := k.domains[domain]
+	if !seen {
+		known = make(map[string]bool, len(sources))
+		k.domains[domain] = known
+	}
+	var fresh []string
+	for _, source := range sources {
+		if !known[source] {
+			known[source] = true
+			fresh = append(fresh, source)
+		}
+	}
+	return !seen, fresh
+}
+
+// recordDomainSources adds the sources of a repeated domain without saving
+// the candidate again.
+func (k *scanResultSink) recordDomainSources(result engine.Result, domain string, sources []string) {
+	if len(sources) == 0 {
+		return
+	}
+	if ok, _ := k.scope.CheckResult(result); !ok {
+		return
+	}
+	if err := k.server.db.RecordAssetSources(k.projectID, k.rootDomain, domain, k.jobID, sources); err != nil {
+		log.Printf("[Scan][Stream] job=%s record sources for %s failed: %v", k.jobID, domain, err)
+	}
+}
+
+// Err reports the accumulated persistence failures, if any.
+func (k *scanResultSink) Err() error {
+	if k == nil {
+		return nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.failures > 0 {
+		return fmt.Errorf("%d records failed to save", k.failures)
+	}
+	return nil
+}
+
+// Persisted returns how many results were written so far.
+func (k *scanResultSink) Persisted() int {
+	if k == nil {
+		return 0
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.persisted
+}
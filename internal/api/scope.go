@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hunter/internal/db"
+	"hunter/internal/engine"
+)
+
+type scopeRuleRequest struct {
+	ProjectID string `json:"projectId"`
+	Action    string `json:"action"`
+	Kind      string `json:"kind"`
+	Pattern   string `json:"pattern"`
+	Note      string `json:"note"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+type scopeRuleResponse struct {
+	ID        int    `json:"id"`
+	ProjectID string `json:"projectId"`
+	Action    string `json:"action"`
+	Kind      string `json:"kind"`
+	Pattern   string `json:"pattern"`
+	Note      string `json:"note"`
+	Enabled   bool   `json:"enabled"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// loadProjectScope builds the scope of projectID from its enabled root
+// domains and project_scope_rules.
+func (s *Server) loadProjectScope(projectID string) (*engine.Scope, error) {
+	var roots []db.ProjectScope
+	if err := s.db.DB.Where("project_id = ? AND enabled = ?", projectID, true).Find(&roots).Error; err != nil {
+		return nil, err
+	}
+	rules, err := s.db.ListProjectScopeRules(projectID, true)
+	if err != nil {
+		return nil, err
+	}
+	return engine.NewScope(projectScopeRules(projectID, roots, rules))
+}
+
+// projectScopeRules turns root domains into domain include rules and appends
+// the valid scope rules. Root domains are always included: include rules can
+// only widen the scope, and exclude rules narrow it.
+func projectScopeRules(projectID string, roots []db.ProjectScope, rules []db.ProjectScopeRule) []engine.ScopeRule {
+	specs := make([]engine.ScopeRule, 0, len(roots)+len(rules))
+	for _, sc := range roots {
+		if rd := normalizeRootDomain(sc.RootDomain); rd != "" {
+			specs = append(specs, engine.ScopeRule{Action: engine.ScopeInclude, Kind: engine.ScopeKindDomain, Pattern: rd})
+		}
+	}
+	for _, rule := range rules {
+		spec, err := engine.NormalizeScopeRule(engine.ScopeRule{Action: rule.Action, Kind: rule.Kind, Pattern: rule.Pattern})
+		if err != nil {
+			log.Printf("[Scope] skip invalid rule project=%s id=%d: %v", projectID, rule.ID, err)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// scanScope loads the project scope for a scan job. When it cannot be loaded
+// the job runs unfiltered and the failure is written to the job log.
+func (s *Server) scanScope(projectID, jobID string) *engine.Scope {
+	scope, err := s.loadProjectScope(projectID)
+	if err != nil {
+		log.Printf("[Scope] load project scope failed: project=%s err=%v", projectID, err)
+		s.appendJobLogf(projectID, jobID, "warn", "Scope rules unavailable, targets are not filtered: %v", err)
+		return nil
+	}
+	return scope
+}
+
+// logOutOfScopeResult records a result dropped at ingest.
+func (s *Server) logOutOfScopeResult(projectID, jobID string, result engine.Result, reason string) {
+	target := strings.Join(engine.DiscoveredHosts(result), ",")
+	if target == "" {
+		if data, ok := result.Data.(map[string]interface{}); ok {
+			target = mapString(data, "ip")
+		}
+	}
+	log.Printf("[Scope] project=%s job=%s dropped out-of-scope %s %s: %s", projectID, jobID, result.Type, target, reason)
+	s.appendJobLogf(projectID, jobID, "warn", "Out-of-scope %s dropped: %s (%s)", result.Type, target, reason)
+}
+
+func scopeRuleToResponse(rule db.ProjectScopeRule) scopeRuleResponse {
+	return scopeRuleResponse{
+		ID:        int(rule.ID),
+		ProjectID: rule.ProjectID,
+		Action:    rule.Action,
+		Kind:      rule.Kind,
+		Pattern:   rule.Pattern,
+		Note:      rule.Note,
+		Enabled:   rule.Enabled,
+		CreatedAt: timeToISO(rule.CreatedAt),
+	}
+}
+
+func (s *Server) handleProjectScopeRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+		if projectID == "" {
+			writeError(w, http.StatusBadRequest, "project_id is required")
+			return
+		}
+		rules, err := s.db.ListProjectScopeRules(projectID, false)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := make([]scopeRuleResponse, 0, len(rules))
+		for _, rule := range rules {
+			resp = append(resp, scopeRuleToResponse(rule))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodPost:
+		var req scopeRuleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		projectID := strings.TrimSpace(req.ProjectID)
+		if projectID == "" {
+			writeError(w, http.StatusBadRequest, "projectId is required")
+			return
+		}
+		spec, err := engine.NormalizeScopeRule(engine.ScopeRule{Action: req.Action, Kind: req.Kind, Pattern: req.Pattern})
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		rule := db.ProjectScopeRule{
+			ProjectID: projectID,
+			Action:    spec.Action,
+			Kind:      spec.Kind,
+			Pattern:   spec.Pattern,
+			Note:      strings.TrimSpace(req.Note),
+			Enabled:   req.Enabled == nil || *req.Enabled,
+		}
+		if err := s.db.DB.Create(&rule).Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit(projectID, actorFromRequest(r), "scope_rule_create", "scope_rule", strconv.Itoa(int(rule.ID)), map[string]interface{}{
+			"action": rule.Action, "kind": rule.Kind, "pattern": rule.Pattern,
+		}, r)
+		writeJSON(w, http.StatusOK, scopeRuleToResponse(rule))
+	case http.MethodDelete:
+		projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+		id, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("id")))
+		if projectID == "" || err != nil || id <= 0 {
+			writeError(w, http.StatusBadRequest, "project_id and id are required")
+			return
+		}
+		res := s.db.DB.Where("project_id = ? AND id = ?", projectID, id).Delete(&db.ProjectScopeRule{})
+		if res.Error != nil {
+			writeError(w, http.StatusInternalServerError, res.Error.Error())
+			return
+		}
+		if res.RowsAffected == 0 {
+			writeError(w, http.StatusNotFound, "scope rule not found")
+			return
+		}
+		s.writeAudit(projectID, actorFromRequest(r), "scope_rule_delete", "scope_rule", strconv.Itoa(id), nil, r)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+
+	"hunter/internal/db"
+	"hunter/internal/engine"
+)
+
+func TestProjectScopeRules(t *testing.T) {
+	roots := []db.ProjectScope{{RootDomain: "Example.com"}}
+	tests := []struct {
+		name  string
+		rules []db.ProjectScopeRule
+		want  map[string]bool
+	}{
+		{
+			name: "root only",
+			want: map[string]bool{"example.com": true, "www.example.com": true, "other.test": false},
+		},
+		{
+			name:  "root and wildcard include",
+			rules: []db.ProjectScopeRule{{Action: engine.ScopeInclude, Kind: engine.ScopeKindWildcard, Pattern: "*.example.com"}},
+			want:  map[string]bool{"example.com": true, "www.example.com": true, "other.test": false},
+		},
+		{
+			name:  "root and CIDR include",
+			rules: []db.ProjectScopeRule{{Action: engine.ScopeInclude, Kind: engine.ScopeKindCIDR, Pattern: "192.0.2.0/24"}},
+			want: map[string]bool{
+				"example.com":     true,
+				"www.example.com": true,
+				"192.0.2.10":      true,
+				"198.51.100.1":    false,
+				"other.test":      false,
+			},
+		},
+		{
+			name: "root and exclude",
+			rules: []db.ProjectScopeRule{
+				{Action: engine.ScopeExclude, Kind: engine.ScopeKindDomain, Pattern: "dev.example.com"},
+				{Action: "bogus", Pattern: "x"},
+			},
+			want: map[string]bool{"www.example.com": true, "api.dev.example.com": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := engine.NewScope(projectScopeRules("p1", roots, tt.rules))
+			if err != nil {
+				t.Fatalf("NewScope: %v", err)
+			}
+			for target, want := range tt.want {
+				if got, reason := scope.Check(target); got != want {
+					t.Errorf("Check(%q) = %v (%s), want %v", target, got, reason, want)
+				}
+			}
+		})
+	}
+}
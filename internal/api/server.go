@@ -18,6 +18,7 @@ import (
 
 	"hunter/internal/db"
 	"hunter/internal/engine"
+	"hunter/internal/pipelines"
 	"hunter/internal/plugins"
 
 	"gorm.io/gorm"
@@ -34,6 +35,8 @@ const (
 	scanWorkerPollInterval     = 3 * time.Second
 	scanCancelWatchInterval    = 5 * time.Second
 	scanJobStaleAfter          = 6 * time.Hour
+	scanJobMaxAutoResumes      = 3
+	maxScanRecurseDepth        = 5
 	monitorEventNotifyWindow   = 30 * time.Minute
 	monitorNotifyMaxAssets     = 6
 	monitorNotifyMaxPorts      = 8
@@ -57,13 +60,23 @@ type Server struct {
 	settingsMu sync.RWMutex
 	settings   runtimeSettings
 
-	// cancel functions for running scans, keyed by jobID
-	scanCancelMu sync.Mutex
-	scanCancels  map[string]context.CancelFunc
+	// cancel functions for running scans, keyed by jobID; lostScanLeases
+	// marks the ones canceled because this worker lost their lease
+	scanCancelMu   sync.Mutex
+	scanCancels    map[string]context.CancelFunc
+	lostScanLeases map[string]bool
 
 	aiLimiterMu          sync.Mutex
 	aiLimiterWindowStart time.Time
 	aiLimiterUsed        int
+
+	// worker is this process's registry entry; set by RunWorkers.
+	worker           *db.Worker
+	workerMu         sync.Mutex
+	currentScanJobID string
+
+	asnData     asnDatasetCache
+	faviconData faviconLabelCache
 }
 
 type runtimeSettings struct {
@@ -142,6 +155,7 @@ type jobOverviewResponse struct {
 	RootDomain   string   `json:"rootDomain"`
 	Mode         string   `json:"mode"`
 	Modules      []string `json:"modules"`
+	Pipeline     string   `json:"pipeline,omitempty"`
 	Status       string   `json:"status"`
 	StartedAt    string   `json:"startedAt"`
 	FinishedAt   string   `json:"finishedAt,omitempty"`
@@ -157,12 +171,15 @@ type createJobRequest struct {
 	Domain       string   `json:"domain"`
 	Mode         string   `json:"mode"`
 	Modules      []string `json:"modules"`
+	Pipeline     string   `json:"pipeline"`
 	EnableNuclei *bool    `json:"enableNuclei"`
 	ActiveSubs   *bool    `json:"activeSubs"`
 	DictSize     int      `json:"dictSize"`
 	DNSResolvers string   `json:"dnsResolvers"`
 	DryRun       bool     `json:"dryRun"`
 	Notify       *bool    `json:"notify"`
+	WorkerTags   []string `json:"workerTags"`
+	RecurseDepth *int     `json:"recurseDepth"`
 }
 
 type assetResponse struct {
@@ -176,9 +193,11 @@ type assetResponse struct {
 	StatusCode   int      `json:"statusCode,omitempty"`
 	Title        string   `json:"title,omitempty"`
 	Technologies []string `json:"technologies,omitempty"`
+	DiscoveryHop int      `json:"discoveryHop,omitempty"`
 	CreatedAt    string   `json:"createdAt,omitempty"`
 	UpdatedAt    string   `json:"updatedAt,omitempty"`
 	LastSeen     string   `json:"lastSeen,omitempty"`
+	Sources      []string `json:"sources,omitempty"`
 }
 
 type pagedAssetsResponse struct {
@@ -224,17 +243,18 @@ type jobLogsResponse struct {
 }
 
 type portResponse struct {
-	ID        int    `json:"id"`
-	AssetID   int    `json:"assetId,omitempty"`
-	Domain    string `json:"domain,omitempty"`
-	IP        string `json:"ip"`
-	Port      int    `json:"port"`
-	Protocol  string `json:"protocol,omitempty"`
-	Service   string `json:"service,omitempty"`
-	Version   string `json:"version,omitempty"`
-	Banner    string `json:"banner,omitempty"`
-	LastSeen  string `json:"lastSeen,omitempty"`
-	UpdatedAt string `json:"updatedAt,omitempty"`
+	ID           int    `json:"id"`
+	AssetID      int    `json:"assetId,omitempty"`
+	Domain       string `json:"domain,omitempty"`
+	IP           string `json:"ip"`
+	Port         int    `json:"port"`
+	Protocol     string `json:"protocol,omitempty"`
+	Service      string `json:"service,omitempty"`
+	Version      string `json:"version,omitempty"`
+	Banner       string `json:"banner,omitempty"`
+	DiscoveryHop int    `json:"discoveryHop,omitempty"`
+	LastSeen     string `json:"lastSeen,omitempty"`
+	UpdatedAt    string `json:"updatedAt,omitempty"`
 }
 
 type vulnerabilityResponse struct {
@@ -261,6 +281,7 @@ type vulnerabilityResponse struct {
 	VerifiedAt       string `json:"verifiedAt,omitempty"`
 	ReopenCount      int    `json:"reopenCount,omitempty"`
 	LastTransitionAt string `json:"lastTransitionAt,omitempty"`
+	DiscoveryHop     int    `json:"discoveryHop,omitempty"`
 	LastSeen         string `json:"lastSeen,omitempty"`
 }
 
@@ -303,6 +324,8 @@ type monitorRunResponse struct {
 	PortOpened    int    `json:"portOpened"`
 	PortClosed    int    `json:"portClosed"`
 	ServiceChange int    `json:"serviceChange"`
+	DNSChanged    int    `json:"dnsChanged"`
+	CertChanged   int    `json:"certChanged"`
 }
 
 type monitorChangeResponse struct {
@@ -491,28 +514,32 @@ type createMonitorRequest struct {
 }
 
 type projectResponse struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	Owner       string   `json:"owner,omitempty"`
-	Tags        []string `json:"tags"`
-	Archived    bool     `json:"archived"`
-	AIEnabled   bool     `json:"aiEnabled"`
-	RootDomains []string `json:"rootDomains"`
-	CreatedAt   string   `json:"createdAt,omitempty"`
-	UpdatedAt   string   `json:"updatedAt,omitempty"`
-	LastScanAt  string   `json:"lastScanAt,omitempty"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Tags        []string          `json:"tags"`
+	Archived    bool              `json:"archived"`
+	AIEnabled   bool              `json:"aiEnabled"`
+	Wildcard    bool              `json:"wildcardFilter"`
+	RateLimit   engine.HostBudget `json:"rateLimit"`
+	RootDomains []string          `json:"rootDomains"`
+	CreatedAt   string            `json:"createdAt,omitempty"`
+	UpdatedAt   string            `json:"updatedAt,omitempty"`
+	LastScanAt  string            `json:"lastScanAt,omitempty"`
 }
 
 type projectUpsertRequest struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Owner       string   `json:"owner"`
-	Tags        []string `json:"tags"`
-	RootDomains []string `json:"rootDomains"`
-	Archived    *bool    `json:"archived"`
-	AIEnabled   *bool    `json:"aiEnabled"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Owner       string             `json:"owner"`
+	Tags        []string           `json:"tags"`
+	RootDomains []string           `json:"rootDomains"`
+	Archived    *bool              `json:"archived"`
+	AIEnabled   *bool              `json:"aiEnabled"`
+	Wildcard    *bool              `json:"wildcardFilter"`
+	RateLimit   *engine.HostBudget `json:"rateLimit"`
 }
 
 type vulnStatusPatchRequest struct {
@@ -558,13 +585,14 @@ func NewServer(database *db.Database, screenshotDir string) *Server {
 	initialSettings.AI = normalizeRuntimeAISettings(initialSettings.AI)
 
 	s := &Server{
-		db:            database,
-		mux:           http.NewServeMux(),
-		screenshotDir: screenshotDir,
-		corsAllowAll:  corsAllowAll,
-		corsOrigins:   corsOrigins,
-		settings:      initialSettings,
-		scanCancels:   make(map[string]context.CancelFunc),
+		db:             database,
+		mux:            http.NewServeMux(),
+		screenshotDir:  screenshotDir,
+		corsAllowAll:   corsAllowAll,
+		corsOrigins:    corsOrigins,
+		settings:       initialSettings,
+		scanCancels:    make(map[string]context.CancelFunc),
+		lostScanLeases: make(map[string]bool),
 	}
 	if err := s.loadPersistedSettings(); err != nil {
 		log.Printf("[Settings] load persisted settings failed: %v", err)
@@ -593,6 +621,7 @@ func (s *Server) RunWorkers() error {
 		log.Printf("[Worker] recovered %d stale scan jobs", len(recovered))
 		for _, item := range recovered {
 			s.appendJobLog(item.ProjectID, item.JobID, "warn", item.ErrorMessage)
+			s.autoResumeRecoveredScanJob(item)
 			if strings.TrimSpace(item.LastStage) == "" {
 				s.appendJobLogf(item.ProjectID, item.JobID, "warn",
 					"worker recovered stale running scan job: root=%s started_at=%s updated_at=%s cutoff=%s",
@@ -619,8 +648,19 @@ func (s *Server) RunWorkers() error {
 		}
 	}
 
+	s.recoverExpiredScanJobLeases()
+
+	s.worker = newLocalWorker()
+	if err := s.db.RegisterWorker(s.worker); err != nil {
+		return fmt.Errorf("register worker: %v", err)
+	}
+	log.Printf("[Worker] registered worker %s region=%s egress=%s local=%s capabilities=%s tags=%s",
+		s.worker.WorkerID, s.worker.Region, s.worker.EgressIP, s.worker.LocalIP, string(s.worker.Capabilities), string(s.worker.Tags))
+
+	go s.runWorkerHeartbeat()
 	go s.runMonitorScheduler()
 	go s.runScanWorker()
+	go s.runResolverHealthChecks()
 	log.Printf("[Worker] execution workers started (monitor poll=%v, scan poll=%v)", schedulerPollInterval, scanWorkerPollInterval)
 	select {}
 }
@@ -661,12 +701,29 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("/api/auth/check", s.handleAuthCheck)
 
 	s.mux.HandleFunc("/api/projects", s.handleProjects)
+	s.mux.HandleFunc("/api/projects/scope-rules", s.handleProjectScopeRules)
+	s.mux.HandleFunc("/api/projects/wildcard-zones", s.handleWildcardZones)
+	s.mux.HandleFunc("/api/projects/wordlist", s.handleProjectWordlist)
+	s.mux.HandleFunc("/api/projects/wordlist/prune", s.handleProjectWordlistPrune)
+	s.mux.HandleFunc("/api/projects/netblocks", s.handleProjectNetblocks)
+	s.mux.HandleFunc("/api/projects/netblocks/suggest", s.handleProjectNetblocksSuggest)
 	s.mux.HandleFunc("/api/dashboard/summary", s.handleDashboard)
 	s.mux.HandleFunc("/api/jobs", s.handleJobs)
 	s.mux.HandleFunc("/api/jobs/cancel", s.handleCancelJob)
+	s.mux.HandleFunc("/api/jobs/resume", s.handleResumeJob)
+	s.mux.HandleFunc("/api/jobs/plan", s.handleJobPlan)
 	s.mux.HandleFunc("/api/jobs/delete", s.handleDeleteJob)
 	s.mux.HandleFunc("/api/jobs/logs", s.handleJobLogs)
+	s.mux.HandleFunc("/api/pipelines", s.handlePipelines)
+	s.mux.HandleFunc("/api/plugins", s.handlePlugins)
+	s.mux.HandleFunc("/api/workers", s.handleWorkers)
+	s.mux.HandleFunc("/api/results/schema", s.handleResultSchema)
 	s.mux.HandleFunc("/api/assets/detail", s.handleAssetDetail)
+	s.mux.HandleFunc("/api/assets/sources", s.handleAssetSourceStats)
+	s.mux.HandleFunc("/api/assets/dns-records", s.handleDNSRecords)
+	s.mux.HandleFunc("/api/assets/asn", s.handleAssetASN)
+	s.mux.HandleFunc("/api/assets/favicons", s.handleAssetFavicons)
+	s.mux.HandleFunc("/api/assets/certificates", s.handleAssetCertificates)
 	s.mux.HandleFunc("/api/assets", s.handleAssets)
 	s.mux.HandleFunc("/api/ports", s.handlePorts)
 	s.mux.HandleFunc("/api/vulns", s.handleVulns)
@@ -690,6 +747,9 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("/api/search", s.handleGlobalSearch)
 	s.mux.HandleFunc("/api/bulk/assets/delete", s.handleBulkDeleteAssets)
 	s.mux.HandleFunc("/api/settings", s.handleSettings)
+	s.mux.HandleFunc("/api/settings/api-keys", s.handleAPIKeys)
+	s.mux.HandleFunc("/api/settings/resolvers", s.handleResolvers)
+	s.mux.HandleFunc("/api/settings/resolvers/check", s.handleResolversCheck)
 	s.mux.HandleFunc("/api/settings/test-notify", s.handleTestNotify)
 	s.mux.HandleFunc("/api/settings/test-ai", s.handleTestAI)
 	s.mux.HandleFunc("/api/settings/test-ai-subdict", s.handleTestAISubdict)
@@ -730,6 +790,8 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 				Tags:        decodeJSONBStrings(p.Tags),
 				Archived:    p.Archived,
 				AIEnabled:   p.AIEnabled,
+				Wildcard:    p.WildcardFilter,
+				RateLimit:   engine.HostBudget{RequestsPerSecond: p.HostRateLimitRPS, MaxConcurrent: p.HostMaxConcurrency},
 				RootDomains: rootDomains,
 				CreatedAt:   timeToISO(p.CreatedAt),
 				UpdatedAt:   timeToISO(p.UpdatedAt),
@@ -767,6 +829,12 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 			Archived:    false,
 			AIEnabled:   req.AIEnabled == nil || *req.AIEnabled,
 		}
+		project.WildcardFilter = req.Wildcard == nil || *req.Wildcard
+		if req.RateLimit != nil {
+			budget := sanitizeHostBudget(*req.RateLimit)
+			project.HostRateLimitRPS = budget.RequestsPerSecond
+			project.HostMaxConcurrency = budget.MaxConcurrent
+		}
 		err := s.db.DB.Transaction(func(tx *gorm.DB) error {
 			if err := tx.Create(&project).Error; err != nil {
 				return err
@@ -818,6 +886,14 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 		if req.AIEnabled != nil {
 			updates["ai_enabled"] = *req.AIEnabled
 		}
+		if req.Wildcard != nil {
+			updates["wildcard_filter"] = *req.Wildcard
+		}
+		if req.RateLimit != nil {
+			budget := sanitizeHostBudget(*req.RateLimit)
+			updates["host_rate_limit_rps"] = budget.RequestsPerSecond
+			updates["host_max_concurrency"] = budget.MaxConcurrent
+		}
 		if req.Tags != nil {
 			tagsJSON, _ := json.Marshal(dedupTrimmed(req.Tags))
 			updates["tags"] = tagsJSON
@@ -1062,7 +1138,7 @@ func (s *Server) runScanWorker() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		job, err := s.db.ClaimPendingScanJob()
+		job, err := s.db.ClaimPendingScanJob(s.worker, scanJobLeaseTTL, time.Now().Add(-workerOfflineAfter))
 		if err != nil {
 			log.Printf("[Worker] claim pending scan job failed: %v", err)
 			continue
@@ -1070,7 +1146,9 @@ func (s *Server) runScanWorker() {
 		if job == nil {
 			continue
 		}
+		s.setCurrentScanJob(job.JobID)
 		s.executeClaimedScanJob(job)
+		s.setCurrentScanJob("")
 	}
 }
 
@@ -1080,14 +1158,14 @@ func (s *Server) executeClaimedScanJob(job *db.ScanJob) {
 	}
 	modules := sanitizeModules(strings.Split(job.Modules, ","))
 	enableNuclei := job.EnableNuclei || containsAnyModule(modules, "nuclei")
-	activeSubs := job.ActiveSubs || containsAnyModule(modules, "dnsx_bruteforce", "dictgen")
+	activeSubs := job.ActiveSubs || containsAnyModule(modules, "dnsx_bruteforce", "dns_bruteforce", "dictgen")
 	dictSize := job.DictSize
 	dnsResolvers := strings.TrimSpace(job.DNSResolvers)
 	dryRun := job.DryRun
 	notify := job.Notify
 	log.Printf("[Worker] claimed scan job %s project=%s root=%s modules=%v", job.JobID, job.ProjectID, job.RootDomain, modules)
-	s.appendJobLogf(job.ProjectID, job.JobID, "info", "Worker claimed job: root=%s modules=%v", job.RootDomain, modules)
-	s.runScanAsync(job.ProjectID, job.JobID, job.RootDomain, modules, enableNuclei, activeSubs, dictSize, dnsResolvers, dryRun, notify)
+	s.appendJobLogf(job.ProjectID, job.JobID, "info", "Worker %s claimed job: root=%s modules=%v", job.WorkerID, job.RootDomain, modules)
+	s.runScanAsync(job.ProjectID, job.JobID, job.RootDomain, modules, strings.TrimSpace(job.Pipeline), enableNuclei, activeSubs, dictSize, dnsResolvers, job.RecurseDepth, dryRun, notify)
 }
 
 func (s *Server) runMonitorScheduler() {
@@ -1137,10 +1215,12 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 		return
 	}
 	establishBaseline := !target.BaselineDone
+	runCtx := s.withHostLimiter(context.Background(), task.ProjectID, jobID)
+	runCtx = s.withAPIKeys(runCtx, task.ProjectID, jobID)
 
 	// Collect subdomains.
 	s.appendJobLog(task.ProjectID, jobID, "info", "Stage: collect subdomains")
-	subResults, subdomains, err := s.collectSubdomains(context.Background(), []string{rootDomain}, true)
+	subResults, subdomains, err := s.collectSubdomains(runCtx, []string{rootDomain}, true, false, nil)
 	if err != nil {
 		errMsg := fmt.Sprintf("subdomain collection failed: %v", err)
 		log.Printf("[Scheduler] %s", errMsg)
@@ -1150,10 +1230,19 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 		return
 	}
 	s.appendJobLogf(task.ProjectID, jobID, "info", "Subdomain collection completed: unique=%d result_items=%d", len(subdomains), len(subResults))
+	dnsResolvers, removeResolversFile := s.jobResolversFile(task.ProjectID, jobID, "")
+	defer removeResolversFile()
+	subdomains, wildcardHits := s.filterWildcardSubdomains(runCtx, task.ProjectID, jobID, rootDomain, subdomains, dnsResolvers)
+	subResults = engine.WithoutDomains(subResults, wildcardHits)
+
+	// Resolve DNS records; changes are diffed against the stored inventory.
+	dnsResults := s.collectDNSRecords(runCtx, task.ProjectID, jobID, subdomains, dnsResolvers, nil)
+	dnsChanged := s.syncMonitorDNSRecords(task.ProjectID, rootDomain, fmt.Sprintf("mon-run-%d", run.ID), run.ID, dnsResults, establishBaseline)
 
 	// Run network pipeline (httpx + ports).
 	s.appendJobLogf(task.ProjectID, jobID, "info", "Stage: network discovery (targets=%d)", len(subdomains))
-	networkResults, err := s.runNetworkPipeline(context.Background(), subdomains, true, target.MonitorPorts, false, false, false, false, s.screenshotDir)
+	scope := s.scanScope(task.ProjectID, jobID)
+	networkResults, err := s.runNetworkPipeline(runCtx, subdomains, true, target.MonitorPorts, false, false, false, false, s.screenshotDir, engine.Recursion{}, scope, nil, nil)
 	if err != nil {
 		log.Printf("[Scheduler] network pipeline warning for %s: %v", rootDomain, err)
 		s.appendJobLogf(task.ProjectID, jobID, "warn", "Network discovery completed with warnings: %v", err)
@@ -1162,7 +1251,10 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 	s.appendJobLogf(task.ProjectID, jobID, "info", "Network discovery completed: web=%d ports=%d", networkCounts["web_services"], networkCounts["ports"])
 	currentSnapshot := buildMonitorSnapshotPayload(networkResults)
 
-	allResults := append(subResults, networkResults...)
+	// Certificates are diffed against the stored inventory before it is
+	// updated, so they are stored here rather than with the other results.
+	certChanged := s.syncMonitorCertificates(scope, task.ProjectID, rootDomain, fmt.Sprintf("mon-run-%d", run.ID), run.ID, networkResults, establishBaseline)
+	allResults := append(subResults, withoutResultType(networkResults, engine.ResultTypeTLSCertificate)...)
 
 	// Save results to DB.
 	if dbErr := s.saveResultsToDB(task.ProjectID, rootDomain, fmt.Sprintf("mon-run-%d", run.ID), allResults); dbErr != nil {
@@ -1192,7 +1284,7 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 				s.appendJobLog(task.ProjectID, jobID, "info", "Skip monitor vuln scan: no eligible URLs")
 			} else {
 				s.appendJobLogf(task.ProjectID, jobID, "info", "Stage: monitor vulnerability scan (urls=%d nuclei=%v cors=%v subtakeover=%v)", len(vulnTargets), policy.EnableNuclei, policy.EnableCors, policy.EnableSubtakeover)
-				vulnResults, vulnErr := s.runMonitorVulnPipeline(task.ProjectID, rootDomain, run.ID, vulnTargets, policy.EnableNuclei, policy.EnableCors, policy.EnableSubtakeover)
+				vulnResults, vulnErr := s.runMonitorVulnPipeline(runCtx, task.ProjectID, rootDomain, run.ID, vulnTargets, scope, policy.EnableNuclei, policy.EnableCors, policy.EnableSubtakeover)
 				if vulnErr != nil {
 					s.appendJobLogf(task.ProjectID, jobID, "warn", "Monitor vulnerability scan warning: %v", vulnErr)
 				}
@@ -1206,6 +1298,8 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 	// Complete run.
 	status := "success"
 	_ = s.db.CompleteMonitorRun(run.ID, status, "", newLive, webChanged, portOpened, portClosed, svcChanged)
+	_ = s.db.SetMonitorRunDNSChanges(run.ID, dnsChanged)
+	_ = s.db.SetMonitorRunCertChanges(run.ID, certChanged)
 
 	// Update target last run info and establish baseline version on first successful run.
 	now := time.Now()
@@ -1214,10 +1308,10 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 	// Complete task and schedule next.
 	_ = s.db.CompleteMonitorTaskSuccess(task.ID)
 
-	totalChanges := newLive + webChanged + portOpened + portClosed + svcChanged
+	totalChanges := newLive + webChanged + portOpened + portClosed + svcChanged + dnsChanged + certChanged
 	log.Printf("[Scheduler] monitor task %d completed for %s: %d total changes", task.ID, rootDomain, totalChanges)
-	s.appendJobLogf(task.ProjectID, jobID, "info", "Monitor task completed: changes=%d (new_live=%d web_changed=%d port_opened=%d port_closed=%d service_changed=%d) new_vulns=%d",
-		totalChanges, newLive, webChanged, portOpened, portClosed, svcChanged, monitorVulnCount)
+	s.appendJobLogf(task.ProjectID, jobID, "info", "Monitor task completed: changes=%d (new_live=%d web_changed=%d port_opened=%d port_closed=%d service_changed=%d dns_changed=%d cert_changed=%d) new_vulns=%d",
+		totalChanges, newLive, webChanged, portOpened, portClosed, svcChanged, dnsChanged, certChanged, monitorVulnCount)
 
 	// Send notification if changes detected.
 	if totalChanges > 0 {
@@ -1238,7 +1332,8 @@ func (s *Server) executeMonitorTask(task *db.MonitorTask) {
 				stats := map[string]int{
 					"new_live": newLive, "web_changed": webChanged,
 					"port_opened": portOpened, "port_closed": portClosed,
-					"service_changed": svcChanged,
+					"service_changed": svcChanged, "dns_changed": dnsChanged,
+					"cert_changed": certChanged,
 				}
 				aiSummary := ""
 				if target != nil && target.NotifyAISummary {
@@ -1794,7 +1889,7 @@ func (s *Server) collectMonitorVulnTargets(projectID string, runID uint, include
 	return urls, nil
 }
 
-func (s *Server) runMonitorVulnPipeline(projectID, rootDomain string, runID uint, urls []string, enableNuclei, enableCors, enableSubtakeover bool) ([]engine.Result, error) {
+func (s *Server) runMonitorVulnPipeline(ctx context.Context, projectID, rootDomain string, runID uint, urls []string, scope *engine.Scope, enableNuclei, enableCors, enableSubtakeover bool) ([]engine.Result, error) {
 	if len(urls) == 0 {
 		return []engine.Result{}, nil
 	}
@@ -1805,6 +1900,10 @@ func (s *Server) runMonitorVulnPipeline(projectID, rootDomain string, runID uint
 		if trimmed == "" {
 			continue
 		}
+		if ok, reason := scope.Check(trimmed); !ok {
+			log.Printf("[Scope] monitor run=%d skipping out-of-scope target %s: %s", runID, trimmed, reason)
+			continue
+		}
 		inputs = append(inputs, trimmed+"|"+rootDomain)
 	}
 	if len(inputs) == 0 {
@@ -1815,21 +1914,21 @@ func (s *Server) runMonitorVulnPipeline(projectID, rootDomain string, runID uint
 	var firstErr error
 
 	if enableNuclei {
-		nucleiResults, err := plugins.NewNucleiPlugin().Execute(context.Background(), inputs)
+		nucleiResults, err := plugins.NewNucleiPlugin().Execute(ctx, inputs)
 		if err != nil {
 			firstErr = err
 		}
 		allResults = append(allResults, nucleiResults...)
 	}
 	if enableCors {
-		corsResults, err := plugins.NewCorsPlugin().Execute(context.Background(), inputs)
+		corsResults, err := plugins.NewCorsPlugin().Execute(ctx, inputs)
 		if err != nil && firstErr == nil {
 			firstErr = err
 		}
 		allResults = append(allResults, corsResults...)
 	}
 	if enableSubtakeover {
-		subtakeoverResults, err := plugins.NewSubTakeoverPlugin().Execute(context.Background(), inputs)
+		subtakeoverResults, err := plugins.NewSubTakeoverPlugin().Execute(ctx, inputs)
 		if err != nil && firstErr == nil {
 			firstErr = err
 		}
@@ -2836,6 +2935,9 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 	search := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
 	liveOnly := isTruthy(r.URL.Query().Get("live_only"))
 	monitorNew := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("monitor_new")))
+	sources := parseAssetSourceFilter(r.URL.Query().Get("source"))
+	sourceOnly := isTruthy(r.URL.Query().Get("source_only"))
+	webFilter := parseWebResponseFilter(r.URL.Query())
 	pool := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("pool")))
 	if pool == "" {
 		pool = "verified"
@@ -2884,6 +2986,7 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "invalid monitor_new (use open|recent24h)")
 			return
 		}
+		base = s.applyAssetSourceFilter(base, "asset_candidates", projectID, sources, sourceOnly)
 
 		var items []db.AssetCandidate
 		query := base.Order(resolveAssetCandidateOrder(r.URL.Query().Get("sort_by"), r.URL.Query().Get("sort_dir")))
@@ -2918,6 +3021,7 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 				})
 			}
 			s.markMonitorNewAssets(projectID, resp)
+			s.attachAssetSources(projectID, resp)
 			writeJSON(w, http.StatusOK, pagedAssetsResponse{
 				Items:    resp,
 				Page:     page,
@@ -2948,6 +3052,7 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 			})
 		}
 		s.markMonitorNewAssets(projectID, resp)
+		s.attachAssetSources(projectID, resp)
 		writeJSON(w, http.StatusOK, resp)
 		return
 	}
@@ -2990,6 +3095,8 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid monitor_new (use open|recent24h)")
 		return
 	}
+	base = s.applyAssetSourceFilter(base, "assets", projectID, sources, sourceOnly)
+	base = s.applyWebResponseFilter(base, projectID, webFilter)
 
 	var assets []db.Asset
 	query := base.Order(resolveAssetOrder(r.URL.Query().Get("sort_by"), r.URL.Query().Get("sort_dir")))
@@ -3019,12 +3126,14 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 				StatusCode:   a.StatusCode,
 				Title:        a.Title,
 				Technologies: decodeJSONBStrings(a.Technologies),
+				DiscoveryHop: a.DiscoveryHop,
 				CreatedAt:    timeToISO(a.CreatedAt),
 				UpdatedAt:    timeToISO(a.UpdatedAt),
 				LastSeen:     timeToISO(a.LastSeen),
 			})
 		}
 		s.markMonitorNewAssets(projectID, resp)
+		s.attachAssetSources(projectID, resp)
 		writeJSON(w, http.StatusOK, pagedAssetsResponse{
 			Items:    resp,
 			Page:     page,
@@ -3052,12 +3161,14 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 			StatusCode:   a.StatusCode,
 			Title:        a.Title,
 			Technologies: decodeJSONBStrings(a.Technologies),
+			DiscoveryHop: a.DiscoveryHop,
 			CreatedAt:    timeToISO(a.CreatedAt),
 			UpdatedAt:    timeToISO(a.UpdatedAt),
 			LastSeen:     timeToISO(a.LastSeen),
 		})
 	}
 	s.markMonitorNewAssets(projectID, resp)
+	s.attachAssetSources(projectID, resp)
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -3191,17 +3302,18 @@ func (s *Server) handlePorts(w http.ResponseWriter, r *http.Request) {
 		resp := make([]portResponse, 0, len(ports))
 		for _, p := range ports {
 			resp = append(resp, portResponse{
-				ID:        int(p.ID),
-				AssetID:   int(p.AssetID),
-				Domain:    p.Domain,
-				IP:        p.IP,
-				Port:      p.Port,
-				Protocol:  p.Protocol,
-				Service:   p.Service,
-				Version:   p.Version,
-				Banner:    p.Banner,
-				LastSeen:  timeToISO(p.LastSeen),
-				UpdatedAt: timeToISO(p.UpdatedAt),
+				ID:           int(p.ID),
+				AssetID:      int(p.AssetID),
+				Domain:       p.Domain,
+				IP:           p.IP,
+				Port:         p.Port,
+				Protocol:     p.Protocol,
+				Service:      p.Service,
+				Version:      p.Version,
+				Banner:       p.Banner,
+				DiscoveryHop: p.DiscoveryHop,
+				LastSeen:     timeToISO(p.LastSeen),
+				UpdatedAt:    timeToISO(p.UpdatedAt),
 			})
 		}
 		writeJSON(w, http.StatusOK, pagedPortsResponse{
@@ -3222,17 +3334,18 @@ func (s *Server) handlePorts(w http.ResponseWriter, r *http.Request) {
 	resp := make([]portResponse, 0, len(ports))
 	for _, p := range ports {
 		resp = append(resp, portResponse{
-			ID:        int(p.ID),
-			AssetID:   int(p.AssetID),
-			Domain:    p.Domain,
-			IP:        p.IP,
-			Port:      p.Port,
-			Protocol:  p.Protocol,
-			Service:   p.Service,
-			Version:   p.Version,
-			Banner:    p.Banner,
-			LastSeen:  timeToISO(p.LastSeen),
-			UpdatedAt: timeToISO(p.UpdatedAt),
+			ID:           int(p.ID),
+			AssetID:      int(p.AssetID),
+			Domain:       p.Domain,
+			IP:           p.IP,
+			Port:         p.Port,
+			Protocol:     p.Protocol,
+			Service:      p.Service,
+			Version:      p.Version,
+			Banner:       p.Banner,
+			DiscoveryHop: p.DiscoveryHop,
+			LastSeen:     timeToISO(p.LastSeen),
+			UpdatedAt:    timeToISO(p.UpdatedAt),
 		})
 	}
 
@@ -3307,7 +3420,7 @@ func (s *Server) handleVulns(w http.ResponseWriter, r *http.Request) {
 				TicketRef: v.TicketRef, DueAt: timePtrToISO(v.DueAt),
 				FixedAt: timePtrToISO(v.FixedAt), VerifiedAt: timePtrToISO(v.VerifiedAt),
 				ReopenCount: v.ReopenCount, LastTransitionAt: timePtrToISO(v.LastTransitionAt),
-				LastSeen: timeToISO(v.LastSeen),
+				LastSeen: timeToISO(v.LastSeen), DiscoveryHop: v.DiscoveryHop,
 			})
 		}
 		writeJSON(w, http.StatusOK, pagedVulnsResponse{
@@ -3340,7 +3453,7 @@ func (s *Server) handleVulns(w http.ResponseWriter, r *http.Request) {
 			TicketRef: v.TicketRef, DueAt: timePtrToISO(v.DueAt),
 			FixedAt: timePtrToISO(v.FixedAt), VerifiedAt: timePtrToISO(v.VerifiedAt),
 			ReopenCount: v.ReopenCount, LastTransitionAt: timePtrToISO(v.LastTransitionAt),
-			LastSeen: timeToISO(v.LastSeen),
+			LastSeen: timeToISO(v.LastSeen), DiscoveryHop: v.DiscoveryHop,
 		})
 	}
 	writeJSON(w, http.StatusOK, resp)
@@ -3380,6 +3493,7 @@ func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 		RootDomain   string     `gorm:"column:root_domain"`
 		Mode         string     `gorm:"column:mode"`
 		Modules      string     `gorm:"column:modules"`
+		Pipeline     string     `gorm:"column:pipeline"`
 		Status       string     `gorm:"column:status"`
 		StartedAt    time.Time  `gorm:"column:started_at"`
 		FinishedAt   *time.Time `gorm:"column:finished_at"`
@@ -3397,6 +3511,7 @@ SELECT
 	sj.root_domain AS root_domain,
 	sj.mode AS mode,
 	COALESCE(sj.modules, '') AS modules,
+	COALESCE(sj.pipeline, '') AS pipeline,
 	COALESCE(sj.status, '') AS status,
 	COALESCE(sj.started_at, sj.created_at) AS started_at,
 	sj.finished_at AS finished_at,
@@ -3414,6 +3529,7 @@ SELECT
 	mt.root_domain AS root_domain,
 	'monitor' AS mode,
 	'subs,ports,monitor' AS modules,
+	'' AS pipeline,
 	COALESCE(mt.status, '') AS status,
 	COALESCE(mt.started_at, mt.created_at) AS started_at,
 	mt.finished_at AS finished_at,
@@ -3457,8 +3573,8 @@ WHERE mt.project_id = ? AND mt.deleted_at IS NULL
 
 	if search != "" {
 		pattern := "%" + search + "%"
-		whereParts = append(whereParts, "(LOWER(id) LIKE ? OR LOWER(root_domain) LIKE ? OR LOWER(status) LIKE ? OR LOWER(error_message) LIKE ? OR LOWER(modules) LIKE ?)")
-		args = append(args, pattern, pattern, pattern, pattern, pattern)
+		whereParts = append(whereParts, "(LOWER(id) LIKE ? OR LOWER(root_domain) LIKE ? OR LOWER(status) LIKE ? OR LOWER(error_message) LIKE ? OR LOWER(modules) LIKE ? OR LOWER(pipeline) LIKE ?)")
+		args = append(args, pattern, pattern, pattern, pattern, pattern, pattern)
 	}
 
 	whereSQL := ""
@@ -3475,7 +3591,7 @@ WHERE mt.project_id = ? AND mt.deleted_at IS NULL
 		}
 	}
 
-	dataSQL := "SELECT id, project_id, root_domain, mode, modules, status, started_at, finished_at, duration_sec, error_message, subdomain_cnt, port_cnt, vuln_cnt FROM (" + baseCombinedSQL + ") AS combined" + whereSQL + " ORDER BY " + resolveJobOrder(r.URL.Query().Get("sort_by"), r.URL.Query().Get("sort_dir"))
+	dataSQL := "SELECT id, project_id, root_domain, mode, modules, pipeline, status, started_at, finished_at, duration_sec, error_message, subdomain_cnt, port_cnt, vuln_cnt FROM (" + baseCombinedSQL + ") AS combined" + whereSQL + " ORDER BY " + resolveJobOrder(r.URL.Query().Get("sort_by"), r.URL.Query().Get("sort_dir"))
 	dataArgs := append([]interface{}{}, args...)
 	if paged {
 		offset := (page - 1) * pageSize
@@ -3509,6 +3625,7 @@ WHERE mt.project_id = ? AND mt.deleted_at IS NULL
 			RootDomain:   row.RootDomain,
 			Mode:         row.Mode,
 			Modules:      modules,
+			Pipeline:     row.Pipeline,
 			Status:       normalizeHealthStatus(row.Status),
 			StartedAt:    timeToISO(row.StartedAt),
 			FinishedAt:   timePtrToISO(row.FinishedAt),
@@ -3538,23 +3655,84 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
+	job, status, err := s.resolveJobRequest(req)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	projectID, rootDomain, mode, modules, pipelineName := job.ProjectID, job.RootDomain, job.Mode, job.Modules, job.Pipeline
+	notify, workerTags, recurseDepth := job.Notify, job.WorkerTags, job.RecurseDepth
+
+	now := time.Now().UTC()
+	jobID := fmt.Sprintf("scan-%d", now.UnixNano())
+
+	// Persist scan job to DB
+	scanJob := db.ScanJob{
+		JobID:        jobID,
+		ProjectID:    projectID,
+		RootDomain:   rootDomain,
+		Mode:         mode,
+		Modules:      strings.Join(modules, ","),
+		Pipeline:     pipelineName,
+		Status:       "pending",
+		EnableNuclei: job.EnableNuclei,
+		ActiveSubs:   job.ActiveSubs,
+		DictSize:     req.DictSize,
+		DNSResolvers: strings.TrimSpace(req.DNSResolvers),
+		DryRun:       req.DryRun,
+		Notify:       notify,
+		Requires:     strings.Join(jobRequiredPlugins(modules, pipelineName), ","),
+		WorkerTags:   strings.Join(workerTags, ","),
+		RecurseDepth: recurseDepth,
+	}
+	if err := s.db.CreateScanJob(&scanJob); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create scan job: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, jobOverviewResponse{
+		ID: jobID, ProjectID: projectID, RootDomain: rootDomain, Mode: mode, Modules: modules, Pipeline: pipelineName,
+		Status: "pending", StartedAt: now.Format(time.RFC3339),
+	})
+	s.appendJobLogf(projectID, jobID, "info", "Job created and queued: root=%s modules=%v pipeline=%s recurseDepth=%d dryRun=%v notify=%v", rootDomain, modules, pipelineName, recurseDepth, req.DryRun, notify)
+	s.writeAudit(projectID, actorFromRequest(r), "create_scan", "job", jobID, map[string]interface{}{
+		"domain": rootDomain, "modules": modules, "pipeline": pipelineName, "dryRun": req.DryRun, "notify": notify, "workerTags": workerTags, "recurseDepth": recurseDepth,
+	}, r)
+}
+
+// resolvedJobRequest is a validated createJobRequest with the server defaults
+// applied. Job creation and planning share it so a plan shows exactly what a
+// created job would run.
+type resolvedJobRequest struct {
+	ProjectID    string
+	RootDomain   string
+	Mode         string
+	Modules      []string
+	Pipeline     string
+	EnableNuclei bool
+	ActiveSubs   bool
+	Notify       bool
+	WorkerTags   []string
+	RecurseDepth int
+}
+
+// resolveJobRequest validates req and resolves its module list. The returned
+// status is the HTTP status to report when err is not nil.
+func (s *Server) resolveJobRequest(req createJobRequest) (resolvedJobRequest, int, error) {
+	var job resolvedJobRequest
 	projectID := strings.TrimSpace(req.ProjectID)
 	if projectID == "" {
-		writeError(w, http.StatusBadRequest, "projectId is required")
-		return
+		return job, http.StatusBadRequest, fmt.Errorf("projectId is required")
 	}
 
 	rootDomain := normalizeRootDomain(req.Domain)
 	if rootDomain == "" {
-		writeError(w, http.StatusBadRequest, "domain is required")
-		return
+		return job, http.StatusBadRequest, fmt.Errorf("domain is required")
 	}
 	if ok, err := s.isDomainInProjectScope(projectID, rootDomain); err != nil {
-		writeError(w, http.StatusInternalServerError, "project scope check failed: "+err.Error())
-		return
+		return job, http.StatusInternalServerError, fmt.Errorf("project scope check failed: %v", err)
 	} else if !ok {
-		writeError(w, http.StatusBadRequest, "domain is not in project scope")
-		return
+		return job, http.StatusBadRequest, fmt.Errorf("domain is not in project scope")
 	}
 
 	mode := strings.ToLower(strings.TrimSpace(req.Mode))
@@ -3562,12 +3740,10 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		mode = "scan"
 	}
 	if mode == "monitor" {
-		writeError(w, http.StatusBadRequest, "monitor tasks must be created from /api/monitor/targets")
-		return
+		return job, http.StatusBadRequest, fmt.Errorf("monitor tasks must be created from /api/monitor/targets")
 	}
 	if mode != "scan" {
-		writeError(w, http.StatusBadRequest, "mode must be scan")
-		return
+		return job, http.StatusBadRequest, fmt.Errorf("mode must be scan")
 	}
 	notify := true
 	if req.Notify != nil {
@@ -3588,8 +3764,23 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		activeSubs = *req.ActiveSubs
 	}
 
+	pipelineName := strings.TrimSpace(req.Pipeline)
+	if unknown := unknownModules(req.Modules); len(unknown) > 0 {
+		return job, http.StatusBadRequest, fmt.Errorf("unknown modules: %s", strings.Join(unknown, ", "))
+	}
 	modules := sanitizeModules(req.Modules)
-	if len(modules) == 0 {
+	if pipelineName != "" {
+		def, err := pipelines.Get(pipelineName)
+		if err != nil {
+			return job, http.StatusBadRequest, err
+		}
+		// Pipeline jobs run exactly the nodes of their definition; modules
+		// stays empty so it keeps naming fixed stages only.
+		scanners := def.ScannerNames()
+		modules = nil
+		enableNuclei = containsModule(scanners, "nuclei")
+		activeSubs = containsAnyModule(scanners, "dnsx_bruteforce", "dns_bruteforce")
+	} else if len(modules) == 0 {
 		if mode == "monitor" {
 			modules = []string{"subs", "ports", "monitor"}
 		} else {
@@ -3608,42 +3799,30 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		if enableNuclei && !containsAnyModule(modules, "nuclei") {
 			modules = append(modules, "nuclei")
 		}
-		if activeSubs && !containsAnyModule(modules, "dnsx_bruteforce", "dictgen") {
+		if activeSubs && !containsAnyModule(modules, "dnsx_bruteforce", "dns_bruteforce", "dictgen") {
 			modules = append(modules, "dnsx_bruteforce")
 		}
 	}
 
-	now := time.Now().UTC()
-	jobID := fmt.Sprintf("scan-%d", now.UnixNano())
+	workerTags := normalizeWorkerTags(req.WorkerTags)
+	recurseDepth := envIntOrDefault("SCAN_RECURSE_DEPTH", 0)
+	if req.RecurseDepth != nil {
+		recurseDepth = *req.RecurseDepth
+	}
+	recurseDepth = clampRecurseDepth(recurseDepth)
 
-	// Persist scan job to DB
-	scanJob := db.ScanJob{
-		JobID:        jobID,
+	return resolvedJobRequest{
 		ProjectID:    projectID,
 		RootDomain:   rootDomain,
 		Mode:         mode,
-		Modules:      strings.Join(modules, ","),
-		Status:       "pending",
+		Modules:      modules,
+		Pipeline:     pipelineName,
 		EnableNuclei: enableNuclei,
 		ActiveSubs:   activeSubs,
-		DictSize:     req.DictSize,
-		DNSResolvers: strings.TrimSpace(req.DNSResolvers),
-		DryRun:       req.DryRun,
 		Notify:       notify,
-	}
-	if err := s.db.CreateScanJob(&scanJob); err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to create scan job: "+err.Error())
-		return
-	}
-
-	writeJSON(w, http.StatusOK, jobOverviewResponse{
-		ID: jobID, ProjectID: projectID, RootDomain: rootDomain, Mode: mode, Modules: modules,
-		Status: "pending", StartedAt: now.Format(time.RFC3339),
-	})
-	s.appendJobLogf(projectID, jobID, "info", "Job created and queued: root=%s modules=%v dryRun=%v notify=%v", rootDomain, modules, req.DryRun, notify)
-	s.writeAudit(projectID, actorFromRequest(r), "create_scan", "job", jobID, map[string]interface{}{
-		"domain": rootDomain, "modules": modules, "dryRun": req.DryRun, "notify": notify,
-	}, r)
+		WorkerTags:   workerTags,
+		RecurseDepth: recurseDepth,
+	}, http.StatusOK, nil
 }
 
 func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
@@ -3679,6 +3858,78 @@ func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "canceled", "jobId": body.JobID})
 }
 
+func (s *Server) handleResumeJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var body struct {
+		ProjectID string `json:"projectId"`
+		JobID     string `json:"jobId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.JobID) == "" {
+		writeError(w, http.StatusBadRequest, "jobId is required")
+		return
+	}
+	jobID := strings.TrimSpace(body.JobID)
+	projectID := strings.TrimSpace(body.ProjectID)
+	if projectID == "" {
+		job, err := s.db.GetScanJob(jobID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		projectID = job.ProjectID
+	}
+
+	job, err := s.db.ResumeScanJob(projectID, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		if errors.Is(err, db.ErrJobNotResumable) {
+			writeError(w, http.StatusConflict, "only failed or canceled scan jobs can be resumed")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "resume job failed: "+err.Error())
+		return
+	}
+	checkpointed, _ := s.db.CountScanStageCheckpoints(projectID, jobID)
+	s.appendJobLogf(projectID, jobID, "info", "Job resumed by user and re-queued: checkpointedStages=%d resumeCount=%d", checkpointed, job.ResumeCount)
+	s.writeAudit(projectID, actorFromRequest(r), "resume_scan", "job", jobID, map[string]interface{}{
+		"rootDomain": job.RootDomain, "checkpointedStages": checkpointed, "resumeCount": job.ResumeCount,
+	}, r)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":             "pending",
+		"jobId":              jobID,
+		"checkpointedStages": checkpointed,
+		"resumeCount":        job.ResumeCount,
+	})
+}
+
+// autoResumeRecoveredScanJob re-queues a job recovered from a dead worker when
+// it has checkpoints to continue from.
+func (s *Server) autoResumeRecoveredScanJob(item db.RecoveredScanJob) {
+	if !envBoolOrDefault("SCAN_AUTO_RESUME", true) {
+		return
+	}
+	checkpointed, err := s.db.CountScanStageCheckpoints(item.ProjectID, item.JobID)
+	if err != nil || checkpointed == 0 {
+		return
+	}
+	job, err := s.db.GetScanJob(item.JobID)
+	if err != nil || job.ResumeCount >= scanJobMaxAutoResumes {
+		return
+	}
+	if _, err := s.db.ResumeScanJob(item.ProjectID, item.JobID); err != nil {
+		log.Printf("[Worker] auto resume job %s failed: %v", item.JobID, err)
+		return
+	}
+	s.appendJobLogf(item.ProjectID, item.JobID, "info", "worker re-queued recovered job to resume from %d checkpointed stages", checkpointed)
+}
+
 func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -3853,9 +4104,14 @@ func (s *Server) resolveJobStatus(projectID, jobID string) string {
 }
 
 // runScanAsync executes the scan pipeline in a background goroutine.
-func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []string, enableNuclei, activeSubs bool, dictSize int, dnsResolvers string, dryRun, notify bool) {
+func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []string, pipelineName string, enableNuclei, activeSubs bool, dictSize int, dnsResolvers string, recurseDepth int, dryRun, notify bool) {
 	startTime := time.Now()
 	ctx, cancel := context.WithCancel(context.Background())
+	ctx = engine.WithInvalidResultHandler(ctx, func(scanner string, result engine.Result, err error) {
+		s.appendJobLogf(projectID, jobID, "warn", "Malformed result from %s (schema v%d): %v", scanner, engine.ResultSchemaVersion, err)
+	})
+	ctx = s.withHostLimiter(ctx, projectID, jobID)
+	ctx = s.withAPIKeys(ctx, projectID, jobID)
 
 	s.scanCancelMu.Lock()
 	s.scanCancels[jobID] = cancel
@@ -3869,6 +4125,7 @@ func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []str
 		cancel()
 		s.scanCancelMu.Lock()
 		delete(s.scanCancels, jobID)
+		delete(s.lostScanLeases, jobID)
 		s.scanCancelMu.Unlock()
 	}()
 
@@ -3895,20 +4152,13 @@ func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []str
 		log.Printf("[Settings] load persisted ai settings failed at job start: %v", err)
 	}
 
-	// Frontend may send stage modules (subs/ports/httpx/...) or concrete tool
-	// modules (subfinder/findomain/bbot/naabu/nmap/...); normalize behavior here.
-	hasPassiveSubs := containsAnyModule(modules, "subs", "subfinder", "findomain", "bbot", "shosubgo")
-	hasBbotActive := containsAnyModule(modules, "bbot_active")
-	hasActiveSubs := activeSubs || containsAnyModule(modules, "dnsx_bruteforce", "dictgen")
-	hasSubs := hasPassiveSubs || hasBbotActive || hasActiveSubs
-	hasPorts := containsAnyModule(modules, "ports", "naabu", "nmap")
-	hasWitness := containsAnyModule(modules, "witness", "gowitness")
-	hasNuclei := enableNuclei || containsAnyModule(modules, "nuclei")
-	hasCors := containsAnyModule(modules, "cors")
-	hasSubTakeover := containsAnyModule(modules, "subtakeover")
-	// Nuclei/Cors/Witness depend on live HTTP targets from httpx.
-	// SubTakeover scans hostnames directly and does not require httpx.
-	hasHttpx := containsAnyModule(modules, "httpx") || hasNuclei || hasCors || hasWitness
+	stages := resolveScanStages(modules, enableNuclei, activeSubs)
+	hasBbotActive, hasActiveSubs, hasPermutations, hasSubs := stages.BbotActive, stages.ActiveSubs, stages.Permute, stages.Subs
+	hasPorts, hasWitness, hasNuclei, hasCors := stages.Ports, stages.Witness, stages.Nuclei, stages.Cors
+	hasSubTakeover, hasHttpx, hasDNS, hasZoneTransfer := stages.SubTakeover, stages.Httpx, stages.DNS, stages.ZoneTransfer
+
+	dnsResolvers, removeResolversFile := s.jobResolversFile(projectID, jobID, dnsResolvers)
+	defer removeResolversFile()
 
 	s.settingsMu.RLock()
 	screenshotDir := s.screenshotDir
@@ -3921,69 +4171,187 @@ func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []str
 	s.settingsMu.RUnlock()
 
 	dictSize = clampDictSize(dictSize)
-	s.appendJobLogf(projectID, jobID, "debug", "Execution params: hasSubs=%v hasBbotActive=%v hasActiveSubs=%v hasHttpx=%v hasPorts=%v hasNuclei=%v hasCors=%v hasSubTakeover=%v hasWitness=%v dictSize=%d",
-		hasSubs, hasBbotActive, hasActiveSubs, hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness, dictSize)
+	scope := s.scanScope(projectID, jobID)
+	recursion := engine.Recursion{MaxDepth: clampRecurseDepth(recurseDepth)}
+	if scope != nil {
+		recursion.InScope = scope.Allows
+	}
+	s.appendJobLogf(projectID, jobID, "debug", "Execution params: hasSubs=%v hasZoneTransfer=%v hasBbotActive=%v hasActiveSubs=%v hasPermutations=%v hasHttpx=%v hasPorts=%v hasNuclei=%v hasCors=%v hasSubTakeover=%v hasWitness=%v hasDNS=%v dictSize=%d recurseDepth=%d",
+		hasSubs, hasZoneTransfer, hasBbotActive, hasActiveSubs, hasPermutations, hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness, hasDNS, dictSize, recursion.MaxDepth)
 
 	var allResults []engine.Result
 	var scanErr error
 	domains := []string{rootDomain}
 
+	// Persist results as they arrive instead of only at finishScan, so long
+	// nuclei/port runs show progress and a crashed worker keeps partial data.
+	var sink *scanResultSink
+	var emit engine.ResultHandler
+	if !dryRun {
+		sink = newScanResultSink(s, projectID, rootDomain, jobID, scope)
+		emit = sink.Handle
+	}
+	// Completed stages are checkpointed; a resumed job restores them instead
+	// of running them again.
+	checkpoints := newScanCheckpointStore(s, projectID, jobID)
+	if n, err := s.db.CountScanStageCheckpoints(projectID, jobID); err == nil && n > 0 {
+		s.appendJobLogf(projectID, jobID, "info", "Resuming job from %d checkpointed stages", n)
+	}
+
 	if err := s.checkScanCanceled(ctx, jobID); err != nil {
-		s.finishScan(projectID, rootDomain, jobID, startTime, nil, err, dryRun, notify)
+		s.finishScan(projectID, rootDomain, jobID, startTime, nil, sink, err, dryRun, notify)
 		return
 	}
 
-	if hasSubs {
-		s.appendJobLog(projectID, jobID, "info", "Stage started: passive subdomain collection")
-		includePassiveBBOT := !hasBbotActive
-		subResults, subdomains, err := s.collectSubdomains(ctx, domains, includePassiveBBOT)
-		allResults = append(allResults, subResults...)
+	if pipelineName != "" {
+		s.appendJobLogf(projectID, jobID, "info", "Stage started: pipeline %s", pipelineName)
+		pipelineResults, err := s.runNamedPipeline(ctx, pipelineName, domains, plugins.ScannerConfig{
+			RootDomains:   domains,
+			ScreenshotDir: screenshotDir,
+			DNSResolvers:  dnsResolvers,
+			DictSize:      dictSize,
+		}, scope, emit, checkpoints.scoped("pipeline"))
+		allResults = append(allResults, pipelineResults...)
 		if err != nil {
-			scanErr = fmt.Errorf("subdomain collection failed: %v", err)
-			s.appendJobLogf(projectID, jobID, "error", "Passive subdomain collection failed: %v", err)
-			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, scanErr, dryRun, notify)
-			return
+			scanErr = fmt.Errorf("pipeline %s failed: %v", pipelineName, err)
+			s.appendJobLogf(projectID, jobID, "error", "Pipeline %s failed: %v", pipelineName, err)
+		}
+		pipelineCounts := countResults(pipelineResults)
+		s.appendJobLogf(projectID, jobID, "info", "Pipeline %s done: subs=%d web=%d ports=%d vulns=%d",
+			pipelineName, pipelineCounts["subdomains"], pipelineCounts["web_services"], pipelineCounts["ports"], pipelineCounts["vulnerabilities"])
+		if err := s.checkScanCanceled(ctx, jobID); err != nil {
+			scanErr = err
+		}
+		s.appendPluginStatusLogs(projectID, jobID, allResults)
+		s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, scanErr, dryRun, notify)
+		return
+	}
+
+	if hasDNS && !hasSubs {
+		// Without enumeration only the root domains themselves are resolved.
+		allResults = append(allResults, s.collectDNSRecords(ctx, projectID, jobID, domains, dnsResolvers, emit)...)
+	}
+
+	if hasSubs {
+		subdomains, restored := checkpoints.loadDomains("subs_passive")
+		if restored {
+			allResults = append(allResults, domainsAsResults(subdomains)...)
+		} else {
+			s.appendJobLog(projectID, jobID, "info", "Stage started: passive subdomain collection")
+			includePassiveBBOT := !hasBbotActive
+			subResults, passiveSubdomains, err := s.collectSubdomains(ctx, domains, includePassiveBBOT, hasZoneTransfer, emit)
+			allResults = append(allResults, subResults...)
+			if err != nil {
+				scanErr = fmt.Errorf("subdomain collection failed: %v", err)
+				s.appendJobLogf(projectID, jobID, "error", "Passive subdomain collection failed: %v", err)
+				s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, scanErr, dryRun, notify)
+				return
+			}
+			subdomains = passiveSubdomains
+			checkpoints.saveDomains("subs_passive", "subs", len(domains), subdomains)
+			s.appendJobLogf(projectID, jobID, "info", "Passive subdomain collection done: unique=%d resultItems=%d", len(subdomains), len(subResults))
 		}
-		s.appendJobLogf(projectID, jobID, "info", "Passive subdomain collection done: unique=%d resultItems=%d", len(subdomains), len(subResults))
 		if err := s.checkScanCanceled(ctx, jobID); err != nil {
 			s.appendJobLog(projectID, jobID, "warn", "Task canceled")
-			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, err, dryRun, notify)
+			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
 			return
 		}
 
 		if hasBbotActive {
-			s.appendJobLog(projectID, jobID, "info", "Stage started: BBOT active expansion")
-			bbotResults, bbotSubdomains, err := s.expandBbotActiveSubdomains(ctx, domains)
-			allResults = append(allResults, bbotResults...)
-			if err != nil {
-				log.Printf("[Scan] Job %s bbot active warning: %v", jobID, err)
-				s.appendJobLogf(projectID, jobID, "warn", "BBOT active expansion warning: %v", err)
+			if merged, ok := checkpoints.loadDomains("subs_bbot_active"); ok {
+				allResults = append(allResults, domainsAsResults(merged)...)
+				subdomains = mergeUnique(subdomains, merged)
 			} else {
-				before := len(subdomains)
-				subdomains = mergeUnique(subdomains, bbotSubdomains)
-				s.appendJobLogf(projectID, jobID, "info", "BBOT active expansion done: added=%d mergedTotal=%d", len(subdomains)-before, len(subdomains))
-			}
-			if err := s.checkScanCanceled(ctx, jobID); err != nil {
-				s.appendJobLog(projectID, jobID, "warn", "Task canceled")
-				s.finishScan(projectID, rootDomain, jobID, startTime, allResults, err, dryRun, notify)
-				return
+				s.appendJobLog(projectID, jobID, "info", "Stage started: BBOT active expansion")
+				bbotResults, bbotSubdomains, err := s.expandBbotActiveSubdomains(ctx, domains, emit)
+				allResults = append(allResults, bbotResults...)
+				if err != nil {
+					log.Printf("[Scan] Job %s bbot active warning: %v", jobID, err)
+					s.appendJobLogf(projectID, jobID, "warn", "BBOT active expansion warning: %v", err)
+				} else {
+					before := len(subdomains)
+					subdomains = mergeUnique(subdomains, bbotSubdomains)
+					checkpoints.saveDomains("subs_bbot_active", "bbot_active", before, subdomains)
+					s.appendJobLogf(projectID, jobID, "info", "BBOT active expansion done: added=%d mergedTotal=%d", len(subdomains)-before, len(subdomains))
+				}
+				if err := s.checkScanCanceled(ctx, jobID); err != nil {
+					s.appendJobLog(projectID, jobID, "warn", "Task canceled")
+					s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
+					return
+				}
 			}
 		}
 
 		if hasActiveSubs {
-			s.appendJobLogf(projectID, jobID, "info", "Stage started: active subdomain bruteforce (dictSize=%d)", dictSize)
-			activeResults, activeSubdomains, err := s.expandActiveSubdomains(ctx, projectID, domains, subdomains, dictSize, dnsResolvers)
-			allResults = append(allResults, activeResults...)
-			if err != nil {
-				log.Printf("[Scan] Job %s active subs warning: %v", jobID, err)
-				s.appendJobLogf(projectID, jobID, "warn", "Active subdomain bruteforce warning: %v", err)
+			if merged, ok := checkpoints.loadDomains("subs_active"); ok {
+				allResults = append(allResults, domainsAsResults(merged)...)
+				subdomains = mergeUnique(subdomains, merged)
 			} else {
-				subdomains = mergeUnique(subdomains, activeSubdomains)
-				s.appendJobLogf(projectID, jobID, "info", "Active subdomain bruteforce done: added=%d mergedTotal=%d", len(activeSubdomains), len(subdomains))
+				s.appendJobLogf(projectID, jobID, "info", "Stage started: active subdomain bruteforce (dictSize=%d)", dictSize)
+				activeResults, activeSubdomains, err := s.expandActiveSubdomains(ctx, projectID, domains, subdomains, dictSize, dnsResolvers, emit)
+				allResults = append(allResults, activeResults...)
+				if err != nil {
+					log.Printf("[Scan] Job %s active subs warning: %v", jobID, err)
+					s.appendJobLogf(projectID, jobID, "warn", "Active subdomain bruteforce warning: %v", err)
+				} else {
+					before := len(subdomains)
+					subdomains = mergeUnique(subdomains, activeSubdomains)
+					checkpoints.saveDomains("subs_active", plugins.ActiveBruteforceEngine(), before, subdomains)
+					s.appendJobLogf(projectID, jobID, "info", "Active subdomain bruteforce done: added=%d mergedTotal=%d", len(activeSubdomains), len(subdomains))
+				}
+				if err := s.checkScanCanceled(ctx, jobID); err != nil {
+					s.appendJobLog(projectID, jobID, "warn", "Task canceled")
+					s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
+					return
+				}
 			}
+		}
+
+		if hasPermutations {
+			if merged, ok := checkpoints.loadDomains("subs_permutations"); ok {
+				allResults = append(allResults, domainsAsResults(merged)...)
+				subdomains = mergeUnique(subdomains, merged)
+			} else {
+				s.appendJobLogf(projectID, jobID, "info", "Stage started: subdomain permutations (known=%d)", len(subdomains))
+				permResults, permSubdomains, err := s.expandPermutedSubdomains(ctx, domains, subdomains, dnsResolvers, emit)
+				allResults = append(allResults, permResults...)
+				if err != nil {
+					log.Printf("[Scan] Job %s permutations warning: %v", jobID, err)
+					s.appendJobLogf(projectID, jobID, "warn", "Subdomain permutations warning: %v", err)
+				} else {
+					before := len(subdomains)
+					subdomains = mergeUnique(subdomains, permSubdomains)
+					checkpoints.saveDomains("subs_permutations", "permutations", before, subdomains)
+					s.appendJobLogf(projectID, jobID, "info", "Subdomain permutations done: added=%d mergedTotal=%d", len(subdomains)-before, len(subdomains))
+				}
+				if err := s.checkScanCanceled(ctx, jobID); err != nil {
+					s.appendJobLog(projectID, jobID, "warn", "Task canceled")
+					s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
+					return
+				}
+			}
+		}
+
+		if filtered, ok := checkpoints.loadDomains("subs_wildcard"); ok {
+			subdomains = filtered
+		} else {
+			before := len(subdomains)
+			var wildcardHits []string
+			subdomains, wildcardHits = s.filterWildcardSubdomains(ctx, projectID, jobID, rootDomain, subdomains, dnsResolvers)
+			allResults = engine.WithoutDomains(allResults, wildcardHits)
+			checkpoints.saveDomains("subs_wildcard", "wildcard_filter", before, subdomains)
+		}
+		if err := s.checkScanCanceled(ctx, jobID); err != nil {
+			s.appendJobLog(projectID, jobID, "warn", "Task canceled")
+			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
+			return
+		}
+
+		if hasDNS {
+			allResults = append(allResults, s.collectDNSRecords(ctx, projectID, jobID, subdomains, dnsResolvers, emit)...)
 			if err := s.checkScanCanceled(ctx, jobID); err != nil {
 				s.appendJobLog(projectID, jobID, "warn", "Task canceled")
-				s.finishScan(projectID, rootDomain, jobID, startTime, allResults, err, dryRun, notify)
+				s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
 				return
 			}
 		}
@@ -3991,7 +4359,7 @@ func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []str
 		if hasPorts || hasHttpx || hasSubTakeover {
 			s.appendJobLogf(projectID, jobID, "info", "Stage started: network scan (targets=%d httpx=%v ports=%v nuclei=%v cors=%v subtakeover=%v witness=%v)",
 				len(subdomains), hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness)
-			networkResults, err := s.runNetworkPipeline(ctx, subdomains, hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness, screenshotDir)
+			networkResults, err := s.runNetworkPipeline(ctx, subdomains, hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness, screenshotDir, recursion, scope, emit, checkpoints.scoped("network"))
 			allResults = append(allResults, networkResults...)
 			if err != nil {
 				scanErr = fmt.Errorf("network stage failed: %v", err)
@@ -4002,14 +4370,14 @@ func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []str
 				networkCounts["web_services"], networkCounts["ports"], networkCounts["vulnerabilities"])
 			if err := s.checkScanCanceled(ctx, jobID); err != nil {
 				s.appendJobLog(projectID, jobID, "warn", "Task canceled")
-				s.finishScan(projectID, rootDomain, jobID, startTime, allResults, err, dryRun, notify)
+				s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
 				return
 			}
 		}
 	} else if hasPorts || hasHttpx || hasSubTakeover {
 		s.appendJobLogf(projectID, jobID, "info", "Stage started: network scan (targets=%d httpx=%v ports=%v nuclei=%v cors=%v subtakeover=%v witness=%v)",
 			len(domains), hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness)
-		networkResults, err := s.runNetworkPipeline(ctx, domains, hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness, screenshotDir)
+		networkResults, err := s.runNetworkPipeline(ctx, domains, hasHttpx, hasPorts, hasNuclei, hasCors, hasSubTakeover, hasWitness, screenshotDir, recursion, scope, emit, checkpoints.scoped("network"))
 		allResults = append(allResults, networkResults...)
 		if err != nil {
 			scanErr = fmt.Errorf("network stage failed: %v", err)
@@ -4020,13 +4388,88 @@ func (s *Server) runScanAsync(projectID, jobID, rootDomain string, modules []str
 			networkCounts["web_services"], networkCounts["ports"], networkCounts["vulnerabilities"])
 		if err := s.checkScanCanceled(ctx, jobID); err != nil {
 			s.appendJobLog(projectID, jobID, "warn", "Task canceled")
-			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, err, dryRun, notify)
+			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
+			return
+		}
+	}
+
+	if stages.Netblocks {
+		netblockResults, err := s.scanApprovedNetblocks(ctx, projectID, jobID, scope, emit, checkpoints.scoped("netblocks"))
+		allResults = append(allResults, netblockResults...)
+		if err != nil {
+			s.appendJobLogf(projectID, jobID, "warn", "Netblock port scan warning: %v", err)
+		}
+		if err := s.checkScanCanceled(ctx, jobID); err != nil {
+			s.appendJobLog(projectID, jobID, "warn", "Task canceled")
+			s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, err, dryRun, notify)
 			return
 		}
 	}
 
 	s.appendPluginStatusLogs(projectID, jobID, allResults)
-	s.finishScan(projectID, rootDomain, jobID, startTime, allResults, scanErr, dryRun, notify)
+	s.finishScan(projectID, rootDomain, jobID, startTime, allResults, sink, scanErr, dryRun, notify)
+}
+
+// scanStages tells which stages runScanAsync runs for a module list.
+type scanStages struct {
+	PassiveSubs bool
+	BbotActive  bool
+	ActiveSubs  bool
+	Permute     bool
+	DNS         bool
+	Subs        bool
+	Httpx       bool
+	Ports       bool
+	Witness     bool
+	Nuclei      bool
+	Cors        bool
+	SubTakeover bool
+	Netblocks   bool
+
+	// ZoneTransfer adds AXFR/NSEC walking to passive collection. It runs
+	// only when requested by module, since it queries the target's own
+	// nameservers.
+	ZoneTransfer bool
+}
+
+func resolveScanStages(modules []string, enableNuclei, activeSubs bool) scanStages {
+	// Frontend may send stage modules (subs/ports/httpx/...) or concrete tool
+	// modules (subfinder/findomain/bbot/naabu/nmap/...); normalize behavior here.
+	var st scanStages
+	st.PassiveSubs = containsAnyModule(modules, "subs", "subfinder", "chaos", "findomain", "bbot", "shosubgo", "ctlogs", "crtsh", "certspotter", "zonetransfer", "axfr", "nsec_walk")
+	st.ZoneTransfer = containsAnyModule(modules, "zonetransfer", "axfr", "nsec_walk")
+	st.BbotActive = containsAnyModule(modules, "bbot_active")
+	st.ActiveSubs = activeSubs || containsAnyModule(modules, "dnsx_bruteforce", "dns_bruteforce", "dictgen")
+	st.Permute = containsAnyModule(modules, "permutations", "alterations")
+	st.DNS = containsAnyModule(modules, "dns_records", "dns")
+	st.Subs = st.PassiveSubs || st.BbotActive || st.ActiveSubs || st.Permute
+	st.Ports = containsAnyModule(modules, "ports", "naabu", "nmap", "tscan", "tscanclient", "tls_grab")
+	st.Witness = containsAnyModule(modules, "witness", "gowitness")
+	st.Nuclei = enableNuclei || containsAnyModule(modules, "nuclei")
+	st.Cors = containsAnyModule(modules, "cors")
+	st.SubTakeover = containsAnyModule(modules, "subtakeover")
+	st.Netblocks = containsAnyModule(modules, "netblocks")
+	// External plugins run in the stage of their category.
+	for _, m := range modules {
+		info, ok := plugins.LookupPlugin(m)
+		if !ok || !info.External {
+			continue
+		}
+		switch info.Category {
+		case plugins.CategorySubdomain:
+			st.PassiveSubs, st.Subs = true, true
+		case plugins.CategoryWeb:
+			st.Httpx = true
+		case plugins.CategoryPort:
+			st.Ports = true
+		case plugins.CategoryVuln:
+			st.Nuclei = true
+		}
+	}
+	// Nuclei/Cors/Witness depend on live HTTP targets from httpx.
+	// SubTakeover scans hostnames directly and does not require httpx.
+	st.Httpx = st.Httpx || containsAnyModule(modules, "httpx", "http_probe") || st.Nuclei || st.Cors || st.Witness
+	return st
 }
 
 func (s *Server) watchScanJobCancel(ctx context.Context, jobID string, cancel context.CancelFunc) {
@@ -4069,11 +4512,23 @@ func (s *Server) checkScanCanceled(ctx context.Context, jobID string) error {
 	return nil
 }
 
-func (s *Server) finishScan(projectID, rootDomain, jobID string, startTime time.Time, results []engine.Result, scanErr error, dryRun, notify bool) {
+func (s *Server) finishScan(projectID, rootDomain, jobID string, startTime time.Time, results []engine.Result, sink *scanResultSink, scanErr error, dryRun, notify bool) {
 	duration := int(time.Since(startTime).Seconds())
+	if s.scanLeaseLost(jobID) {
+		// The job was recovered or taken over; its row is no longer ours.
+		log.Printf("[Scan] Job %s stopped after losing its lease: duration=%ds", jobID, duration)
+		s.appendJobLogf(projectID, jobID, "warn", "Worker lost the job lease, scan stopped after %ds", duration)
+		return
+	}
 	var dbErr error
 	if !dryRun {
-		dbErr = s.saveResultsToDB(projectID, rootDomain, jobID, results)
+		if sink != nil {
+			// Results were already persisted incrementally by the sink.
+			dbErr = sink.Err()
+			s.appendJobLogf(projectID, jobID, "debug", "Streamed results persisted: %d", sink.Persisted())
+		} else {
+			dbErr = s.saveResultsToDB(projectID, rootDomain, jobID, results)
+		}
 		if dbErr != nil {
 			s.appendJobLogf(projectID, jobID, "error", "Save results to database failed: %v", dbErr)
 		}
@@ -4177,8 +4632,9 @@ func (s *Server) appendPluginStatusLogs(projectID, jobID string, results []engin
 	}
 }
 
-func (s *Server) collectSubdomains(ctx context.Context, rootDomains []string, includePassiveBBOT bool) ([]engine.Result, []string, error) {
+func (s *Server) collectSubdomains(ctx context.Context, rootDomains []string, includePassiveBBOT, includeZoneTransfer bool, emit engine.ResultHandler) ([]engine.Result, []string, error) {
 	pipeline := engine.NewPipeline()
+	pipeline.SetResultHandler(emit)
 	isBatch := len(rootDomains) > 1
 	pipeline.AddDomainScanner(plugins.NewSubfinderPlugin(isBatch))
 	pipeline.AddDomainScanner(plugins.NewChaosPlugin(isBatch))
@@ -4187,21 +4643,28 @@ func (s *Server) collectSubdomains(ctx context.Context, rootDomains []string, in
 		pipeline.AddDomainScanner(plugins.NewBBOTPlugin(true))
 	}
 	pipeline.AddDomainScanner(plugins.NewShosubgoPlugin())
+	pipeline.AddDomainScanner(plugins.NewCTLogsPlugin("", ""))
+	if includeZoneTransfer {
+		pipeline.AddDomainScanner(plugins.NewZoneTransferPlugin())
+	}
+	for _, scanner := range plugins.ExternalScanners(plugins.CategorySubdomain, plugins.ScannerConfig{RootDomains: rootDomains}) {
+		pipeline.AddDomainScanner(scanner)
+	}
 	results, err := pipeline.Execute(ctx, rootDomains)
 	subdomains := extractDomains(results)
 	log.Printf("[Scan] Passive collection: %d unique subdomains", len(subdomains))
 	return results, subdomains, err
 }
 
-func (s *Server) expandBbotActiveSubdomains(ctx context.Context, rootDomains []string) ([]engine.Result, []string, error) {
+func (s *Server) expandBbotActiveSubdomains(ctx context.Context, rootDomains []string, emit engine.ResultHandler) ([]engine.Result, []string, error) {
 	scanner := plugins.NewBBOTPlugin(false)
-	results, err := scanner.Execute(ctx, rootDomains)
+	results, err := engine.ExecuteScanner(ctx, scanner, rootDomains, emit)
 	subdomains := extractDomains(results)
 	log.Printf("[Scan] BBOT active expansion: %d unique subdomains", len(subdomains))
 	return results, subdomains, err
 }
 
-func (s *Server) expandActiveSubdomains(ctx context.Context, projectID string, rootDomains, passiveSubdomains []string, dictSize int, dnsResolvers string) ([]engine.Result, []string, error) {
+func (s *Server) expandActiveSubdomains(ctx context.Context, projectID string, rootDomains, passiveSubdomains []string, dictSize int, dnsResolvers string, emit engine.ResultHandler) ([]engine.Result, []string, error) {
 	var allResults []engine.Result
 	words, aiUsed := s.buildActiveBruteforceWordlist(ctx, projectID, rootDomains, passiveSubdomains, clampDictSize(dictSize))
 	if len(words) == 0 {
@@ -4212,21 +4675,47 @@ func (s *Server) expandActiveSubdomains(ctx context.Context, projectID string, r
 	} else {
 		log.Printf("[Scan] Active wordlist built from passive data: words=%d", len(words))
 	}
-	brutePlugin := plugins.NewDNSXBruteforcePlugin(rootDomains, dnsResolvers)
-	bruteResults, err := brutePlugin.Execute(ctx, words)
+	brutePlugin := plugins.NewActiveBruteforcePlugin(rootDomains, dnsResolvers)
+	bruteResults, err := engine.ExecuteScanner(ctx, brutePlugin, words, emit)
 	allResults = append(allResults, bruteResults...)
 	if err != nil {
 		return allResults, nil, err
 	}
-	return allResults, extractDomains(bruteResults), nil
+	found := extractDomains(bruteResults)
+	s.learnBruteforceWords(ctx, projectID, rootDomains, words, found, dnsResolvers)
+	return allResults, found, nil
 }
 
-func (s *Server) runNetworkPipeline(ctx context.Context, targets []string, enableHTTPX, enablePorts, enableNuclei, enableCors, enableSubTakeover, enableWitness bool, screenshotDir string) ([]engine.Result, error) {
+// expandPermutedSubdomains resolves permutations of the known subdomains
+// with the permutations plugin, so hits are attributed to "permutations".
+// PERMUTATIONS_MAX_CANDIDATES caps the candidate count.
+func (s *Server) expandPermutedSubdomains(ctx context.Context, rootDomains, knownSubdomains []string, dnsResolvers string, emit engine.ResultHandler) ([]engine.Result, []string, error) {
+	plugin := plugins.NewPermutationsPlugin(rootDomains, dnsResolvers, envIntOrDefault("PERMUTATIONS_MAX_CANDIDATES", 5000))
+	results, err := engine.ExecuteScanner(ctx, plugin, knownSubdomains, emit)
+	if err != nil {
+		return results, nil, err
+	}
+	return results, extractDomains(results), nil
+}
+
+func (s *Server) runNetworkPipeline(ctx context.Context, targets []string, enableHTTPX, enablePorts, enableNuclei, enableCors, enableSubTakeover, enableWitness bool, screenshotDir string, recursion engine.Recursion, scope *engine.Scope, emit engine.ResultHandler, checkpoints engine.CheckpointStore) ([]engine.Result, error) {
 	pipeline := engine.NewPipeline()
+	pipeline.SetResultHandler(emit)
+	pipeline.SetRecursion(recursion)
+	pipeline.SetScope(scope)
+	if checkpoints != nil {
+		pipeline.SetCheckpointStore(checkpoints)
+	}
 	if enableHTTPX {
-		pipeline.SetHttpxScanner(plugins.NewHttpxPlugin())
+		pipeline.SetHttpxScanner(plugins.NewWebProbePlugin())
+		for _, scanner := range plugins.ExternalScanners(plugins.CategoryWeb, plugins.ScannerConfig{ScreenshotDir: screenshotDir}) {
+			pipeline.AddNetworkScanner(scanner)
+		}
 	}
 	if enablePorts {
+		for _, scanner := range plugins.ExternalScanners(plugins.CategoryPort, plugins.ScannerConfig{}) {
+			pipeline.AddNetworkScanner(scanner)
+		}
 		switch configuredPortScannerEngine() {
 		case "naabu_nmap":
 			pipeline.AddPortScanner(plugins.NewNaabuPlugin())
@@ -4234,6 +4723,7 @@ func (s *Server) runNetworkPipeline(ctx context.Context, targets []string, enabl
 		default:
 			pipeline.AddPortScanner(plugins.NewTscanPortPlugin())
 		}
+		pipeline.SetTLSScanner(plugins.NewTLSGrabPlugin())
 	}
 	if enableNuclei {
 		pipeline.AddVulnScanner(plugins.NewNucleiPlugin())
@@ -4244,12 +4734,66 @@ func (s *Server) runNetworkPipeline(ctx context.Context, targets []string, enabl
 	if enableSubTakeover {
 		pipeline.AddVulnScanner(plugins.NewSubTakeoverPlugin())
 	}
+	if enableNuclei || enableCors || enableSubTakeover {
+		for _, scanner := range plugins.ExternalScanners(plugins.CategoryVuln, plugins.ScannerConfig{ScreenshotDir: screenshotDir}) {
+			pipeline.AddVulnScanner(scanner)
+		}
+	}
 	if enableWitness {
 		pipeline.SetScreenshotScanner(plugins.NewGowitnessPlugin(screenshotDir))
 	}
 	return pipeline.ExecuteFromSubdomains(ctx, targets)
 }
 
+// runNamedPipeline executes a declarative pipeline definition by name.
+func (s *Server) runNamedPipeline(ctx context.Context, name string, targets []string, cfg plugins.ScannerConfig, scope *engine.Scope, emit engine.ResultHandler, checkpoints engine.CheckpointStore) ([]engine.Result, error) {
+	def, err := pipelines.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := engine.NewDAGPipeline(def, func(node engine.PipelineNode) (engine.Scanner, error) {
+		return plugins.NewScannerByName(node.Scanner, cfg, node.Options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	pipeline.SetResultHandler(emit)
+	pipeline.SetCheckpointStore(checkpoints)
+	pipeline.SetScope(scope)
+	return pipeline.Execute(ctx, targets)
+}
+
+func (s *Server) handlePipelines(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defs, err := pipelines.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "load pipelines failed: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, defs)
+}
+
+func (s *Server) handleResultSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(engine.ResultSchema())
+}
+
+func (s *Server) handlePlugins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, plugins.ListPlugins())
+}
+
 func configuredPortScannerEngine() string {
 	raw := strings.ToLower(strings.TrimSpace(os.Getenv("PORT_SCANNER_ENGINE")))
 	switch raw {
@@ -4263,8 +4807,26 @@ func configuredPortScannerEngine() string {
 }
 
 func (s *Server) saveResultsToDB(projectID, rootDomain, jobID string, results []engine.Result) error {
+	return s.saveScopedResultsToDB(s.scanScope(projectID, jobID), projectID, rootDomain, jobID, results)
+}
+
+// saveScopedResultsToDB persists results, dropping and logging those whose
+// host is outside scope.
+func (s *Server) saveScopedResultsToDB(scope *engine.Scope, projectID, rootDomain, jobID string, results []engine.Result) error {
 	failureCount := 0
 	for _, result := range results {
+		if ok, reason := scope.CheckResult(result); !ok {
+			s.logOutOfScopeResult(projectID, jobID, result, reason)
+			continue
+		}
+		if result.Type == engine.ResultTypeZoneExposure {
+			exposure, err := engine.DecodeResult[engine.ZoneExposure](result)
+			if err != nil {
+				log.Printf("[Scan][DB] decode %s failed: %v", result.Type, err)
+				continue
+			}
+			result = exposure.Vulnerability().Result()
+		}
 		var err error
 		sourceModule := result.Type
 		switch result.Type {
@@ -4278,6 +4840,11 @@ func (s *Server) saveResultsToDB(projectID, rootDomain, jobID string, results []
 					"domain":        subdomain,
 					"verify_status": "pending",
 				})
+				if err == nil {
+					if srcErr := s.db.RecordAssetSources(projectID, rootDomain, subdomain, jobID, plugins.ResultSources(result)); srcErr != nil {
+						log.Printf("[Scan][DB] record sources for %s failed: %v", subdomain, srcErr)
+					}
+				}
 			}
 		case "web_service":
 			if data, ok := result.Data.(map[string]interface{}); ok {
@@ -4352,6 +4919,15 @@ func (s *Server) saveResultsToDB(projectID, rootDomain, jobID string, results []
 					s.saveSimpleEdge(projectID, rootDomain, "domain", mapString(data, "domain"), "vuln", vulnID, "has_vuln", jobID)
 				}
 			}
+		case engine.ResultTypeDNSRecords:
+			var records engine.DNSRecords
+			if records, err = engine.DecodeResult[engine.DNSRecords](result); err == nil {
+				_, _, err = s.db.SyncDNSRecords(projectID, rootDomain, records.Host, jobID, records.ByType(), records.Failed)
+			}
+		case engine.ResultTypeTLSCertificate:
+			_, _, err = s.saveCertificate(scope, projectID, rootDomain, jobID, result)
+		case engine.ResultTypeCTCertificate:
+			err = s.saveCTCertificate(scope, projectID, rootDomain, jobID, result)
 		}
 		if err != nil {
 			failureCount++
@@ -4452,7 +5028,7 @@ func countResults(results []engine.Result) map[string]int {
 				continue
 			}
 			portBuckets[baseKey][domain] = struct{}{}
-		case "vulnerability":
+		case "vulnerability", engine.ResultTypeZoneExposure:
 			counts["vulnerabilities"]++
 		case "screenshot":
 			if data, ok := r.Data.(map[string]interface{}); ok {
@@ -4731,6 +5307,7 @@ func (s *Server) handleMonitorRuns(w http.ResponseWriter, r *http.Request) {
 			DurationSec: run.DurationSec, ErrorMessage: strings.TrimSpace(run.ErrorMessage),
 			NewLiveCount: run.NewLiveCount, WebChanged: run.WebChanged,
 			PortOpened: run.PortOpened, PortClosed: run.PortClosed, ServiceChange: run.ServiceChange,
+			DNSChanged: run.DNSChanged, CertChanged: run.CertChanged,
 		})
 	}
 	writeJSON(w, http.StatusOK, resp)
@@ -5933,19 +6510,35 @@ func parseMonitorEventStatus(raw string) (string, bool) {
 	}
 }
 
+// stageModules are job modules that select a whole scan stage rather than a
+// single registered plugin.
+var stageModules = map[string]bool{"subs": true, "ports": true, "monitor": true, "netblocks": true}
+
+// isValidModule reports whether m is a stage module or a plugin that
+// resolveScanStages maps to a stage. Plugins that would select nothing are
+// rejected rather than silently ignored.
+func isValidModule(m string) bool {
+	return stageModules[m] || resolveScanStages([]string{m}, false, false) != scanStages{}
+}
+
 func sanitizeModules(raw []string) []string {
-	allowed := map[string]bool{
-		"subfinder": true, "findomain": true, "bbot": true, "shosubgo": true,
-		"bbot_active": true, "chaos": true,
-		"dnsx_bruteforce": true,
-		"naabu":           true, "nmap": true,
-		"httpx": true, "gowitness": true, "nuclei": true, "cors": true, "subtakeover": true,
-		"subs": true, "ports": true, "monitor": true, "witness": true,
+	var out []string
+	for _, m := range raw {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if m != "" && isValidModule(m) {
+			out = append(out, m)
+		}
 	}
+	return out
+}
+
+// unknownModules returns the requested modules that are neither stage
+// modules nor plugins that map to a scan stage.
+func unknownModules(raw []string) []string {
 	var out []string
 	for _, m := range raw {
 		m = strings.ToLower(strings.TrimSpace(m))
-		if m != "" && allowed[m] {
+		if m != "" && !isValidModule(m) {
 			out = append(out, m)
 		}
 	}
@@ -6505,6 +7098,7 @@ func matchJobSearch(job jobOverviewResponse, q string) bool {
 	if strings.Contains(strings.ToLower(job.ID), lq) ||
 		strings.Contains(strings.ToLower(job.RootDomain), lq) ||
 		strings.Contains(strings.ToLower(job.Status), lq) ||
+		strings.Contains(strings.ToLower(job.Pipeline), lq) ||
 		strings.Contains(strings.ToLower(job.ErrorMessage), lq) {
 		return true
 	}
@@ -6644,6 +7238,9 @@ type assetDetailResponse struct {
 	Ports  []portResponse          `json:"ports"`
 	Vulns  []vulnerabilityResponse `json:"vulns"`
 	Events []vulnEventResponse     `json:"events"`
+	DNS    []dnsRecordResponse     `json:"dns"`
+	Web    []webResponseResponse   `json:"webResponses"`
+	Certs  []certificateResponse   `json:"certificates"`
 }
 
 func (s *Server) handleAssetDetail(w http.ResponseWriter, r *http.Request) {
@@ -6680,9 +7277,13 @@ func (s *Server) handleAssetDetail(w http.ResponseWriter, r *http.Request) {
 		ID: int(asset.ID), Domain: asset.Domain, URL: asset.URL, IP: asset.IP,
 		StatusCode: asset.StatusCode, Title: asset.Title,
 		Technologies: decodeJSONBStrings(asset.Technologies),
+		DiscoveryHop: asset.DiscoveryHop,
 		CreatedAt:    timeToISO(asset.CreatedAt), UpdatedAt: timeToISO(asset.UpdatedAt),
 		LastSeen: timeToISO(asset.LastSeen),
 	}
+	detail := []assetResponse{ar}
+	s.attachAssetSources(projectID, detail)
+	ar = detail[0]
 
 	var ports []db.Port
 	s.db.DB.Where("asset_id = ? AND project_id = ?", asset.ID, projectID).Order("port asc").Limit(500).Find(&ports)
@@ -6694,7 +7295,7 @@ func (s *Server) handleAssetDetail(w http.ResponseWriter, r *http.Request) {
 		pr = append(pr, portResponse{
 			ID: int(p.ID), AssetID: int(p.AssetID), Domain: p.Domain, IP: p.IP,
 			Port: p.Port, Protocol: p.Protocol, Service: p.Service, Version: p.Version,
-			Banner: p.Banner, LastSeen: timeToISO(p.LastSeen), UpdatedAt: timeToISO(p.UpdatedAt),
+			Banner: p.Banner, LastSeen: timeToISO(p.LastSeen), UpdatedAt: timeToISO(p.UpdatedAt), DiscoveryHop: p.DiscoveryHop,
 		})
 	}
 
@@ -6711,11 +7312,21 @@ func (s *Server) handleAssetDetail(w http.ResponseWriter, r *http.Request) {
 			URL: v.URL, IP: v.IP, TemplateID: v.TemplateID, TemplateName: v.TemplateName,
 			Severity: v.Severity, CVE: v.CVE, Description: v.Description,
 			MatchedAt: matchedAt, Fingerprint: v.Fingerprint, Status: v.Status,
-			LastSeen: timeToISO(v.LastSeen),
+			LastSeen: timeToISO(v.LastSeen), DiscoveryHop: v.DiscoveryHop,
 		})
 	}
 
-	writeJSON(w, http.StatusOK, assetDetailResponse{Asset: ar, Ports: pr, Vulns: vr})
+	dnsRows, _ := s.db.ListDNSRecords(projectID, asset.Domain, "", "", 200)
+	webRows, _ := s.db.ListWebResponses(projectID, asset.ID)
+	web := toWebResponseResponses(webRows)
+	s.labelWebResponses(web)
+	certRows, _ := s.db.ListAssetCertificates(projectID, asset.ID, asset.Domain)
+
+	writeJSON(w, http.StatusOK, assetDetailResponse{
+		Asset: ar, Ports: pr, Vulns: vr,
+		DNS: toDNSRecordResponses(dnsRows), Web: web,
+		Certs: toCertificateResponses(certRows),
+	})
 }
 
 // 闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾闁冲厜鍋撻柍鍏夊亾
@@ -6794,6 +7405,15 @@ func (s *Server) handleBulkDeleteAssets(w http.ResponseWriter, r *http.Request)
 				return r.Error
 			}
 			deletedDeps["ports"] += r.RowsAffected
+			if err := tx.Where("project_id = ? AND asset_id IN ?", body.ProjectID, assetIDs).Delete(&db.WebResponse{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(assetIDs) > 0 || len(domains) > 0 {
+			if err := tx.Where("project_id = ? AND (asset_id IN ? OR host IN ?)", body.ProjectID, assetIDs, domains).
+				Delete(&db.Certificate{}).Error; err != nil {
+				return err
+			}
 		}
 		if len(domains) > 0 || len(ips) > 0 {
 			var r *gorm.DB
@@ -7037,8 +7657,8 @@ func (s *Server) handleGlobalSearch(w http.ResponseWriter, r *http.Request) {
 	resp := globalSearchResponse{}
 
 	var assets []db.Asset
-	s.db.DB.Where("project_id = ? AND (LOWER(domain) LIKE ? OR LOWER(url) LIKE ? OR LOWER(ip) LIKE ? OR LOWER(title) LIKE ?)",
-		projectID, pattern, pattern, pattern, pattern).Order("last_seen desc").Limit(limit).Find(&assets)
+	s.db.DB.Where("project_id = ? AND (LOWER(domain) LIKE ? OR LOWER(url) LIKE ? OR LOWER(ip) LIKE ? OR LOWER(title) LIKE ? OR EXISTS (?))",
+		projectID, pattern, pattern, pattern, pattern, s.webResponseSearchMatch(projectID, q, pattern)).Order("last_seen desc").Limit(limit).Find(&assets)
 	resp.Assets = make([]assetResponse, 0, len(assets))
 	for _, a := range assets {
 		resp.Assets = append(resp.Assets, assetResponse{
@@ -7057,7 +7677,7 @@ func (s *Server) handleGlobalSearch(w http.ResponseWriter, r *http.Request) {
 		resp.Ports = append(resp.Ports, portResponse{
 			ID: int(p.ID), Domain: p.Domain, IP: p.IP, Port: p.Port,
 			Protocol: p.Protocol, Service: p.Service, Version: p.Version,
-			LastSeen: timeToISO(p.LastSeen),
+			LastSeen: timeToISO(p.LastSeen), DiscoveryHop: p.DiscoveryHop,
 		})
 	}
 
@@ -7074,13 +7694,15 @@ func (s *Server) handleGlobalSearch(w http.ResponseWriter, r *http.Request) {
 			ID: int(v.ID), Domain: v.Domain, Host: v.Host, URL: v.URL,
 			TemplateID: v.TemplateID, TemplateName: v.TemplateName,
 			Severity: v.Severity, CVE: v.CVE, Status: v.Status,
-			MatchedAt: matchedAt, LastSeen: timeToISO(v.LastSeen),
+			MatchedAt: matchedAt, LastSeen: timeToISO(v.LastSeen), DiscoveryHop: v.DiscoveryHop,
 		})
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// isDomainInProjectScope reports whether domain is covered by the project's
+// root domains and scope rules. Archived projects have no scope.
 func (s *Server) isDomainInProjectScope(projectID, domain string) (bool, error) {
 	domain = normalizeRootDomain(domain)
 	if projectID == "" || domain == "" {
@@ -7096,23 +7718,69 @@ func (s *Server) isDomainInProjectScope(projectID, domain string) (bool, error)
 	if project.Archived {
 		return false, nil
 	}
-	var scopes []db.ProjectScope
-	if err := s.db.DB.Where("project_id = ? AND enabled = ?", projectID, true).Find(&scopes).Error; err != nil {
+	var scopeCount int64
+	if err := s.db.DB.Model(&db.ProjectScope{}).Where("project_id = ? AND enabled = ?", projectID, true).Count(&scopeCount).Error; err != nil {
 		return false, err
 	}
-	if len(scopes) == 0 {
+	if scopeCount == 0 {
 		return false, nil
 	}
-	for _, sc := range scopes {
-		rd := normalizeRootDomain(sc.RootDomain)
-		if rd == "" {
-			continue
+	scope, err := s.loadProjectScope(projectID)
+	if err != nil {
+		return false, err
+	}
+	return scope.Allows(domain), nil
+}
+
+func clampRecurseDepth(depth int) int {
+	if depth < 0 {
+		return 0
+	}
+	if depth > maxScanRecurseDepth {
+		return maxScanRecurseDepth
+	}
+	return depth
+}
+
+func sanitizeHostBudget(budget engine.HostBudget) engine.HostBudget {
+	if budget.RequestsPerSecond < 0 {
+		budget.RequestsPerSecond = 0
+	}
+	if budget.MaxConcurrent < 0 {
+		budget.MaxConcurrent = 0
+	}
+	return budget
+}
+
+// projectHostBudget returns the per-host load budget for projectID, falling
+// back to the HOST_RATE_LIMIT_RPS / HOST_MAX_CONCURRENCY defaults for unset
+// values.
+func (s *Server) projectHostBudget(projectID string) engine.HostBudget {
+	budget := engine.HostBudget{MaxConcurrent: envIntOrDefault("HOST_MAX_CONCURRENCY", 0)}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("HOST_RATE_LIMIT_RPS")), 64); err == nil {
+		budget.RequestsPerSecond = v
+	}
+	var project db.Project
+	if err := s.db.DB.Select("id", "host_rate_limit_rps", "host_max_concurrency").Where("id = ?", strings.TrimSpace(projectID)).First(&project).Error; err == nil {
+		if project.HostRateLimitRPS > 0 {
+			budget.RequestsPerSecond = project.HostRateLimitRPS
 		}
-		if domain == rd || strings.HasSuffix(domain, "."+rd) {
-			return true, nil
+		if project.HostMaxConcurrency > 0 {
+			budget.MaxConcurrent = project.HostMaxConcurrency
 		}
 	}
-	return false, nil
+	return sanitizeHostBudget(budget)
+}
+
+// withHostLimiter attaches the project's host budget to ctx so every scanner
+// of one job or monitor run shares it.
+func (s *Server) withHostLimiter(ctx context.Context, projectID, jobID string) context.Context {
+	budget := s.projectHostBudget(projectID)
+	if !budget.Enabled() {
+		return ctx
+	}
+	s.appendJobLogf(projectID, jobID, "info", "Host budget: rps=%.2f concurrency=%d", budget.RequestsPerSecond, budget.MaxConcurrent)
+	return engine.WithHostLimiter(ctx, engine.NewHostLimiter(budget))
 }
 
 func (s *Server) isProjectAIEnabled(projectID string) (bool, error) {
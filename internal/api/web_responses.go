@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"hunter/internal/db"
+)
+
+type webResponseResponse struct {
+	URL            string            `json:"url"`
+	FinalURL       string            `json:"finalUrl"`
+	Location       string            `json:"location"`
+	StatusCode     int               `json:"statusCode"`
+	ContentType    string            `json:"contentType"`
+	ContentLength  int               `json:"contentLength"`
+	BodySHA256     string            `json:"bodySha256"`
+	Server         string            `json:"server"`
+	CDN            string            `json:"cdn"`
+	Headers        map[string]string `json:"headers"`
+	ResponseTime   int               `json:"responseTimeMs"`
+	FaviconMMH3    string            `json:"faviconMmh3"`
+	FaviconMD5     string            `json:"faviconMd5"`
+	FaviconURL     string            `json:"faviconUrl"`
+	FaviconProduct string            `json:"faviconProduct,omitempty"` // label from FAVICON_HASH_DB
+	FirstSeenAt    string            `json:"firstSeenAt"`
+	LastSeen       string            `json:"lastSeen"`
+}
+
+func toWebResponseResponses(rows []db.WebResponse) []webResponseResponse {
+	out := make([]webResponseResponse, 0, len(rows))
+	for _, row := range rows {
+		headers := map[string]string{}
+		if len(row.Headers) > 0 {
+			_ = json.Unmarshal(row.Headers, &headers)
+		}
+		out = append(out, webResponseResponse{
+			URL:           row.URL,
+			FinalURL:      row.FinalURL,
+			Location:      row.Location,
+			StatusCode:    row.StatusCode,
+			ContentType:   row.ContentType,
+			ContentLength: row.ContentLength,
+			BodySHA256:    row.BodySHA256,
+			Server:        row.Server,
+			CDN:           row.CDN,
+			Headers:       headers,
+			ResponseTime:  row.ResponseTime,
+			FaviconMMH3:   row.FaviconMMH3,
+			FaviconMD5:    row.FaviconMD5,
+			FaviconURL:    row.FaviconURL,
+			FirstSeenAt:   timeToISO(row.FirstSeenAt),
+			LastSeen:      timeToISO(row.LastSeen),
+		})
+	}
+	return out
+}
+
+// webResponseFilter narrows the asset list to assets with at least one
+// stored response matching every non-empty field.
+type webResponseFilter struct {
+	Server      string // substring of the Server header
+	CDN         string // CDN/WAF name, exact
+	ContentType string // substring of Content-Type
+	BodyHash    string // body SHA-256, exact
+	Favicon     string // favicon mmh3 hash or MD5, exact
+	HeaderName  string // header present (case-insensitive)
+	HeaderValue string // substring of HeaderName's value
+}
+
+// parseWebResponseFilter reads server, cdn, content_type, body_hash,
+// favicon and header ("Name" or "Name:value") from the query string.
+func parseWebResponseFilter(q url.Values) webResponseFilter {
+	f := webResponseFilter{
+		Server:      strings.ToLower(strings.TrimSpace(q.Get("server"))),
+		CDN:         strings.ToLower(strings.TrimSpace(q.Get("cdn"))),
+		ContentType: strings.ToLower(strings.TrimSpace(q.Get("content_type"))),
+		BodyHash:    strings.ToLower(strings.TrimSpace(q.Get("body_hash"))),
+		Favicon:     strings.ToLower(strings.TrimSpace(q.Get("favicon"))),
+	}
+	if header := strings.TrimSpace(q.Get("header")); header != "" {
+		name, value, _ := strings.Cut(header, ":")
+		f.HeaderName = strings.ToLower(strings.TrimSpace(name))
+		f.HeaderValue = strings.ToLower(strings.TrimSpace(value))
+	}
+	return f
+}
+
+func (f webResponseFilter) empty() bool {
+	return f == webResponseFilter{}
+}
+
+func (s *Server) applyWebResponseFilter(base *gorm.DB, projectID string, f webResponseFilter) *gorm.DB {
+	if f.empty() {
+		return base
+	}
+	match := s.db.DB.Model(&db.WebResponse{}).
+		Select("1").
+		Where("web_responses.project_id = ? AND web_responses.asset_id = assets.id", projectID)
+	if f.Server != "" {
+		match = match.Where("LOWER(web_responses.server) LIKE ?", "%"+f.Server+"%")
+	}
+	if f.CDN != "" {
+		match = match.Where("web_responses.cdn = ?", f.CDN)
+	}
+	if f.ContentType != "" {
+		match = match.Where("LOWER(web_responses.content_type) LIKE ?", "%"+f.ContentType+"%")
+	}
+	if f.BodyHash != "" {
+		match = match.Where("web_responses.body_sha256 = ?", f.BodyHash)
+	}
+	if f.Favicon != "" {
+		match = match.Where("web_responses.favicon_mmh3 = ? OR web_responses.favicon_md5 = ?", f.Favicon, f.Favicon)
+	}
+	if f.HeaderName != "" {
+		match = match.Where(
+			"EXISTS (SELECT 1 FROM jsonb_each_text(web_responses.headers) h WHERE LOWER(h.key) = ? AND LOWER(h.value) LIKE ?)",
+			f.HeaderName, "%"+f.HeaderValue+"%",
+		)
+	}
+	return base.Where("EXISTS (?)", match)
+}
+
+// webResponseSearchMatch matches assets whose stored responses mention
+// pattern in the server, CDN, content type or final URL, or whose body or
+// favicon hash equals q.
+func (s *Server) webResponseSearchMatch(projectID, q, pattern string) *gorm.DB {
+	return s.db.DB.Model(&db.WebResponse{}).
+		Select("1").
+		Where("web_responses.project_id = ? AND web_responses.asset_id = assets.id", projectID).
+		Where("LOWER(web_responses.server) LIKE ? OR web_responses.cdn LIKE ? OR LOWER(web_responses.content_type) LIKE ? OR LOWER(web_responses.final_url) LIKE ? OR web_responses.body_sha256 = ? OR web_responses.favicon_mmh3 = ?",
+			pattern, pattern, pattern, pattern, q, q)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"hunter/internal/db"
+	"hunter/internal/resolver"
+)
+
+const wildcardFilterConcurrency = 50
+
+type wildcardZoneResponse struct {
+	ID          int      `json:"id"`
+	ProjectID   string   `json:"projectId"`
+	RootDomain  string   `json:"rootDomain"`
+	Zone        string   `json:"zone"`
+	IPs         []string `json:"ips"`
+	CNAME       string   `json:"cname,omitempty"`
+	JobID       string   `json:"jobId"`
+	DroppedHits int      `json:"droppedHits"`
+	FirstSeenAt string   `json:"firstSeenAt"`
+	LastSeenAt  string   `json:"lastSeenAt"`
+}
+
+// isProjectWildcardFilterEnabled reports the project's wildcard filter
+// toggle. Unknown projects use the default (enabled).
+func (s *Server) isProjectWildcardFilterEnabled(projectID string) bool {
+	var project db.Project
+	if err := s.db.DB.Select("id", "wildcard_filter").Where("id = ?", projectID).First(&project).Error; err != nil {
+		return true
+	}
+	return project.WildcardFilter
+}
+
+// filterWildcardSubdomains drops subdomains that only resolve because of a
+// wildcard record on their parent zone. Detected zones are stored on the
+// project. Hits may already have been streamed to the candidate pool, so
+// their pending candidates are deleted. On any resolver error the input is
+// returned unchanged.
+func (s *Server) filterWildcardSubdomains(ctx context.Context, projectID, jobID, rootDomain string, subdomains []string, dnsResolvers string) (kept, dropped []string) {
+	if len(subdomains) == 0 || !s.isProjectWildcardFilterEnabled(projectID) {
+		return subdomains, nil
+	}
+	kept, dropped, zones, err := resolver.FilterWildcards(ctx, dnsResolvers, subdomains, wildcardFilterConcurrency)
+	if err != nil {
+		s.appendJobLogf(projectID, jobID, "warn", "Wildcard filter skipped: %v", err)
+		return subdomains, nil
+	}
+	if len(zones) == 0 {
+		return subdomains, nil
+	}
+
+	hits := make(map[string]int, len(zones))
+	for _, host := range dropped {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if idx := strings.Index(host, "."); idx >= 0 {
+			hits[host[idx+1:]]++
+		}
+	}
+	for _, zone := range zones {
+		ipsJSON, _ := json.Marshal(zone.IPs)
+		record := db.WildcardZone{
+			ProjectID:   projectID,
+			RootDomain:  rootDomain,
+			Zone:        zone.Zone,
+			IPs:         ipsJSON,
+			CNAME:       zone.CNAME,
+			JobID:       jobID,
+			DroppedHits: hits[zone.Zone],
+		}
+		if err := s.db.UpsertWildcardZone(&record); err != nil {
+			log.Printf("[Wildcard] save zone %s failed: %v", zone.Zone, err)
+		}
+		s.appendJobLogf(projectID, jobID, "info", "Wildcard zone *.%s -> ips=%s cname=%s dropped=%d",
+			zone.Zone, strings.Join(zone.IPs, ","), zone.CNAME, hits[zone.Zone])
+	}
+	if deleted, err := s.db.DeleteWildcardCandidates(projectID, jobID, dropped); err != nil {
+		log.Printf("[Wildcard] delete candidates failed: %v", err)
+	} else if deleted > 0 {
+		log.Printf("[Wildcard] project=%s job=%s deleted %d wildcard candidates", projectID, jobID, deleted)
+	}
+	s.appendJobLogf(projectID, jobID, "info", "Wildcard filter done: zones=%d dropped=%d kept=%d", len(zones), len(dropped), len(kept))
+	return kept, dropped
+}
+
+func (s *Server) handleWildcardZones(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+	zones, err := s.db.ListWildcardZones(projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := make([]wildcardZoneResponse, 0, len(zones))
+	for _, zone := range zones {
+		resp = append(resp, wildcardZoneResponse{
+			ID:          int(zone.ID),
+			ProjectID:   zone.ProjectID,
+			RootDomain:  zone.RootDomain,
+			Zone:        zone.Zone,
+			IPs:         decodeJSONBStrings(zone.IPs),
+			CNAME:       zone.CNAME,
+			JobID:       zone.JobID,
+			DroppedHits: zone.DroppedHits,
+			FirstSeenAt: timeToISO(zone.FirstSeenAt),
+			LastSeenAt:  timeToISO(zone.LastSeenAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonpkg "hunter/internal/common"
+	"hunter/internal/db"
+	"hunter/internal/resolver"
+)
+
+const (
+	// bruteforceWordlistLoadLimit caps the learned words read per run.
+	bruteforceWordlistLoadLimit = 20000
+	// bruteforceWordDeadMisses is the number of misses after which a word
+	// that never resolved is left out of generated wordlists.
+	bruteforceWordDeadMisses = 10
+	// maxWordlistUploadBytes and maxWordlistUploadWords cap manual uploads.
+	maxWordlistUploadBytes = 4 << 20
+	maxWordlistUploadWords = 100000
+)
+
+type wordlistWordResponse struct {
+	Word      string  `json:"word"`
+	Source    string  `json:"source"`
+	Hits      int     `json:"hits"`
+	Misses    int     `json:"misses"`
+	HitRate   float64 `json:"hitRate"`
+	LastHitAt string  `json:"lastHitAt"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+type wordlistUploadRequest struct {
+	ProjectID string   `json:"projectId"`
+	Words     []string `json:"words"`
+	Text      string   `json:"text"`
+}
+
+type wordlistPruneRequest struct {
+	ProjectID     string  `json:"projectId"`
+	MinTries      int     `json:"minTries"`
+	MaxHitRate    float64 `json:"maxHitRate"`
+	IncludeManual bool    `json:"includeManual"`
+}
+
+// rankBruteforceWords merges the learned wordlist of a project with the
+// generated candidates and orders them by smoothed hit rate. Untried
+// candidates keep their generated order; words that only ever missed are
+// dropped.
+func rankBruteforceWords(learned []db.BruteforceWord, candidates []string, maxWords int) []string {
+	type rankedWord struct {
+		word  string
+		score float64
+	}
+	stats := make(map[string]db.BruteforceWord, len(learned))
+	ranked := make([]rankedWord, 0, len(learned)+len(candidates))
+	seen := make(map[string]bool, len(learned)+len(candidates))
+	add := func(word string) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || seen[word] {
+			return
+		}
+		seen[word] = true
+		score := 0.5
+		if stat, ok := stats[word]; ok {
+			if stat.Hits == 0 && stat.Misses >= bruteforceWordDeadMisses {
+				return
+			}
+			score = float64(stat.Hits+1) / float64(stat.Hits+stat.Misses+2)
+		}
+		ranked = append(ranked, rankedWord{word: word, score: score})
+	}
+	for _, row := range learned {
+		stats[row.Word] = row
+	}
+	for _, word := range candidates {
+		add(word)
+	}
+	for _, row := range learned {
+		add(row.Word)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	if maxWords > 0 && len(ranked) > maxWords {
+		ranked = ranked[:maxWords]
+	}
+	out := make([]string, 0, len(ranked))
+	for _, r := range ranked {
+		out = append(out, r.word)
+	}
+	return out
+}
+
+// applyLearnedWordlist ranks candidates against the project's learned
+// wordlist. The candidates are returned unchanged when it cannot be loaded.
+func (s *Server) applyLearnedWordlist(projectID string, candidates []string, maxWords int) []string {
+	if strings.TrimSpace(projectID) == "" || s.db == nil {
+		return candidates
+	}
+	learned, err := s.db.ListBruteforceWords(projectID, "", bruteforceWordlistLoadLimit)
+	if err != nil {
+		log.Printf("[Wordlist] load learned words failed project=%s: %v", projectID, err)
+		return candidates
+	}
+	if len(learned) == 0 {
+		return candidates
+	}
+	return rankBruteforceWords(learned, candidates, maxWords)
+}
+
+// learnBruteforceWords records, for every word tried against rootDomains,
+// how many of <word>.<root> resolved. Wildcard hits are not real names, so
+// resolved is run through the wildcard filter first and roots that are
+// wildcard zones themselves are left out; if the filter fails nothing is
+// learned.
+func (s *Server) learnBruteforceWords(ctx context.Context, projectID string, rootDomains, words, resolved []string, dnsResolvers string) {
+	if strings.TrimSpace(projectID) == "" || len(words) == 0 || len(rootDomains) == 0 {
+		return
+	}
+	if len(resolved) > 0 {
+		kept, _, zones, err := resolver.FilterWildcards(ctx, dnsResolvers, resolved, wildcardFilterConcurrency)
+		if err != nil {
+			log.Printf("[Wordlist] skip learning project=%s: wildcard filter failed: %v", projectID, err)
+			return
+		}
+		wildcard := make(map[string]bool, len(zones))
+		for _, zone := range zones {
+			wildcard[zone.Zone] = true
+		}
+		roots := make([]string, 0, len(rootDomains))
+		for _, root := range rootDomains {
+			if !wildcard[strings.ToLower(root)] {
+				roots = append(roots, root)
+			}
+		}
+		rootDomains, resolved = roots, kept
+		if len(rootDomains) == 0 {
+			return
+		}
+	}
+	found := make(map[string]bool, len(resolved))
+	for _, domain := range resolved {
+		found[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	stats := make([]db.BruteforceWordStat, 0, len(words))
+	seen := make(map[string]bool, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		stat := db.BruteforceWordStat{Word: word}
+		for _, root := range rootDomains {
+			if found[word+"."+strings.ToLower(root)] {
+				stat.Hits++
+			} else {
+				stat.Misses++
+			}
+		}
+		stats = append(stats, stat)
+	}
+	if err := s.db.RecordBruteforceWordStats(projectID, stats); err != nil {
+		log.Printf("[Wordlist] record word stats failed project=%s: %v", projectID, err)
+	}
+}
+
+func toWordlistWordResponse(row db.BruteforceWord) wordlistWordResponse {
+	resp := wordlistWordResponse{
+		Word:      row.Word,
+		Source:    row.Source,
+		Hits:      row.Hits,
+		Misses:    row.Misses,
+		UpdatedAt: timeToISO(row.UpdatedAt),
+	}
+	if tries := row.Hits + row.Misses; tries > 0 {
+		resp.HitRate = float64(row.Hits) / float64(tries)
+	}
+	if row.LastHitAt != nil {
+		resp.LastHitAt = timeToISO(*row.LastHitAt)
+	}
+	return resp
+}
+
+// handleProjectWordlist serves the learned bruteforce wordlist of a project.
+// GET lists it ranked by hit rate (format=txt exports plain words), POST
+// uploads manual words and DELETE removes the given words.
+func (s *Server) handleProjectWordlist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+		if projectID == "" {
+			writeError(w, http.StatusBadRequest, "project_id is required")
+			return
+		}
+		limit := maxListRows
+		if v, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("limit"))); err == nil && v > 0 && v < limit {
+			limit = v
+		}
+		format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+		if format == "txt" {
+			limit = bruteforceWordlistLoadLimit
+		}
+		rows, err := s.db.ListBruteforceWords(projectID, r.URL.Query().Get("source"), limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if format == "txt" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="wordlist.txt"`)
+			w.WriteHeader(http.StatusOK)
+			for _, row := range rows {
+				_, _ = io.WriteString(w, row.Word+"\n")
+			}
+			return
+		}
+		resp := make([]wordlistWordResponse, 0, len(rows))
+		for _, row := range rows {
+			resp = append(resp, toWordlistWordResponse(row))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodPost:
+		var req wordlistUploadRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxWordlistUploadBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		projectID := strings.TrimSpace(req.ProjectID)
+		if projectID == "" {
+			writeError(w, http.StatusBadRequest, "projectId is required")
+			return
+		}
+		words := commonpkg.NormalizeBruteforceWords(append(req.Words, req.Text), maxWordlistUploadWords)
+		if len(words) == 0 {
+			writeError(w, http.StatusBadRequest, "no valid words")
+			return
+		}
+		added, err := s.db.AddBruteforceWords(projectID, words)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit(projectID, actorFromRequest(r), "wordlist_upload", "wordlist", projectID, map[string]interface{}{"words": added}, r)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "added": added})
+	case http.MethodDelete:
+		projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+		words := commonpkg.NormalizeBruteforceWords([]string{r.URL.Query().Get("words")}, maxWordlistUploadWords)
+		if projectID == "" || len(words) == 0 {
+			writeError(w, http.StatusBadRequest, "project_id and words are required")
+			return
+		}
+		removed, err := s.db.DeleteBruteforceWords(projectID, words)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.writeAudit(projectID, actorFromRequest(r), "wordlist_delete", "wordlist", projectID, map[string]interface{}{"words": words}, r)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "removed": removed})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleProjectWordlistPrune removes words tried at least minTries times
+// (default bruteforceWordDeadMisses) with a hit rate at or below maxHitRate.
+func (s *Server) handleProjectWordlistPrune(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req wordlistPruneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	projectID := strings.TrimSpace(req.ProjectID)
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "projectId is required")
+		return
+	}
+	if req.MaxHitRate < 0 || req.MaxHitRate > 1 {
+		writeError(w, http.StatusBadRequest, "maxHitRate must be between 0 and 1")
+		return
+	}
+	if req.MinTries <= 0 {
+		req.MinTries = bruteforceWordDeadMisses
+	}
+	removed, err := s.db.PruneBruteforceWords(projectID, req.MinTries, req.MaxHitRate, req.IncludeManual)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeAudit(projectID, actorFromRequest(r), "wordlist_prune", "wordlist", projectID, map[string]interface{}{
+		"minTries": req.MinTries, "maxHitRate": req.MaxHitRate, "removed": removed,
+	}, r)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "removed": removed})
+}
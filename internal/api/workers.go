@@ -0,0 +1,266 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"hunter/internal/db"
+	"hunter/internal/pipelines"
+	"hunter/internal/plugins"
+)
+
+const (
+	workerHeartbeatInterval = 15 * time.Second
+	scanJobLeaseTTL         = 90 * time.Second
+	workerOfflineAfter      = 60 * time.Second
+)
+
+type workerResponse struct {
+	WorkerID        string   `json:"workerId"`
+	Hostname        string   `json:"hostname"`
+	Region          string   `json:"region,omitempty"`
+	EgressIP        string   `json:"egressIp,omitempty"`
+	LocalIP         string   `json:"localIp,omitempty"`
+	Capabilities    []string `json:"capabilities"`
+	Tags            []string `json:"tags"`
+	CurrentJobID    string   `json:"currentJobId,omitempty"`
+	Health          string   `json:"health"`
+	StartedAt       string   `json:"startedAt"`
+	LastHeartbeatAt string   `json:"lastHeartbeatAt"`
+}
+
+// newLocalWorker describes this process for the worker registry. Identity
+// comes from WORKER_ID, WORKER_REGION, WORKER_TAGS and WORKER_EGRESS_IP;
+// capabilities are the plugins whose binaries are available locally.
+func newLocalWorker() *db.Worker {
+	hostname, _ := os.Hostname()
+	workerID := strings.TrimSpace(envOrDefault("WORKER_ID", fmt.Sprintf("%s-%d", hostname, os.Getpid())))
+	region := strings.ToLower(strings.TrimSpace(os.Getenv("WORKER_REGION")))
+
+	tags := normalizeWorkerTags(strings.Split(os.Getenv("WORKER_TAGS"), ","))
+	if region != "" {
+		tags = normalizeWorkerTags(append(tags, "region:"+region))
+	}
+	capabilities := plugins.AvailablePlugins()
+
+	rawCapabilities, _ := json.Marshal(capabilities)
+	rawTags, _ := json.Marshal(tags)
+	return &db.Worker{
+		WorkerID:     workerID,
+		Hostname:     hostname,
+		Region:       region,
+		EgressIP:     strings.TrimSpace(os.Getenv("WORKER_EGRESS_IP")),
+		LocalIP:      detectLocalIP(),
+		Capabilities: db.JSONB(rawCapabilities),
+		Tags:         db.JSONB(rawTags),
+		StartedAt:    time.Now(),
+	}
+}
+
+// detectLocalIP returns the local address the default route would use. Behind
+// NAT this is not the public egress IP, which only WORKER_EGRESS_IP sets. No
+// packet is sent.
+func detectLocalIP() string {
+	conn, err := net.Dial("udp", "8.8.8.8:53")
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return addr.IP.String()
+	}
+	return ""
+}
+
+func normalizeWorkerTags(raw []string) []string {
+	seen := make(map[string]bool, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		out = append(out, item)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// jobRequiredPlugins returns the canonical plugin names a job runs, used to
+// route it to a worker that has them installed.
+func jobRequiredPlugins(modules []string, pipelineName string) []string {
+	names := stageRequiredPlugins(modules)
+	if pipelineName != "" {
+		if def, err := pipelines.Get(pipelineName); err == nil {
+			names = def.ScannerNames()
+		}
+	}
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		info, ok := plugins.LookupPlugin(name)
+		if !ok || info.External || seen[info.Name] {
+			continue
+		}
+		seen[info.Name] = true
+		out = append(out, info.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// stageRequiredPlugins maps the fixed stages selected by modules to the
+// plugins they cannot run without. Passive sources are optional, since a
+// missing tool is skipped, and HTTP probing and DNS brute force only need
+// their external tool when the engine is pinned to it.
+func stageRequiredPlugins(modules []string) []string {
+	st := resolveScanStages(modules, false, false)
+	var out []string
+	if st.BbotActive {
+		out = append(out, "bbot_active")
+	}
+	if st.ActiveSubs {
+		if name := plugins.PinnedActiveBruteforceEngine(); name != "" {
+			out = append(out, name)
+		}
+	}
+	if st.Httpx {
+		if name := plugins.PinnedHTTPProbeEngine(); name != "" {
+			out = append(out, name)
+		}
+	}
+	if st.Ports {
+		out = append(out, portScanTools(configuredPortScannerEngine())...)
+	}
+	if st.Nuclei {
+		out = append(out, "nuclei")
+	}
+	if st.SubTakeover {
+		out = append(out, "subtakeover")
+	}
+	if st.Witness {
+		out = append(out, "gowitness")
+	}
+	return out
+}
+
+func (s *Server) setCurrentScanJob(jobID string) {
+	s.workerMu.Lock()
+	s.currentScanJobID = jobID
+	s.workerMu.Unlock()
+}
+
+func (s *Server) currentScanJob() string {
+	s.workerMu.Lock()
+	defer s.workerMu.Unlock()
+	return s.currentScanJobID
+}
+
+// runWorkerHeartbeat keeps this worker's registry row and job leases fresh
+// and fails jobs whose worker stopped heartbeating.
+func (s *Server) runWorkerHeartbeat() {
+	ticker := time.NewTicker(workerHeartbeatInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		jobID := s.currentScanJob()
+		err := s.db.HeartbeatWorker(s.worker.WorkerID, jobID, scanJobLeaseTTL)
+		switch {
+		case errors.Is(err, db.ErrLeaseLost):
+			if s.abandonScanJob(jobID) {
+				log.Printf("[Worker] lost lease on scan job %s, canceling it", jobID)
+			}
+		case err != nil:
+			log.Printf("[Worker] heartbeat failed: %v", err)
+		}
+		s.recoverExpiredScanJobLeases()
+	}
+}
+
+// abandonScanJob cancels the running scan jobID after its lease was lost and
+// reports whether it was running here.
+func (s *Server) abandonScanJob(jobID string) bool {
+	s.scanCancelMu.Lock()
+	defer s.scanCancelMu.Unlock()
+	cancel, ok := s.scanCancels[jobID]
+	if !ok {
+		return false
+	}
+	s.lostScanLeases[jobID] = true
+	cancel()
+	return true
+}
+
+func (s *Server) scanLeaseLost(jobID string) bool {
+	s.scanCancelMu.Lock()
+	defer s.scanCancelMu.Unlock()
+	return s.lostScanLeases[jobID]
+}
+
+func (s *Server) recoverExpiredScanJobLeases() {
+	recovered, err := s.db.RecoverExpiredScanJobLeases()
+	if err != nil {
+		log.Printf("[Worker] failed to recover expired scan job leases: %v", err)
+	}
+	if len(recovered) == 0 {
+		return
+	}
+	log.Printf("[Worker] recovered %d scan jobs with expired leases", len(recovered))
+	for _, item := range recovered {
+		s.appendJobLog(item.ProjectID, item.JobID, "warn", item.ErrorMessage)
+		if stage := strings.TrimSpace(item.LastStage); stage != "" {
+			s.appendJobLogf(item.ProjectID, item.JobID, "warn",
+				"lease expired in stage: stage=%s status=%s stage_updated_at=%s",
+				stage, item.LastStageStatus, timePtrToISO(item.LastStageUpdatedAt),
+			)
+		}
+		s.autoResumeRecoveredScanJob(item)
+	}
+}
+
+func (s *Server) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	workers, err := s.db.ListWorkers()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now()
+	resp := make([]workerResponse, 0, len(workers))
+	for _, item := range workers {
+		health := "online"
+		if now.Sub(item.LastHeartbeatAt) > workerOfflineAfter {
+			health = "offline"
+		}
+		capabilities := []string{}
+		_ = json.Unmarshal(item.Capabilities, &capabilities)
+		tags := []string{}
+		_ = json.Unmarshal(item.Tags, &tags)
+		resp = append(resp, workerResponse{
+			WorkerID:        item.WorkerID,
+			Hostname:        item.Hostname,
+			Region:          item.Region,
+			EgressIP:        item.EgressIP,
+			LocalIP:         item.LocalIP,
+			Capabilities:    capabilities,
+			Tags:            tags,
+			CurrentJobID:    item.CurrentJobID,
+			Health:          health,
+			StartedAt:       timeToISO(item.StartedAt),
+			LastHeartbeatAt: timeToISO(item.LastHeartbeatAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
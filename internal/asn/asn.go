@@ -0,0 +1,171 @@
+// Package asn maps IP addresses to autonomous systems using an offline
+// dataset loaded from disk. Supported formats are the ip2asn TSV files
+// (optionally gzipped), the MaxMind GeoLite2-ASN CSV and MaxMind DB (.mmdb)
+// files carrying ASN data.
+package asn
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Record is one address range announced by an autonomous system.
+type Record struct {
+	Start   netip.Addr
+	End     netip.Addr
+	ASN     uint32
+	Org     string
+	Country string
+}
+
+// Contains reports whether ip is inside the range.
+func (r Record) Contains(ip netip.Addr) bool {
+	return r.Start.Compare(ip) <= 0 && ip.Compare(r.End) <= 0
+}
+
+// Prefixes returns the CIDR prefixes covering the range exactly.
+func (r Record) Prefixes() []netip.Prefix {
+	return RangePrefixes(r.Start, r.End)
+}
+
+// DB is an in-memory ASN dataset.
+type DB struct {
+	records []Record // sorted by Start, IPv4 before IPv6
+	byASN   map[uint32][]int
+}
+
+// Open loads the dataset at path. The format is chosen by file extension:
+// .mmdb, .csv or anything else as ip2asn TSV; a .gz suffix is decompressed.
+func Open(path string) (*DB, error) {
+	var (
+		records []Record
+		err     error
+	)
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	switch {
+	case strings.HasSuffix(name, ".mmdb"):
+		records, err = readFile(path, readMMDB)
+	case strings.HasSuffix(name, ".csv"):
+		records, err = readFile(path, parseCSV)
+	default:
+		records, err = readFile(path, parseTSV)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load asn dataset %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("load asn dataset %s: no records", path)
+	}
+	return newDB(records), nil
+}
+
+func newDB(records []Record) *DB {
+	sort.Slice(records, func(i, j int) bool { return records[i].Start.Less(records[j].Start) })
+	// Adjacent ranges of the same AS are merged so prefixes come out as
+	// large as the dataset allows.
+	merged := records[:0]
+	for _, rec := range records {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.ASN == rec.ASN && last.Org == rec.Org && last.End.Next() == rec.Start {
+				last.End = rec.End
+				continue
+			}
+		}
+		merged = append(merged, rec)
+	}
+	d := &DB{records: merged, byASN: make(map[uint32][]int)}
+	for i, rec := range merged {
+		d.byASN[rec.ASN] = append(d.byASN[rec.ASN], i)
+	}
+	return d
+}
+
+// Len returns the number of ranges in the dataset.
+func (d *DB) Len() int {
+	return len(d.records)
+}
+
+// Lookup returns the range containing ip.
+func (d *DB) Lookup(ip netip.Addr) (Record, bool) {
+	ip = ip.Unmap()
+	i := sort.Search(len(d.records), func(i int) bool { return ip.Less(d.records[i].Start) })
+	if i == 0 {
+		return Record{}, false
+	}
+	rec := d.records[i-1]
+	if !rec.Contains(ip) {
+		return Record{}, false
+	}
+	return rec, true
+}
+
+// Ranges returns every range announced by asn.
+func (d *DB) Ranges(asn uint32) []Record {
+	idx := d.byASN[asn]
+	out := make([]Record, 0, len(idx))
+	for _, i := range idx {
+		out = append(out, d.records[i])
+	}
+	return out
+}
+
+// MatchOrg returns the ASNs whose organisation name contains pattern,
+// ignoring case, mapped to that name.
+func (d *DB) MatchOrg(pattern string) map[uint32]string {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	out := make(map[uint32]string)
+	if pattern == "" {
+		return out
+	}
+	for asn, idx := range d.byASN {
+		org := d.records[idx[0]].Org
+		if strings.Contains(strings.ToLower(org), pattern) {
+			out[asn] = org
+		}
+	}
+	return out
+}
+
+// RangePrefixes returns the smallest set of prefixes covering start..end.
+func RangePrefixes(start, end netip.Addr) []netip.Prefix {
+	var out []netip.Prefix
+	if !start.IsValid() || !end.IsValid() || start.Is4() != end.Is4() || end.Less(start) {
+		return out
+	}
+	for {
+		var p netip.Prefix
+		for bits := 0; bits <= start.BitLen(); bits++ {
+			candidate := netip.PrefixFrom(start, bits).Masked()
+			if candidate.Addr() == start && !end.Less(lastAddr(candidate)) {
+				p = candidate
+				break
+			}
+		}
+		out = append(out, p)
+		last := lastAddr(p)
+		if last == end {
+			return out
+		}
+		start = last.Next()
+	}
+}
+
+// lastAddr returns the highest address in p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().As16()
+	offset := 0
+	if p.Addr().Is4() {
+		offset = 96
+	}
+	for i := offset + p.Bits(); i < 128; i++ {
+		b[i/8] |= 1 << (7 - uint(i%8))
+	}
+	addr := netip.AddrFrom16(b)
+	if p.Addr().Is4() {
+		addr = addr.Unmap()
+	}
+	return addr
+}
@@ -0,0 +1,77 @@
+package asn
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRangePrefixes(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       []string
+	}{
+		{"192.0.2.0", "192.0.2.255", []string{"192.0.2.0/24"}},
+		{"192.0.2.0", "192.0.3.127", []string{"192.0.2.0/24", "192.0.3.0/25"}},
+		{"10.0.0.1", "10.0.0.6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"0.0.0.0", "255.255.255.255", []string{"0.0.0.0/0"}},
+		{"2001:db8::", "2001:db8::ffff", []string{"2001:db8::/112"}},
+		{"192.0.2.9", "192.0.2.1", nil},
+		{"192.0.2.1", "2001:db8::1", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range RangePrefixes(netip.MustParseAddr(tt.start), netip.MustParseAddr(tt.end)) {
+			got = append(got, p.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("RangePrefixes(%s, %s) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestParseTSVAndLookup(t *testing.T) {
+	const data = "# ip2asn\n" +
+		"192.0.2.0\t192.0.2.127\t64500\tus\tExample Org\n" +
+		"192.0.2.128\t192.0.2.255\tAS64500\tUS\tExample Org\n" +
+		"3221226240\t3221226495\t0\tNone\tNot routed\n" +
+		"2001:db8::\t2001:db8::ffff\t64501\tZZ\tOther Net\n"
+	records, err := parseTSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseTSV: %v", err)
+	}
+	db := newDB(records)
+	if db.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 (adjacent ranges merged, AS0 skipped)", db.Len())
+	}
+
+	tests := []struct {
+		ip      string
+		wantASN uint32
+		wantOK  bool
+	}{
+		{"192.0.2.1", 64500, true},
+		{"192.0.2.200", 64500, true},
+		{"::ffff:192.0.2.7", 64500, true},
+		{"192.0.3.1", 0, false},
+		{"2001:db8::42", 64501, true},
+		{"10.0.0.1", 0, false},
+	}
+	for _, tt := range tests {
+		rec, ok := db.Lookup(netip.MustParseAddr(tt.ip))
+		if ok != tt.wantOK || rec.ASN != tt.wantASN {
+			t.Errorf("Lookup(%s) = (AS%d, %v), want (AS%d, %v)", tt.ip, rec.ASN, ok, tt.wantASN, tt.wantOK)
+		}
+	}
+	if got := db.MatchOrg("example"); !reflect.DeepEqual(got, map[uint32]string{64500: "Example Org"}) {
+		t.Errorf("MatchOrg(example) = %v", got)
+	}
+	if ranges := db.Ranges(64500); len(ranges) != 1 || ranges[0].Prefixes()[0].String() != "192.0.2.0/24" {
+		t.Errorf("Ranges(64500) = %+v, want 192.0.2.0/24", ranges)
+	}
+
+	if _, err := parseTSV(strings.NewReader("192.0.2.0\t192.0.2.255\n")); err == nil {
+		t.Error("parseTSV accepted a line without an AS number")
+	}
+}
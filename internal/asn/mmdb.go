@@ -0,0 +1,420 @@
+package asn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"strings"
+)
+
+// mmdbMetadataMarker precedes the metadata map at the end of a MaxMind DB.
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+var errMMDBCorrupt = errors.New("corrupt mmdb data")
+
+// mmdbReader walks the search tree of a MaxMind DB. Only what is needed to
+// list every network with its ASN is implemented.
+type mmdbReader struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	treeSize   uint
+	data       []byte // data section
+}
+
+func readMMDB(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseMMDB(data)
+}
+
+// parseMMDB lists every network of a MaxMind DB holding ASN data. Both the
+// GeoLite2-ASN keys (autonomous_system_number/_organization) and the
+// asn/name/as_name/country keys of other ASN databases are understood.
+func parseMMDB(buf []byte) ([]Record, error) {
+	i := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if i < 0 {
+		return nil, errors.New("not a MaxMind DB file")
+	}
+	metaStart := i + len(mmdbMetadataMarker)
+	meta, _, err := (&mmdbDecoder{buf: buf[metaStart:]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("read metadata: %w", err)
+	}
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, errMMDBCorrupt
+	}
+	rd := &mmdbReader{
+		buf:        buf,
+		nodeCount:  uint(mmdbUint(m["node_count"])),
+		recordSize: uint(mmdbUint(m["record_size"])),
+		ipVersion:  uint(mmdbUint(m["ip_version"])),
+	}
+	switch rd.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", rd.recordSize)
+	}
+	rd.treeSize = rd.recordSize * 2 / 8 * rd.nodeCount
+	if rd.treeSize+16 > uint(i) {
+		return nil, errMMDBCorrupt
+	}
+	rd.data = buf[rd.treeSize+16 : i]
+	return rd.records()
+}
+
+// records walks the tree depth-first. In IPv6 trees the IPv4 space sits
+// under ::/96 and is aliased from other prefixes; it is walked once as IPv4.
+func (rd *mmdbReader) records() ([]Record, error) {
+	w := &mmdbWalk{rd: rd, cache: make(map[uint]*Record), ipv4Start: rd.nodeCount}
+	if rd.ipVersion == 6 {
+		node := uint(0)
+		for depth := 0; depth < 96 && node < rd.nodeCount; depth++ {
+			next, err := rd.child(node, 0)
+			if err != nil {
+				return nil, err
+			}
+			node = next
+		}
+		w.ipv4Start = node
+		if err := w.walk(node, [16]byte{}, 96, 96); err != nil {
+			return nil, err
+		}
+		w.skipIPv4 = true
+		if err := w.walk(0, [16]byte{}, 0, 0); err != nil {
+			return nil, err
+		}
+		return w.out, nil
+	}
+	if err := w.walk(0, [16]byte{}, 96, 96); err != nil {
+		return nil, err
+	}
+	return w.out, nil
+}
+
+// child returns the left (bit 0) or right (bit 1) record of node.
+func (rd *mmdbReader) child(node uint, bit int) (uint, error) {
+	size := rd.recordSize * 2 / 8
+	off := node * size
+	if off+size > rd.treeSize {
+		return 0, errMMDBCorrupt
+	}
+	b := rd.buf[off : off+size]
+	switch rd.recordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		if bit == 0 {
+			return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3]), nil
+		}
+		return uint(b[4])<<24 | uint(b[5])<<16 | uint(b[6])<<8 | uint(b[7]), nil
+	}
+}
+
+type mmdbWalk struct {
+	rd        *mmdbReader
+	cache     map[uint]*Record // decoded ASN data by data offset
+	ipv4Start uint
+	skipIPv4  bool
+	out       []Record
+}
+
+// walk visits node, the subtree for the depth-bit prefix ip. base is 96 for
+// IPv4 networks, which are emitted as 4-byte addresses.
+func (w *mmdbWalk) walk(node uint, ip [16]byte, depth, base int) error {
+	rd := w.rd
+	if depth > 128 {
+		return errMMDBCorrupt
+	}
+	if node > rd.nodeCount {
+		return w.emit(node, ip, depth, base)
+	}
+	if node == rd.nodeCount {
+		return nil
+	}
+	if w.skipIPv4 && node == w.ipv4Start && depth > 0 {
+		return nil
+	}
+	if depth == 128 {
+		return errMMDBCorrupt
+	}
+	for bit := 0; bit < 2; bit++ {
+		next, err := rd.child(node, bit)
+		if err != nil {
+			return err
+		}
+		childIP := ip
+		if bit == 1 {
+			childIP[depth/8] |= 1 << (7 - uint(depth%8))
+		}
+		if err := w.walk(next, childIP, depth+1, base); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *mmdbWalk) emit(pointer uint, ip [16]byte, depth, base int) error {
+	off := pointer - w.rd.nodeCount - 16
+	info, ok := w.cache[off]
+	if !ok {
+		value, _, err := (&mmdbDecoder{buf: w.rd.data}).decode(off)
+		if err != nil {
+			return err
+		}
+		info = mmdbASNInfo(value)
+		w.cache[off] = info
+	}
+	if info == nil {
+		return nil
+	}
+	prefix := netip.PrefixFrom(netip.AddrFrom16(ip), depth)
+	if base == 96 {
+		prefix = netip.PrefixFrom(netip.AddrFrom4([4]byte{ip[12], ip[13], ip[14], ip[15]}), depth-96)
+	}
+	rec := *info
+	rec.Start, rec.End = prefix.Addr(), lastAddr(prefix)
+	w.out = append(w.out, rec)
+	return nil
+}
+
+// mmdbASNInfo extracts the AS number, owner and country of a data record.
+// Records without an AS number yield nil.
+func mmdbASNInfo(value interface{}) *Record {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var rec Record
+	switch v := firstValue(m, "autonomous_system_number", "asn").(type) {
+	case uint64:
+		if v <= math.MaxUint32 {
+			rec.ASN = uint32(v)
+		}
+	case string:
+		n, err := parseASN(v)
+		if err != nil {
+			return nil
+		}
+		rec.ASN = n
+	}
+	if rec.ASN == 0 {
+		return nil
+	}
+	rec.Org, _ = firstValue(m, "autonomous_system_organization", "as_name", "name", "org").(string)
+	rec.Org = strings.TrimSpace(rec.Org)
+	country, _ := firstValue(m, "country", "country_code").(string)
+	rec.Country = normalizeCountry(country)
+	return &rec
+}
+
+func firstValue(m map[string]interface{}, keys ...string) interface{} {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func mmdbUint(v interface{}) uint64 {
+	n, _ := v.(uint64)
+	return n
+}
+
+// mmdbDecoder decodes the MaxMind DB data section format. Pointers are
+// offsets into buf.
+type mmdbDecoder struct {
+	buf []byte
+}
+
+// MaxMind DB data types.
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBool
+	mmdbFloat
+)
+
+// decode returns the value at off and the offset following it.
+func (d *mmdbDecoder) decode(off uint) (interface{}, uint, error) {
+	if off >= uint(len(d.buf)) {
+		return nil, 0, errMMDBCorrupt
+	}
+	ctrl := d.buf[off]
+	off++
+	typ := int(ctrl >> 5)
+	if typ == mmdbPointer {
+		ptr, next, err := d.pointer(ctrl, off)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Pointers never point at pointers; refusing them stops loops.
+		if ptr >= uint(len(d.buf)) || d.buf[ptr]>>5 == mmdbPointer {
+			return nil, 0, errMMDBCorrupt
+		}
+		value, _, err := d.decode(ptr)
+		return value, next, err
+	}
+	if typ == mmdbExtended {
+		if off >= uint(len(d.buf)) {
+			return nil, 0, errMMDBCorrupt
+		}
+		typ = 7 + int(d.buf[off])
+		off++
+	}
+	size, off, err := d.size(ctrl, off)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch typ {
+	case mmdbMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(off)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, next, err := d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errMMDBCorrupt
+			}
+			m[k] = value
+			off = next
+		}
+		return m, off, nil
+	case mmdbArray:
+		arr := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(off)
+			if err != nil {
+				return nil, 0, err
+			}
+			arr = append(arr, value)
+			off = next
+		}
+		return arr, off, nil
+	case mmdbBool:
+		return size != 0, off, nil
+	}
+	if off+size > uint(len(d.buf)) {
+		return nil, 0, errMMDBCorrupt
+	}
+	raw := d.buf[off : off+size]
+	next := off + size
+	switch typ {
+	case mmdbString:
+		return string(raw), next, nil
+	case mmdbBytes, mmdbUint128:
+		return raw, next, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		var n uint64
+		for _, b := range raw {
+			n = n<<8 | uint64(b)
+		}
+		return n, next, nil
+	case mmdbInt32:
+		var n uint32
+		for _, b := range raw {
+			n = n<<8 | uint32(b)
+		}
+		return int64(int32(n)), next, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, errMMDBCorrupt
+		}
+		var n uint64
+		for _, b := range raw {
+			n = n<<8 | uint64(b)
+		}
+		return math.Float64frombits(n), next, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, errMMDBCorrupt
+		}
+		var n uint32
+		for _, b := range raw {
+			n = n<<8 | uint32(b)
+		}
+		return float64(math.Float32frombits(n)), next, nil
+	case mmdbContainer, mmdbEndMarker:
+		return nil, next, nil
+	}
+	return nil, 0, fmt.Errorf("unknown mmdb data type %d", typ)
+}
+
+func (d *mmdbDecoder) pointer(ctrl byte, off uint) (uint, uint, error) {
+	n := uint((ctrl>>3)&0x3) + 1
+	if off+n > uint(len(d.buf)) {
+		return 0, 0, errMMDBCorrupt
+	}
+	var p uint
+	if n < 4 {
+		p = uint(ctrl & 0x7)
+	}
+	for _, b := range d.buf[off : off+n] {
+		p = p<<8 | uint(b)
+	}
+	switch n {
+	case 2:
+		p += 2048
+	case 3:
+		p += 526336
+	}
+	return p, off + n, nil
+}
+
+func (d *mmdbDecoder) size(ctrl byte, off uint) (uint, uint, error) {
+	size := uint(ctrl & 0x1f)
+	if size < 29 {
+		return size, off, nil
+	}
+	n := size - 28
+	if off+n > uint(len(d.buf)) {
+		return 0, 0, errMMDBCorrupt
+	}
+	var v uint
+	for _, b := range d.buf[off : off+n] {
+		v = v<<8 | uint(b)
+	}
+	switch n {
+	case 1:
+		v += 29
+	case 2:
+		v += 285
+	default:
+		v += 65821
+	}
+	return v, off + n, nil
+}
@@ -0,0 +1,198 @@
+package asn
+
+import (
+	"math"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+// encString, encUint32 and encMap encode data section values for the test
+// databases below. Strings may use the one-byte size extension.
+func encString(s string) []byte {
+	if len(s) >= 29 {
+		return append([]byte{mmdbString<<5 | 29, byte(len(s) - 29)}, s...)
+	}
+	return append([]byte{mmdbString<<5 | byte(len(s))}, s...)
+}
+
+func encUint32(n uint32) []byte {
+	return []byte{mmdbUint32<<5 | 4, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+func encMap(pairs ...[]byte) []byte {
+	out := []byte{mmdbMap<<5 | byte(len(pairs)/2)}
+	for _, p := range pairs {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// buildMMDB lays out a database with 24-bit records. nodes holds the left
+// and right record of each node; a record pointing at data holds
+// len(nodes) + 16 + the offset of the value in data.
+func buildMMDB(ipVersion uint32, nodes [][2]uint32, data []byte) []byte {
+	nodeCount := uint32(len(nodes))
+	var buf []byte
+	for _, node := range nodes {
+		for _, rec := range node {
+			buf = append(buf, byte(rec>>16), byte(rec>>8), byte(rec))
+		}
+	}
+	buf = append(buf, make([]byte, 16)...)
+	buf = append(buf, data...)
+	buf = append(buf, mmdbMetadataMarker...)
+	buf = append(buf, encMap(
+		encString("node_count"), encUint32(nodeCount),
+		encString("record_size"), encUint32(24),
+		encString("ip_version"), encUint32(ipVersion),
+	)...)
+	return buf
+}
+
+func TestParseMMDB(t *testing.T) {
+	geoLite := encMap(
+		encString("autonomous_system_number"), encUint32(64500),
+		encString("autonomous_system_organization"), encString(" Example Org "),
+	)
+	other := encMap(
+		encString("asn"), encString("AS64501"),
+		encString("name"), encString("Other Net"),
+		encString("country"), encString("us"),
+	)
+	noASN := encMap(encString("network"), encString("reserved"))
+	data := append(append(append([]byte{}, geoLite...), other...), noASN...)
+	offGeoLite, offOther, offNoASN := uint32(0), uint32(len(geoLite)), uint32(len(geoLite)+len(other))
+
+	t.Run("ipv4 tree", func(t *testing.T) {
+		// 0/1 -> geoLite; 128/2 -> no ASN; 192/2 -> other.
+		const nodeCount = 2
+		ptr := func(off uint32) uint32 { return nodeCount + 16 + off }
+		db := buildMMDB(4, [][2]uint32{
+			{ptr(offGeoLite), 1},
+			{ptr(offNoASN), ptr(offOther)},
+		}, data)
+		got, err := parseMMDB(db)
+		if err != nil {
+			t.Fatalf("parseMMDB: %v", err)
+		}
+		want := []Record{
+			{Start: netip.MustParseAddr("0.0.0.0"), End: netip.MustParseAddr("127.255.255.255"), ASN: 64500, Org: "Example Org"},
+			{Start: netip.MustParseAddr("192.0.0.0"), End: netip.MustParseAddr("255.255.255.255"), ASN: 64501, Org: "Other Net", Country: "US"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseMMDB() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("ipv6 tree with ipv4 subtree", func(t *testing.T) {
+		// Nodes 0..95 lead left to ::/96, whose node holds the IPv4 space.
+		// The right branch of node 0 is 8000::/1.
+		const nodeCount = 97
+		ptr := func(off uint32) uint32 { return nodeCount + 16 + off }
+		nodes := make([][2]uint32, nodeCount)
+		for i := 0; i < 96; i++ {
+			nodes[i] = [2]uint32{uint32(i + 1), nodeCount}
+		}
+		nodes[0][1] = ptr(offOther)
+		nodes[96] = [2]uint32{ptr(offGeoLite), nodeCount}
+		got, err := parseMMDB(buildMMDB(6, nodes, data))
+		if err != nil {
+			t.Fatalf("parseMMDB: %v", err)
+		}
+		want := []Record{
+			{Start: netip.MustParseAddr("0.0.0.0"), End: netip.MustParseAddr("127.255.255.255"), ASN: 64500, Org: "Example Org"},
+			{Start: netip.MustParseAddr("8000::"), End: netip.MustParseAddr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), ASN: 64501, Org: "Other Net", Country: "US"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseMMDB() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			buf  []byte
+		}{
+			{"no metadata", []byte("not a database")},
+			{"tree larger than file", buildMMDB(4, [][2]uint32{{0, 0}}, nil)[6:]},
+			{"record points outside tree", buildMMDB(4, [][2]uint32{{5, 5}, {1, 1}}, nil)},
+		}
+		for _, tt := range tests {
+			if _, err := parseMMDB(tt.buf); err == nil {
+				t.Errorf("%s: parseMMDB() succeeded, want error", tt.name)
+			}
+		}
+	})
+}
+
+func TestMMDBDecode(t *testing.T) {
+	long := make([]byte, 300)
+	for i := range long {
+		long[i] = 'a'
+	}
+	tests := []struct {
+		name    string
+		buf     []byte
+		want    interface{}
+		wantEnd uint
+		wantErr bool
+	}{
+		{name: "string", buf: encString("hi"), want: "hi", wantEnd: 3},
+		{name: "uint16", buf: []byte{mmdbUint16<<5 | 2, 0x01, 0x02}, want: uint64(0x0102), wantEnd: 3},
+		{name: "empty uint32", buf: []byte{mmdbUint32 << 5}, want: uint64(0), wantEnd: 1},
+		{name: "uint64 extended", buf: []byte{2, mmdbUint64 - 7, 0x01, 0x00}, want: uint64(256), wantEnd: 4},
+		{name: "int32 negative", buf: []byte{4, mmdbInt32 - 7, 0xff, 0xff, 0xff, 0xfe}, want: int64(-2), wantEnd: 6},
+		{name: "bool", buf: []byte{1, mmdbBool - 7}, want: true, wantEnd: 2},
+		{name: "double", buf: []byte{mmdbDouble<<5 | 8, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, want: 1.5, wantEnd: 9},
+		{name: "float", buf: []byte{4, mmdbFloat - 7, 0x3f, 0xc0, 0, 0}, want: float64(float32(1.5)), wantEnd: 6},
+		{name: "size extension", buf: append([]byte{mmdbString<<5 | 30, 0x00, 300 - 285}, long...), want: string(long), wantEnd: 303},
+		{name: "array", buf: []byte{1, mmdbArray - 7, mmdbString<<5 | 1, 'x'}, want: []interface{}{"x"}, wantEnd: 4},
+		{name: "pointer", buf: append([]byte{mmdbPointer << 5, 2}, encString("ab")...), want: "ab", wantEnd: 2},
+		{name: "pointer to pointer", buf: []byte{mmdbPointer << 5, 2, mmdbPointer << 5, 0}, wantErr: true},
+		{name: "truncated string", buf: []byte{mmdbString<<5 | 5, 'a'}, wantErr: true},
+		{name: "bad double size", buf: []byte{mmdbDouble<<5 | 4, 0, 0, 0, 0}, wantErr: true},
+		{name: "map with non-string key", buf: []byte{mmdbMap<<5 | 1, mmdbUint16<<5 | 1, 1, mmdbUint16<<5 | 1, 2}, wantErr: true},
+		{name: "empty", buf: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, end, err := (&mmdbDecoder{buf: tt.buf}).decode(0)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decode() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || end != tt.wantEnd {
+				t.Errorf("decode() = (%#v, %d), want (%#v, %d)", got, end, tt.want, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestMMDBASNInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  *Record
+	}{
+		{"geolite", map[string]interface{}{"autonomous_system_number": uint64(13335), "autonomous_system_organization": "CLOUDFLARENET"}, &Record{ASN: 13335, Org: "CLOUDFLARENET"}},
+		{"string asn", map[string]interface{}{"asn": "AS15169", "as_name": "GOOGLE", "country_code": "us"}, &Record{ASN: 15169, Org: "GOOGLE", Country: "US"}},
+		{"unknown country", map[string]interface{}{"asn": uint64(64500), "country": "ZZ"}, &Record{ASN: 64500}},
+		{"zero asn", map[string]interface{}{"asn": uint64(0)}, nil},
+		{"asn out of range", map[string]interface{}{"asn": uint64(math.MaxUint32 + 1)}, nil},
+		{"bad asn string", map[string]interface{}{"asn": "ASX"}, nil},
+		{"not a map", "AS13335", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mmdbASNInfo(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mmdbASNInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package asn
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readFile opens path, decompressing .gz files, and hands it to parse.
+func readFile(path string, parse func(io.Reader) ([]Record, error)) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return parse(r)
+}
+
+// parseTSV reads ip2asn rows: range_start, range_end, AS_number,
+// country_code, AS_description. Addresses may also be written as 32-bit
+// integers (ip2asn-v4-u32). Unrouted ranges (AS 0) are skipped.
+func parseTSV(r io.Reader) ([]Record, error) {
+	var out []Record
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.SplitN(text, "\t", 5)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least 3 tab-separated fields", line)
+		}
+		start, err1 := parseTSVAddr(fields[0])
+		end, err2 := parseTSVAddr(fields[1])
+		num, err3 := parseASN(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("line %d: invalid range or AS number", line)
+		}
+		if num == 0 || start.Is4() != end.Is4() {
+			continue
+		}
+		rec := Record{Start: start, End: end, ASN: num}
+		if len(fields) > 3 {
+			rec.Country = normalizeCountry(fields[3])
+		}
+		if len(fields) > 4 {
+			rec.Org = strings.TrimSpace(fields[4])
+		}
+		out = append(out, rec)
+	}
+	return out, sc.Err()
+}
+
+func parseTSVAddr(s string) (netip.Addr, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap(), nil
+}
+
+// parseCSV reads the GeoLite2-ASN CSV layout: network,
+// autonomous_system_number, autonomous_system_organization.
+func parseCSV(r io.Reader) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	var out []Record
+	for row := 1; ; row++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 2 || (row == 1 && strings.EqualFold(strings.TrimSpace(fields[0]), "network")) {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		num, err := parseASN(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid AS number %q", row, fields[1])
+		}
+		if num == 0 {
+			continue
+		}
+		prefix = prefix.Masked()
+		rec := Record{Start: prefix.Addr(), End: lastAddr(prefix), ASN: num}
+		if len(fields) > 2 {
+			rec.Org = strings.TrimSpace(fields[2])
+		}
+		out = append(out, rec)
+	}
+}
+
+// parseASN accepts "13335" and "AS13335".
+func parseASN(s string) (uint32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2 && strings.EqualFold(s[:2], "as") {
+		s = s[2:]
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	return uint32(n), err
+}
+
+func normalizeCountry(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "NONE" || s == "ZZ" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	digitRunRegex  = regexp.MustCompile(`[0-9]+`)
+	dnsLabelRegex  = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	permutationEnv = []string{"dev", "test", "qa", "uat", "stg", "stage", "staging", "pre", "preprod", "prod", "beta", "demo", "sandbox"}
+)
+
+// permutationInsertWords are inserted as extra labels and dash-joined
+// affixes, next to the most common tokens of the known subdomains.
+var permutationInsertWords = []string{"dev", "stg", "staging", "uat", "test", "prod", "api", "admin", "internal", "old", "new", "v2"}
+
+const (
+	defaultPermutationLimit = 5000
+	maxPermutationLimit     = 100000
+	permutationLearnedWords = 20
+)
+
+// BuildPermutations generates alteration candidates (altdns/gotator style)
+// from known subdomains: number increments, environment swaps, dash/dot
+// joins and splits, and label insertion. Each candidate is a full hostname
+// under the root domain of the subdomain it was derived from
+// (api-dev.eu.example.com -> api-stg.eu.example.com), so it is resolved
+// once rather than against every root. Known names are never returned.
+// Cheaper, higher-yield mutations come first, so the cap drops insertions
+// before increments.
+func BuildPermutations(knownSubdomains, rootDomains []string, maxCandidates int) []string {
+	if maxCandidates <= 0 {
+		maxCandidates = defaultPermutationLimit
+	}
+	if maxCandidates > maxPermutationLimit {
+		maxCandidates = maxPermutationLimit
+	}
+
+	type seed struct {
+		host     string
+		relative string
+		root     string
+	}
+	roots := normalizeRootDomains(rootDomains)
+	known := make(map[string]bool, len(knownSubdomains))
+	seeds := make([]seed, 0, len(knownSubdomains))
+	tokenScores := make(map[string]int, 256)
+	for _, raw := range knownSubdomains {
+		host := normalizeHost(raw)
+		if host == "" || known[host] {
+			continue
+		}
+		known[host] = true
+		relative, root := splitRoot(host, roots)
+		if relative == "" || root == "" {
+			continue
+		}
+		seeds = append(seeds, seed{host: host, relative: relative, root: root})
+		for _, label := range strings.Split(relative, ".") {
+			for _, part := range splitWordParts(label) {
+				addWordScore(tokenScores, part, 1)
+			}
+		}
+	}
+	sort.Slice(seeds, func(i, j int) bool { return seeds[i].host < seeds[j].host })
+	insertWords := mergeInsertWords(tokenScores)
+
+	out := make([]string, 0, minInt(maxCandidates, len(seeds)*16))
+	seen := make(map[string]bool, cap(out))
+	add := func(relative, root string) bool {
+		if len(out) >= maxCandidates {
+			return false
+		}
+		if !validRelativeName(relative) {
+			return true
+		}
+		candidate := relative + "." + root
+		if known[candidate] || seen[candidate] {
+			return true
+		}
+		seen[candidate] = true
+		out = append(out, candidate)
+		return true
+	}
+
+	generators := []func(labels []string, add func(string) bool){
+		permuteNumbers,
+		permuteEnvironments,
+		permuteJoins,
+		func(labels []string, add func(string) bool) { permuteInsertions(labels, insertWords, add) },
+	}
+	for _, generate := range generators {
+		for _, sd := range seeds {
+			if len(out) >= maxCandidates {
+				return out
+			}
+			root := sd.root
+			generate(strings.Split(sd.relative, "."), func(relative string) bool { return add(relative, root) })
+		}
+	}
+	return out
+}
+
+// permuteNumbers shifts every number in every label by -1, +1 and +2,
+// keeping zero padding: web01 -> web00, web02, web03.
+func permuteNumbers(labels []string, add func(string) bool) {
+	for i, label := range labels {
+		for _, loc := range digitRunRegex.FindAllStringIndex(label, -1) {
+			run := label[loc[0]:loc[1]]
+			n, err := strconv.Atoi(run)
+			if err != nil || len(run) > 6 {
+				continue
+			}
+			for _, delta := range []int{-1, 1, 2} {
+				if n+delta < 0 {
+					continue
+				}
+				next := fmt.Sprintf("%0*d", len(run), n+delta)
+				if !add(withLabel(labels, i, label[:loc[0]]+next+label[loc[1]:])) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// permuteEnvironments swaps environment tokens: api-dev -> api-stg, api-prod.
+func permuteEnvironments(labels []string, add func(string) bool) {
+	for i, label := range labels {
+		parts := strings.Split(label, "-")
+		for j, part := range parts {
+			if !isPermutationEnv(part) {
+				continue
+			}
+			for _, env := range permutationEnv {
+				if env == part {
+					continue
+				}
+				swapped := append([]string(nil), parts...)
+				swapped[j] = env
+				if !add(withLabel(labels, i, strings.Join(swapped, "-"))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// permuteJoins joins adjacent labels with a dash and splits dashed labels
+// into separate labels: api-dev.eu <-> api.dev.eu, api-dev-eu.
+func permuteJoins(labels []string, add func(string) bool) {
+	for i := 0; i+1 < len(labels); i++ {
+		joined := append(append(append([]string(nil), labels[:i]...), labels[i]+"-"+labels[i+1]), labels[i+2:]...)
+		if !add(strings.Join(joined, ".")) {
+			return
+		}
+	}
+	for i, label := range labels {
+		if !strings.Contains(label, "-") {
+			continue
+		}
+		split := append(append(append([]string(nil), labels[:i]...), strings.Split(label, "-")...), labels[i+1:]...)
+		if !add(strings.Join(split, ".")) {
+			return
+		}
+	}
+}
+
+// permuteInsertions adds a word as a new leading label and as a dash affix
+// of the first label: api.eu -> dev.api.eu, dev-api.eu, api-dev.eu.
+func permuteInsertions(labels []string, words []string, add func(string) bool) {
+	rest := strings.Join(labels[1:], ".")
+	if rest != "" {
+		rest = "." + rest
+	}
+	for _, word := range words {
+		if word == labels[0] {
+			continue
+		}
+		if !add(word+"."+strings.Join(labels, ".")) ||
+			!add(word+"-"+labels[0]+rest) ||
+			!add(labels[0]+"-"+word+rest) {
+			return
+		}
+	}
+}
+
+func withLabel(labels []string, i int, label string) string {
+	out := append([]string(nil), labels...)
+	out[i] = label
+	return strings.Join(out, ".")
+}
+
+func isPermutationEnv(token string) bool {
+	for _, env := range permutationEnv {
+		if env == token {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeInsertWords returns the fixed insert words followed by the most
+// frequent tokens seen in known subdomains.
+func mergeInsertWords(tokenScores map[string]int) []string {
+	learned := make([]string, 0, len(tokenScores))
+	for token := range tokenScores {
+		learned = append(learned, token)
+	}
+	sort.Slice(learned, func(i, j int) bool {
+		if tokenScores[learned[i]] != tokenScores[learned[j]] {
+			return tokenScores[learned[i]] > tokenScores[learned[j]]
+		}
+		return learned[i] < learned[j]
+	})
+	learned = learned[:minInt(len(learned), permutationLearnedWords)]
+
+	seen := make(map[string]bool, len(permutationInsertWords)+len(learned))
+	out := make([]string, 0, len(permutationInsertWords)+len(learned))
+	for _, word := range append(append([]string(nil), permutationInsertWords...), learned...) {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		out = append(out, word)
+	}
+	return out
+}
+
+func validRelativeName(name string) bool {
+	if name == "" || len(name) > 200 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !dnsLabelRegex.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPermutations(t *testing.T) {
+	tests := []struct {
+		name     string
+		known    []string
+		roots    []string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "number increments keep padding",
+			known:    []string{"web01.example.com"},
+			roots:    []string{"example.com"},
+			contains: []string{"web00.example.com", "web02.example.com", "web03.example.com"},
+		},
+		{
+			name:     "environment swaps",
+			known:    []string{"api-dev.example.com"},
+			roots:    []string{"example.com"},
+			contains: []string{"api-stg.example.com", "api-prod.example.com"},
+			excludes: []string{"api-dev.example.com"},
+		},
+		{
+			name:     "joins and splits",
+			known:    []string{"api-dev.eu.example.com"},
+			roots:    []string{"example.com"},
+			contains: []string{"api-dev-eu.example.com", "api.dev.eu.example.com"},
+		},
+		{
+			name:     "insertions",
+			known:    []string{"shop.example.com"},
+			roots:    []string{"example.com"},
+			contains: []string{"dev.shop.example.com", "dev-shop.example.com", "shop-dev.example.com"},
+		},
+		{
+			name:     "candidates stay under the seed's own root",
+			known:    []string{"web1.example.com", "web1.example.org"},
+			roots:    []string{"example.com", "example.org"},
+			contains: []string{"web2.example.com", "web2.example.org"},
+		},
+		{
+			name:     "longest root wins",
+			known:    []string{"api-dev.eu.example.com"},
+			roots:    []string{"example.com", "eu.example.com"},
+			contains: []string{"api-stg.eu.example.com"},
+			excludes: []string{"api-dev-eu.example.com"},
+		},
+		{
+			name:     "seed without a configured root uses its registrable domain",
+			known:    []string{"app1.other.co.uk"},
+			roots:    []string{"example.com"},
+			contains: []string{"app2.other.co.uk"},
+		},
+		{
+			name:     "known names are never returned",
+			known:    []string{"web1.example.com", "web2.example.com"},
+			roots:    []string{"example.com"},
+			excludes: []string{"web1.example.com", "web2.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildPermutations(tt.known, tt.roots, 0)
+			set := make(map[string]bool, len(got))
+			for _, host := range got {
+				if set[host] {
+					t.Errorf("duplicate candidate %s", host)
+				}
+				set[host] = true
+				if !validRelativeName(host) {
+					t.Errorf("invalid candidate %q", host)
+				}
+			}
+			for _, want := range tt.contains {
+				if !set[want] {
+					t.Errorf("missing %s in %v", want, got)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if set[unwanted] {
+					t.Errorf("unexpected %s", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPermutationsCap(t *testing.T) {
+	known := []string{"api-dev01.example.com", "shop-prod.example.com", "mail.eu.example.com"}
+	got := BuildPermutations(known, []string{"example.com"}, 5)
+	if len(got) != 5 {
+		t.Fatalf("len(BuildPermutations) = %d, want 5", len(got))
+	}
+	// Number increments come first, so the cap keeps them.
+	if got[0] != "api-dev00.example.com" {
+		t.Errorf("first candidate = %s, want api-dev00.example.com", got[0])
+	}
+	for _, host := range got {
+		if !strings.HasSuffix(host, ".example.com") {
+			t.Errorf("candidate %s is not under example.com", host)
+		}
+	}
+}
+
+func TestSplitRoot(t *testing.T) {
+	roots := normalizeRootDomains([]string{"Example.com", "eu.example.com", "example.com."})
+	tests := []struct {
+		host         string
+		wantRelative string
+		wantRoot     string
+	}{
+		{"a.b.example.com", "a.b", "example.com"},
+		{"api.eu.example.com", "api", "eu.example.com"},
+		{"example.com", "", "example.com"},
+		{"www.other.co.uk", "www", "other.co.uk"},
+		{"localhost", "localhost", ""},
+	}
+	for _, tt := range tests {
+		relative, root := splitRoot(tt.host, roots)
+		if relative != tt.wantRelative || root != tt.wantRoot {
+			t.Errorf("splitRoot(%q) = (%q, %q), want (%q, %q)", tt.host, relative, root, tt.wantRelative, tt.wantRoot)
+		}
+	}
+}
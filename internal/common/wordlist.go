@@ -243,23 +243,31 @@ func normalizeRootDomains(rootDomains []string) []string {
 }
 
 func relativePart(host string, roots []string) string {
+	relative, _ := splitRoot(host, roots)
+	return relative
+}
+
+// splitRoot splits host into the part below its root domain and the root
+// domain, preferring the longest matching entry of roots. Hosts without a
+// known root are returned whole with an empty root.
+func splitRoot(host string, roots []string) (string, string) {
 	for _, root := range roots {
 		if host == root {
-			return ""
+			return "", root
 		}
 		suffix := "." + root
 		if strings.HasSuffix(host, suffix) {
-			return strings.TrimSuffix(host, suffix)
+			return strings.TrimSuffix(host, suffix), root
 		}
 	}
 	erd := EffectiveRootDomain(host)
 	if erd != "" && erd != host {
 		suffix := "." + erd
 		if strings.HasSuffix(host, suffix) {
-			return strings.TrimSuffix(host, suffix)
+			return strings.TrimSuffix(host, suffix), erd
 		}
 	}
-	return host
+	return host, ""
 }
 
 func splitWordParts(label string) []string {
@@ -28,6 +28,8 @@ type Database struct {
 var (
 	ErrJobActive         = errors.New("job is running or pending")
 	ErrMonitorTaskActive = errors.New("monitor task is running or pending")
+	ErrJobNotResumable   = errors.New("job is not in a resumable state")
+	ErrLeaseLost         = errors.New("scan job lease lost")
 )
 
 const (
@@ -73,11 +75,12 @@ func openDatabase(dsn string, runMigrate bool) (*Database, error) {
 
 	if runMigrate {
 		if err := database.AutoMigrate(
-			&Project{}, &ProjectScope{},
-			&AppSetting{},
-			&Asset{}, &AssetCandidate{}, &Port{}, &Vulnerability{}, &VulnEvent{},
+			&Project{}, &ProjectScope{}, &ProjectScopeRule{}, &WildcardZone{},
+			&AppSetting{}, &DNSResolver{}, &APIKey{}, &NetblockSuggestion{},
+			&Asset{}, &WebResponse{}, &Certificate{}, &AssetCandidate{}, &AssetSource{}, &DNSRecord{}, &BruteforceWord{}, &Port{}, &Vulnerability{}, &VulnEvent{},
 			&MonitorRun{}, &AssetChange{}, &PortChange{}, &MonitorEvent{}, &MonitorSnapshot{}, &MonitorTarget{}, &MonitorTask{},
 			&ScanJob{}, &ScanStage{}, &ScanArtifact{}, &JobLog{}, &AssetEdge{}, &AuditLog{},
+			&Worker{},
 		); err != nil {
 			return nil, fmt.Errorf("failed to migrate database: %v", err)
 		}
@@ -202,12 +205,14 @@ func (d *Database) SaveOrUpdateAsset(data map[string]interface{}) error {
 			Technologies: techJSON,
 			SourceJobID:  sourceJobID,
 			SourceModule: sourceModule,
+			DiscoveryHop: getIntValue(data, "discovery_hop"),
 			FirstSeenAt:  now,
 			LastSeen:     now,
 		}
 		if err := d.DB.Create(&asset).Error; err != nil {
 			return fmt.Errorf("failed to create asset: %v", err)
 		}
+		existingAsset = asset
 	} else if result.Error == nil {
 		updates := map[string]interface{}{"last_seen": now}
 		if rootDomain != "" {
@@ -242,9 +247,316 @@ func (d *Database) SaveOrUpdateAsset(data map[string]interface{}) error {
 		return fmt.Errorf("database query error: %v", result.Error)
 	}
 
+	return d.saveWebResponse(existingAsset, data, now)
+}
+
+// saveWebResponse upserts the response metadata carried by a web_service
+// result for its URL. Results without a URL are ignored.
+func (d *Database) saveWebResponse(asset Asset, data map[string]interface{}, now time.Time) error {
+	url := strings.TrimSpace(getStringValue(data, "url"))
+	if url == "" {
+		return nil
+	}
+	headersJSON, _ := json.Marshal(headerValues(data["headers"]))
+	row := WebResponse{
+		ProjectID:     asset.ProjectID,
+		AssetID:       asset.ID,
+		Domain:        asset.Domain,
+		URL:           url,
+		FinalURL:      strings.TrimSpace(getStringValue(data, "final_url")),
+		Location:      strings.TrimSpace(getStringValue(data, "location")),
+		StatusCode:    getIntValue(data, "status_code"),
+		ContentType:   strings.TrimSpace(getStringValue(data, "content_type")),
+		ContentLength: getIntValue(data, "content_length"),
+		BodySHA256:    strings.ToLower(strings.TrimSpace(getStringValue(data, "body_sha256"))),
+		Server:        strings.TrimSpace(getStringValue(data, "webserver")),
+		CDN:           strings.ToLower(strings.TrimSpace(getStringValue(data, "cdn"))),
+		Headers:       headersJSON,
+		ResponseTime:  getIntValue(data, "response_time_ms"),
+		FaviconMMH3:   strings.TrimSpace(getStringValue(data, "favicon_mmh3")),
+		FaviconMD5:    strings.ToLower(strings.TrimSpace(getStringValue(data, "favicon_md5"))),
+		FaviconURL:    strings.TrimSpace(getStringValue(data, "favicon_url")),
+		SourceJobID:   strings.TrimSpace(getStringValue(data, "source_job_id")),
+		FirstSeenAt:   now,
+		LastSeen:      now,
+	}
+	if row.FinalURL == "" {
+		row.FinalURL = url
+	}
+	err := d.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "asset_id"}, {Name: "url"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"project_id", "domain", "final_url", "location", "status_code", "content_type", "content_length",
+			"body_sha256", "server", "cdn", "headers", "response_time", "favicon_mmh3", "favicon_md5", "favicon_url",
+			"source_job_id", "last_seen",
+		}),
+	}).Create(&row).Error
+	if err != nil {
+		return fmt.Errorf("failed to save web response: %v", err)
+	}
 	return nil
 }
 
+// headerValues accepts headers as produced by plugins (map[string]string)
+// or decoded from JSON (map[string]interface{}).
+func headerValues(raw interface{}) map[string]string {
+	out := map[string]string{}
+	switch v := raw.(type) {
+	case map[string]string:
+		for key, value := range v {
+			out[key] = value
+		}
+	case map[string]interface{}:
+		for key, value := range v {
+			if s, ok := value.(string); ok {
+				out[key] = s
+			}
+		}
+	}
+	return out
+}
+
+// ListWebResponses returns the stored responses of an asset, most recently
+// seen first.
+func (d *Database) ListWebResponses(projectID string, assetID uint) ([]WebResponse, error) {
+	var rows []WebResponse
+	err := d.DB.Where("project_id = ? AND asset_id = ?", projectID, assetID).
+		Order("last_seen desc").Limit(100).Find(&rows).Error
+	return rows, err
+}
+
+// FaviconGroup counts the hosts of a project serving one favicon.
+type FaviconGroup struct {
+	MMH3     string    `json:"mmh3"`
+	MD5      string    `json:"md5"`
+	Hosts    int64     `json:"hosts"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// FaviconGroups groups the web responses of a project by favicon mmh3 hash,
+// largest groups first. A non-empty hash restricts the result to that hash.
+func (d *Database) FaviconGroups(projectID, hash string, limit int) ([]FaviconGroup, error) {
+	q := d.DB.Model(&WebResponse{}).
+		Select("favicon_mmh3 AS mmh3, MAX(favicon_md5) AS md5, COUNT(DISTINCT asset_id) AS hosts, MAX(last_seen) AS last_seen").
+		Where("project_id = ? AND favicon_mmh3 <> ''", projectID)
+	if hash != "" {
+		q = q.Where("favicon_mmh3 = ?", hash)
+	}
+	var groups []FaviconGroup
+	err := q.Group("favicon_mmh3").Order("hosts desc, mmh3 asc").Limit(limit).Scan(&groups).Error
+	return groups, err
+}
+
+// ListFaviconResponses returns the web responses serving the favicon with
+// the given mmh3 hash, most recently seen first.
+func (d *Database) ListFaviconResponses(projectID, hash string, limit int) ([]WebResponse, error) {
+	var rows []WebResponse
+	err := d.DB.Where("project_id = ? AND favicon_mmh3 = ?", projectID, hash).
+		Order("last_seen desc").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// SaveCertificate upserts the certificate of a tls_certificate result for
+// its host and port, linking the asset of the host and the scanned port when
+// they exist. It returns the row as it was before the update, or nil when the
+// endpoint had no certificate yet.
+func (d *Database) SaveCertificate(data map[string]interface{}) (*Certificate, error) {
+	host := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(getStringValue(data, "host")), "."))
+	port := getIntValue(data, "port")
+	fingerprint := strings.ToLower(strings.TrimSpace(getStringValue(data, "fingerprint_sha256")))
+	if host == "" || port <= 0 || fingerprint == "" {
+		return nil, fmt.Errorf("host, port and fingerprint_sha256 are required")
+	}
+	projectID := strings.TrimSpace(getStringValue(data, "project_id"))
+	if projectID == "" {
+		projectID = "default"
+	}
+	ip := strings.TrimSpace(getStringValue(data, "ip"))
+	sansJSON, _ := json.Marshal(getStringsValue(data, "sans"))
+	now := time.Now()
+	row := Certificate{
+		ProjectID:          projectID,
+		RootDomain:         strings.TrimSpace(getStringValue(data, "root_domain")),
+		Host:               host,
+		Port:               port,
+		IP:                 ip,
+		URL:                strings.TrimSpace(getStringValue(data, "url")),
+		Subject:            strings.TrimSpace(getStringValue(data, "subject")),
+		SubjectCN:          strings.TrimSpace(getStringValue(data, "subject_cn")),
+		Issuer:             strings.TrimSpace(getStringValue(data, "issuer")),
+		IssuerCN:           strings.TrimSpace(getStringValue(data, "issuer_cn")),
+		SANs:               sansJSON,
+		NotBefore:          getTimeValue(data, "not_before"),
+		NotAfter:           getTimeValue(data, "not_after"),
+		Serial:             strings.TrimSpace(getStringValue(data, "serial")),
+		KeyType:            strings.TrimSpace(getStringValue(data, "key_type")),
+		SignatureAlgorithm: strings.TrimSpace(getStringValue(data, "signature_algorithm")),
+		FingerprintSHA256:  fingerprint,
+		SelfSigned:         getBoolValue(data, "self_signed"),
+		Source:             strings.TrimSpace(getStringValue(data, "source")),
+		SourceJobID:        strings.TrimSpace(getStringValue(data, "source_job_id")),
+		FirstSeenAt:        now,
+		ChangedAt:          now,
+		LastSeen:           now,
+	}
+
+	var asset Asset
+	if err := d.DB.Select("id").Where("project_id = ? AND domain = ?", projectID, host).Limit(1).Find(&asset).Error; err != nil {
+		return nil, fmt.Errorf("database query error: %v", err)
+	}
+	row.AssetID = asset.ID
+	var portRow Port
+	portQuery := d.DB.Select("id").Where("project_id = ? AND port = ?", projectID, port)
+	if ip != "" {
+		portQuery = portQuery.Where("ip = ? AND domain IN ?", ip, []string{host, ""})
+	} else {
+		portQuery = portQuery.Where("domain = ?", host)
+	}
+	if err := portQuery.Order("domain desc").Limit(1).Find(&portRow).Error; err != nil {
+		return nil, fmt.Errorf("database query error: %v", err)
+	}
+	row.PortID = portRow.ID
+
+	var existing Certificate
+	result := d.DB.Where("project_id = ? AND host = ? AND port = ?", projectID, host, port).Limit(1).Find(&existing)
+	if result.Error != nil {
+		return nil, fmt.Errorf("database query error: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if err := d.DB.Create(&row).Error; err != nil {
+			return nil, fmt.Errorf("failed to create certificate: %v", err)
+		}
+		return nil, nil
+	}
+
+	previous := existing
+	row.ID = existing.ID
+	row.FirstSeenAt = existing.FirstSeenAt
+	if existing.FingerprintSHA256 == fingerprint {
+		row.ChangedAt = existing.ChangedAt
+	}
+	if row.RootDomain == "" {
+		row.RootDomain = existing.RootDomain
+	}
+	if row.AssetID == 0 {
+		row.AssetID = existing.AssetID
+	}
+	if row.PortID == 0 {
+		row.PortID = existing.PortID
+	}
+	if err := d.DB.Save(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to update certificate: %v", err)
+	}
+	return &previous, nil
+}
+
+// SaveCTCertificate stores a certificate found in a Certificate
+// Transparency log. CT entries have no endpoint, so each name gets a row on
+// port 0 holding the newest logged certificate for it.
+func (d *Database) SaveCTCertificate(data map[string]interface{}) error {
+	names := getStringsValue(data, "names")
+	source := strings.TrimSpace(getStringValue(data, "source"))
+	if len(names) == 0 || source == "" {
+		return fmt.Errorf("names and source are required")
+	}
+	projectID := strings.TrimSpace(getStringValue(data, "project_id"))
+	if projectID == "" {
+		projectID = "default"
+	}
+	sansJSON, _ := json.Marshal(names)
+	notAfter := getTimeValue(data, "not_after")
+	now := time.Now()
+	for _, name := range names {
+		host := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+		if host == "" {
+			continue
+		}
+		row := Certificate{
+			ProjectID:         projectID,
+			RootDomain:        strings.TrimSpace(getStringValue(data, "root_domain")),
+			Host:              host,
+			Issuer:            strings.TrimSpace(getStringValue(data, "issuer")),
+			SANs:              sansJSON,
+			NotBefore:         getTimeValue(data, "not_before"),
+			NotAfter:          notAfter,
+			Serial:            strings.TrimSpace(getStringValue(data, "serial")),
+			FingerprintSHA256: strings.ToLower(strings.TrimSpace(getStringValue(data, "fingerprint_sha256"))),
+			Source:            source,
+			SourceJobID:       strings.TrimSpace(getStringValue(data, "source_job_id")),
+			FirstSeenAt:       now,
+			ChangedAt:         now,
+			LastSeen:          now,
+		}
+		var asset Asset
+		if err := d.DB.Select("id").Where("project_id = ? AND domain = ?", projectID, host).Limit(1).Find(&asset).Error; err != nil {
+			return fmt.Errorf("database query error: %v", err)
+		}
+		row.AssetID = asset.ID
+
+		var existing Certificate
+		result := d.DB.Where("project_id = ? AND host = ? AND port = ?", projectID, host, 0).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return fmt.Errorf("database query error: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			if err := d.DB.Create(&row).Error; err != nil {
+				return fmt.Errorf("failed to create certificate: %v", err)
+			}
+			continue
+		}
+		if notAfter.Before(existing.NotAfter) {
+			if err := d.DB.Model(&existing).Update("last_seen", now).Error; err != nil {
+				return fmt.Errorf("failed to update certificate: %v", err)
+			}
+			continue
+		}
+		row.ID = existing.ID
+		row.FirstSeenAt = existing.FirstSeenAt
+		if existing.Serial == row.Serial && existing.Issuer == row.Issuer && existing.FingerprintSHA256 == row.FingerprintSHA256 {
+			row.ChangedAt = existing.ChangedAt
+		}
+		if row.RootDomain == "" {
+			row.RootDomain = existing.RootDomain
+		}
+		if row.AssetID == 0 {
+			row.AssetID = existing.AssetID
+		}
+		if err := d.DB.Save(&row).Error; err != nil {
+			return fmt.Errorf("failed to update certificate: %v", err)
+		}
+	}
+	return nil
+}
+
+// ListCertificates returns the certificates of a project, soonest expiry
+// first. A non-empty host restricts the list to that host; expiresBefore,
+// when set, keeps certificates that expire before it; selfSignedOnly keeps
+// self-signed ones.
+func (d *Database) ListCertificates(projectID, host string, expiresBefore *time.Time, selfSignedOnly bool, limit int) ([]Certificate, error) {
+	q := d.DB.Where("project_id = ?", projectID)
+	if host != "" {
+		q = q.Where("host = ?", strings.ToLower(host))
+	}
+	if expiresBefore != nil {
+		q = q.Where("not_after < ?", *expiresBefore)
+	}
+	if selfSignedOnly {
+		q = q.Where("self_signed = ?", true)
+	}
+	var rows []Certificate
+	err := q.Order("not_after asc, host asc, port asc").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// ListAssetCertificates returns the certificates linked to an asset or
+// served for its domain.
+func (d *Database) ListAssetCertificates(projectID string, assetID uint, domain string) ([]Certificate, error) {
+	var rows []Certificate
+	err := d.DB.Where("project_id = ? AND (asset_id = ? OR host = ?)", projectID, assetID, strings.ToLower(domain)).
+		Order("port asc").Limit(100).Find(&rows).Error
+	return rows, err
+}
+
 // SaveOrUpdateAssetCandidate saves or updates candidate pool entries.
 func (d *Database) SaveOrUpdateAssetCandidate(data map[string]interface{}) error {
 	domain := strings.ToLower(strings.TrimSpace(getStringValue(data, "domain")))
@@ -406,6 +718,7 @@ func (d *Database) SaveOrUpdatePort(data map[string]interface{}) error {
 	rootDomain := strings.TrimSpace(getStringValue(data, "root_domain"))
 	sourceJobID := strings.TrimSpace(getStringValue(data, "source_job_id"))
 	sourceModule := strings.TrimSpace(getStringValue(data, "source_module"))
+	discoveryHop := getIntValue(data, "discovery_hop")
 
 	if ip == "" || port == 0 {
 		return fmt.Errorf("ip and port are required")
@@ -422,6 +735,7 @@ func (d *Database) SaveOrUpdatePort(data map[string]interface{}) error {
 				IP:           ip,
 				SourceJobID:  sourceJobID,
 				SourceModule: sourceModule,
+				DiscoveryHop: discoveryHop,
 				FirstSeenAt:  time.Now(),
 				LastSeen:     time.Now(),
 			}
@@ -441,6 +755,7 @@ func (d *Database) SaveOrUpdatePort(data map[string]interface{}) error {
 				IP:           ip,
 				SourceJobID:  sourceJobID,
 				SourceModule: sourceModule,
+				DiscoveryHop: discoveryHop,
 				FirstSeenAt:  time.Now(),
 				LastSeen:     time.Now(),
 			}
@@ -479,6 +794,7 @@ func (d *Database) SaveOrUpdatePort(data map[string]interface{}) error {
 			Banner:       getStringValue(data, "banner"),
 			SourceJobID:  sourceJobID,
 			SourceModule: sourceModule,
+			DiscoveryHop: discoveryHop,
 			FirstSeenAt:  now,
 			LastSeen:     now,
 		}
@@ -592,6 +908,7 @@ func (d *Database) SaveOrUpdateVulnerability(data map[string]interface{}) error
 			Fingerprint:      fingerprint,
 			Status:           "open",
 			SourceJobID:      sourceJobID,
+			DiscoveryHop:     getIntValue(data, "discovery_hop"),
 			Raw:              rawJSON,
 			FirstSeenAt:      now,
 			LastSeen:         now,
@@ -1326,15 +1643,42 @@ func (d *Database) DeleteProjectAndData(projectID string) error {
 		if err := tx.Unscoped().Where("project_id = ?", projectID).Delete(&Port{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&WebResponse{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&Certificate{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Unscoped().Where("project_id = ?", projectID).Delete(&AssetCandidate{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&AssetSource{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&DNSRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&BruteforceWord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&APIKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&NetblockSuggestion{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Unscoped().Where("project_id = ?", projectID).Delete(&Asset{}).Error; err != nil {
 			return err
 		}
 		if err := tx.Unscoped().Where("project_id = ?", projectID).Delete(&ProjectScope{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&ProjectScopeRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&WildcardZone{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Unscoped().Where("project_id = ?", projectID).Delete(&AuditLog{}).Error; err != nil {
 			return err
 		}
@@ -1656,6 +2000,14 @@ func (d *Database) DeleteAllDataByRootDomain(projectID, rootDomain string) error
 			Delete(&Port{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("project_id = ? AND (domain = ? OR domain LIKE ?)", projectID, rootDomain, pattern).
+			Delete(&WebResponse{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ? AND (host = ? OR host LIKE ?)", projectID, rootDomain, pattern).
+			Delete(&Certificate{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Where("project_id = ? AND (domain = ? OR domain LIKE ?)", projectID, rootDomain, pattern).
 			Delete(&Asset{}).Error; err != nil {
 			return err
@@ -1664,6 +2016,14 @@ func (d *Database) DeleteAllDataByRootDomain(projectID, rootDomain string) error
 			Delete(&AssetCandidate{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("project_id = ? AND (domain = ? OR domain LIKE ?)", projectID, rootDomain, pattern).
+			Delete(&AssetSource{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ? AND (host = ? OR host LIKE ?)", projectID, rootDomain, pattern).
+			Delete(&DNSRecord{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Where("project_id = ? AND root_domain = ?", projectID, rootDomain).Delete(&PortChange{}).Error; err != nil {
 			return err
 		}
@@ -1889,6 +2249,209 @@ func (d *Database) DeleteScanJobHistory(projectID, jobID string) error {
 	})
 }
 
+// SaveScanStageCheckpoint stores the output of a completed stage so the job
+// can resume from it. Re-saving the same stage overwrites the checkpoint.
+func (d *Database) SaveScanStageCheckpoint(projectID, jobID, stage, module string, inputCount, outputCount int, output []byte) error {
+	now := time.Now()
+	var existing ScanStage
+	err := d.DB.Where("project_id = ? AND job_id = ? AND stage = ?", projectID, jobID, stage).First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		return d.DB.Create(&ScanStage{
+			ProjectID:   projectID,
+			JobID:       jobID,
+			Stage:       stage,
+			Module:      module,
+			Status:      "success",
+			InputCount:  inputCount,
+			OutputCount: outputCount,
+			Output:      JSONB(output),
+			StartedAt:   &now,
+			FinishedAt:  &now,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return d.DB.Model(&ScanStage{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+		"module":       module,
+		"status":       "success",
+		"input_count":  inputCount,
+		"output_count": outputCount,
+		"output":       JSONB(output),
+		"error":        "",
+		"finished_at":  now,
+	}).Error
+}
+
+// GetScanStageCheckpoint returns the checkpoint of a completed stage, or nil
+// when the stage has not completed for this job.
+func (d *Database) GetScanStageCheckpoint(projectID, jobID, stage string) (*ScanStage, error) {
+	var row ScanStage
+	err := d.DB.Where("project_id = ? AND job_id = ? AND stage = ? AND status = ? AND output IS NOT NULL", projectID, jobID, stage, "success").
+		Order("updated_at desc").
+		First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// CountScanStageCheckpoints returns how many stages of a job have checkpoints.
+func (d *Database) CountScanStageCheckpoints(projectID, jobID string) (int64, error) {
+	var count int64
+	err := d.DB.Model(&ScanStage{}).
+		Where("project_id = ? AND job_id = ? AND status = ? AND output IS NOT NULL", projectID, jobID, "success").
+		Count(&count).Error
+	return count, err
+}
+
+// ScanStageTiming is the observed duration of one completed stage of a past
+// scan job.
+type ScanStageTiming struct {
+	JobID       string
+	Stage       string
+	InputCount  int
+	DurationSec float64
+}
+
+// ListScanStageTimings returns stage durations of the most recent successful
+// scan jobs of projectID. Checkpoint rows only record when a stage finished,
+// so each stage is measured from the previous stage of the same job, or from
+// the job start for the first one.
+func (d *Database) ListScanStageTimings(projectID string, jobLimit int) ([]ScanStageTiming, error) {
+	var jobs []ScanJob
+	if err := d.DB.Select("job_id", "started_at").
+		Where("project_id = ? AND mode = ? AND status = ? AND started_at IS NOT NULL", projectID, "scan", "success").
+		Order("finished_at desc").
+		Limit(jobLimit).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	jobIDs := make([]string, 0, len(jobs))
+	startedAt := make(map[string]time.Time, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.JobID)
+		startedAt[job.JobID] = *job.StartedAt
+	}
+
+	var stages []ScanStage
+	if err := d.DB.Select("job_id", "stage", "input_count", "finished_at").
+		Where("project_id = ? AND job_id IN ? AND stage <> ? AND status = ? AND finished_at IS NOT NULL", projectID, jobIDs, "pipeline", "success").
+		Order("job_id asc, finished_at asc").
+		Find(&stages).Error; err != nil {
+		return nil, err
+	}
+	out := make([]ScanStageTiming, 0, len(stages))
+	prev := make(map[string]time.Time, len(jobs))
+	for _, stage := range stages {
+		from, ok := prev[stage.JobID]
+		if !ok {
+			from = startedAt[stage.JobID]
+		}
+		prev[stage.JobID] = *stage.FinishedAt
+		duration := stage.FinishedAt.Sub(from).Seconds()
+		if duration < 0 {
+			continue
+		}
+		out = append(out, ScanStageTiming{
+			JobID:       stage.JobID,
+			Stage:       stage.Stage,
+			InputCount:  stage.InputCount,
+			DurationSec: duration,
+		})
+	}
+	return out, nil
+}
+
+// AverageScanJobDuration returns the mean duration of the most recent
+// successful scan jobs of projectID that ran modules or the named pipeline,
+// and how many jobs it is based on.
+func (d *Database) AverageScanJobDuration(projectID, modules, pipeline string, jobLimit int) (float64, int, error) {
+	var durations []int
+	if err := d.DB.Model(&ScanJob{}).
+		Where("project_id = ? AND mode = ? AND status = ? AND modules = ? AND COALESCE(pipeline, '') = ? AND duration_sec > 0", projectID, "scan", "success", modules, pipeline).
+		Order("finished_at desc").
+		Limit(jobLimit).
+		Pluck("duration_sec", &durations).Error; err != nil {
+		return 0, 0, err
+	}
+	if len(durations) == 0 {
+		return 0, 0, nil
+	}
+	total := 0
+	for _, v := range durations {
+		total += v
+	}
+	return float64(total) / float64(len(durations)), len(durations), nil
+}
+
+// CountKnownHosts returns how many subdomains (candidate pool) and live web
+// URLs (verified assets) projectID already has under rootDomains.
+func (d *Database) CountKnownHosts(projectID string, rootDomains []string) (subdomains int64, liveURLs int64, err error) {
+	if len(rootDomains) == 0 {
+		return 0, 0, nil
+	}
+	if err = d.DB.Model(&AssetCandidate{}).
+		Where("project_id = ? AND root_domain IN ?", projectID, rootDomains).
+		Count(&subdomains).Error; err != nil {
+		return 0, 0, err
+	}
+	if err = d.DB.Model(&Asset{}).
+		Where("project_id = ? AND root_domain IN ? AND url <> ?", projectID, rootDomains, "").
+		Count(&liveURLs).Error; err != nil {
+		return 0, 0, err
+	}
+	return subdomains, liveURLs, nil
+}
+
+// ResumeScanJob re-queues a failed or canceled scan job. The worker that
+// claims it skips every stage that already has a checkpoint.
+func (d *Database) ResumeScanJob(projectID, jobID string) (*ScanJob, error) {
+	var resumed *ScanJob
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var job ScanJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("project_id = ? AND job_id = ?", projectID, jobID).
+			First(&job).Error; err != nil {
+			return err
+		}
+		status := strings.ToLower(strings.TrimSpace(job.Status))
+		if strings.ToLower(strings.TrimSpace(job.Mode)) != "scan" || (status != "failed" && status != "canceled") {
+			return ErrJobNotResumable
+		}
+		if err := tx.Model(&ScanJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":        "pending",
+			"error_message": "",
+			"finished_at":   nil,
+			"worker_id":     "",
+			"lease_until":   nil,
+			"resume_count":  gorm.Expr("resume_count + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		// Stage rows left running by a dead worker would otherwise never close.
+		if err := tx.Model(&ScanStage{}).
+			Where("project_id = ? AND job_id = ? AND status = ?", projectID, jobID, "running").
+			Updates(map[string]interface{}{"status": "interrupted"}).Error; err != nil {
+			return err
+		}
+		job.Status = "pending"
+		job.ResumeCount++
+		resumed = &job
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resumed, nil
+}
+
 // DeleteMonitorTaskHistory removes one finished/canceled monitor task record.
 func (d *Database) DeleteMonitorTaskHistory(projectID string, taskID uint) error {
 	projectID = strings.TrimSpace(projectID)
@@ -1916,35 +2479,61 @@ func (d *Database) DeleteMonitorTaskHistory(projectID string, taskID uint) error
 	})
 }
 
-// ClaimPendingScanJob atomically claims one pending scan job.
-func (d *Database) ClaimPendingScanJob() (*ScanJob, error) {
+// ClaimPendingScanJob claims the oldest pending scan job that worker can run
+// and leases it to worker until leaseTTL from now.
+//
+// A job can run on worker when worker carries all of the job's worker tags
+// and, if any live worker (heartbeat after liveSince) has every plugin the job
+// requires, worker has them too. Jobs no live worker fully covers fall back to
+// any worker so they do not wait forever.
+func (d *Database) ClaimPendingScanJob(worker *Worker, leaseTTL time.Duration, liveSince time.Time) (*ScanJob, error) {
 	var claimed *ScanJob
 	err := d.DB.Transaction(func(tx *gorm.DB) error {
-		var job ScanJob
+		var jobs []ScanJob
 		now := time.Now()
-		result := tx.Model(&ScanJob{}).
+		if err := tx.Model(&ScanJob{}).
 			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
 			Where("mode = ? AND status = ?", "scan", "pending").
 			Order("created_at asc").
-			First(&job)
-		if result.Error == gorm.ErrRecordNotFound {
+			Limit(50).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
 			return nil
 		}
-		if result.Error != nil {
-			return result.Error
+
+		var live []Worker
+		if err := tx.Where("last_heartbeat_at >= ?", liveSince).Find(&live).Error; err != nil {
+			return err
 		}
 
-		if err := tx.Model(&ScanJob{}).
-			Where("id = ? AND status = ?", job.ID, "pending").
-			Updates(map[string]interface{}{
+		for i := range jobs {
+			job := jobs[i]
+			if !workerCanRunJob(worker, &job, live) {
+				continue
+			}
+			updates := map[string]interface{}{
 				"status":     "running",
 				"started_at": now,
-			}).Error; err != nil {
-			return err
+			}
+			if worker != nil {
+				leaseUntil := now.Add(leaseTTL)
+				updates["worker_id"] = worker.WorkerID
+				updates["lease_until"] = leaseUntil
+				job.WorkerID = worker.WorkerID
+				job.LeaseUntil = &leaseUntil
+			}
+			if err := tx.Model(&ScanJob{}).
+				Where("id = ? AND status = ?", job.ID, "pending").
+				Updates(updates).Error; err != nil {
+				return err
+			}
+			job.Status = "running"
+			job.StartedAt = &now
+			claimed = &job
+			return nil
 		}
-		job.Status = "running"
-		job.StartedAt = &now
-		claimed = &job
 		return nil
 	})
 	if err != nil {
@@ -1953,6 +2542,802 @@ func (d *Database) ClaimPendingScanJob() (*ScanJob, error) {
 	return claimed, nil
 }
 
+func workerCanRunJob(worker *Worker, job *ScanJob, live []Worker) bool {
+	if worker == nil {
+		return true
+	}
+	tags := splitCSVSet(job.WorkerTags)
+	if !isSubset(tags, decodeStringSet(worker.Tags)) {
+		return false
+	}
+	requires := splitCSVSet(job.Requires)
+	if len(requires) == 0 || isSubset(requires, decodeStringSet(worker.Capabilities)) {
+		return true
+	}
+	for i := range live {
+		if isSubset(tags, decodeStringSet(live[i].Tags)) && isSubset(requires, decodeStringSet(live[i].Capabilities)) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitCSVSet(raw string) map[string]bool {
+	out := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out[item] = true
+		}
+	}
+	return out
+}
+
+func decodeStringSet(raw JSONB) map[string]bool {
+	var items []string
+	_ = json.Unmarshal(raw, &items)
+	out := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out[item] = true
+		}
+	}
+	return out
+}
+
+func isSubset(want, have map[string]bool) bool {
+	for item := range want {
+		if !have[item] {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterWorker creates or refreshes the registry row of worker.
+func (d *Database) RegisterWorker(worker *Worker) error {
+	if strings.TrimSpace(worker.WorkerID) == "" {
+		return fmt.Errorf("workerID is required")
+	}
+	now := time.Now()
+	worker.LastHeartbeatAt = now
+	return d.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "worker_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"hostname":          worker.Hostname,
+			"region":            worker.Region,
+			"egress_ip":         worker.EgressIP,
+			"local_ip":          worker.LocalIP,
+			"capabilities":      worker.Capabilities,
+			"tags":              worker.Tags,
+			"current_job_id":    "",
+			"started_at":        worker.StartedAt,
+			"last_heartbeat_at": now,
+			"updated_at":        now,
+		}),
+	}).Create(worker).Error
+}
+
+// HeartbeatWorker records a heartbeat and extends the lease of
+// currentJobID. It returns ErrLeaseLost when currentJobID is no longer
+// running under workerID, e.g. after its lease expired and was recovered.
+func (d *Database) HeartbeatWorker(workerID, currentJobID string, leaseTTL time.Duration) error {
+	now := time.Now()
+	renewed := true
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Worker{}).Where("worker_id = ?", workerID).Updates(map[string]interface{}{
+			"current_job_id":    currentJobID,
+			"last_heartbeat_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if currentJobID == "" {
+			return nil
+		}
+		res := tx.Model(&ScanJob{}).
+			Where("job_id = ? AND worker_id = ? AND status = ?", currentJobID, workerID, "running").
+			Update("lease_until", now.Add(leaseTTL))
+		if res.Error != nil {
+			return res.Error
+		}
+		renewed = res.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !renewed {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// RecordAssetSources marks domain as reported by each of sources, creating
+// the rows on first sight and bumping last_seen/hit_count afterwards.
+func (d *Database) RecordAssetSources(projectID, rootDomain, domain, jobID string, sources []string) error {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if strings.TrimSpace(projectID) == "" || domain == "" {
+		return nil
+	}
+	now := time.Now()
+	for _, source := range sources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == "" {
+			continue
+		}
+		row := AssetSource{
+			ProjectID:   projectID,
+			Domain:      domain,
+			Source:      source,
+			RootDomain:  rootDomain,
+			FirstJobID:  jobID,
+			LastJobID:   jobID,
+			HitCount:    1,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		}
+		err := d.DB.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "project_id"}, {Name: "domain"}, {Name: "source"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"last_job_id":  jobID,
+				"hit_count":    gorm.Expr("asset_sources.hit_count + 1"),
+				"last_seen_at": now,
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAssetSources returns the sources of each of domains in projectID,
+// keyed by domain.
+func (d *Database) ListAssetSources(projectID string, domains []string) (map[string][]AssetSource, error) {
+	out := make(map[string][]AssetSource, len(domains))
+	if len(domains) == 0 {
+		return out, nil
+	}
+	var rows []AssetSource
+	if err := d.DB.Where("project_id = ? AND domain IN ?", projectID, domains).
+		Order("first_seen_at asc, source asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.Domain] = append(out[row.Domain], row)
+	}
+	return out, nil
+}
+
+// AssetSourceStat summarises one source's contribution to a project.
+type AssetSourceStat struct {
+	Source      string    `json:"source"`
+	Hosts       int64     `json:"hosts"`
+	UniqueHosts int64     `json:"unique_hosts"`
+	LiveHosts   int64     `json:"live_hosts"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// AssetSourceStats returns, per source, how many hosts it reported, how
+// many no other source reported, and how many of them are live assets.
+// An empty rootDomain covers the whole project.
+func (d *Database) AssetSourceStats(projectID, rootDomain string) ([]AssetSourceStat, int64, error) {
+	scoped := func(q *gorm.DB, table string) *gorm.DB {
+		q = q.Where(table+".project_id = ?", projectID)
+		if rootDomain != "" {
+			q = q.Where(table+".root_domain = ? OR "+table+".domain = ? OR "+table+".domain LIKE ?", rootDomain, rootDomain, "%."+rootDomain)
+		}
+		return q
+	}
+
+	var stats []AssetSourceStat
+	if err := scoped(d.DB.Model(&AssetSource{}), "asset_sources").
+		Select("source, COUNT(*) AS hosts, MIN(first_seen_at) AS first_seen_at, MAX(last_seen_at) AS last_seen_at").
+		Group("source").Order("hosts desc, source asc").Scan(&stats).Error; err != nil {
+		return nil, 0, err
+	}
+
+	type sourceCount struct {
+		Source string
+		Count  int64
+	}
+	var unique []sourceCount
+	single := scoped(d.DB.Model(&AssetSource{}), "asset_sources").
+		Select("domain").Group("domain").Having("COUNT(*) = 1")
+	if err := scoped(d.DB.Model(&AssetSource{}), "asset_sources").
+		Select("source, COUNT(*) AS count").Where("domain IN (?)", single).
+		Group("source").Scan(&unique).Error; err != nil {
+		return nil, 0, err
+	}
+	var live []sourceCount
+	if err := scoped(d.DB.Model(&AssetSource{}), "asset_sources").
+		Select("asset_sources.source, COUNT(*) AS count").
+		Joins("JOIN assets ON assets.project_id = asset_sources.project_id AND assets.domain = asset_sources.domain AND assets.deleted_at IS NULL AND assets.status_code > 0").
+		Group("asset_sources.source").Scan(&live).Error; err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	if err := scoped(d.DB.Model(&AssetSource{}), "asset_sources").
+		Distinct("domain").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	uniqueBySource := make(map[string]int64, len(unique))
+	for _, row := range unique {
+		uniqueBySource[row.Source] = row.Count
+	}
+	liveBySource := make(map[string]int64, len(live))
+	for _, row := range live {
+		liveBySource[row.Source] = row.Count
+	}
+	for i := range stats {
+		stats[i].UniqueHosts = uniqueBySource[stats[i].Source]
+		stats[i].LiveHosts = liveBySource[stats[i].Source]
+	}
+	return stats, total, nil
+}
+
+// ListProjectScopeRules returns the scope rules of projectID in creation
+// order, optionally only the enabled ones.
+func (d *Database) ListProjectScopeRules(projectID string, enabledOnly bool) ([]ProjectScopeRule, error) {
+	q := d.DB.Where("project_id = ?", projectID)
+	if enabledOnly {
+		q = q.Where("enabled = ?", true)
+	}
+	var rules []ProjectScopeRule
+	err := q.Order("id asc").Find(&rules).Error
+	return rules, err
+}
+
+// UpsertWildcardZone records a wildcard zone for projectID, refreshing its
+// fingerprint and adding hits to the dropped counter when it already exists.
+func (d *Database) UpsertWildcardZone(zone *WildcardZone) error {
+	if zone == nil || strings.TrimSpace(zone.ProjectID) == "" || strings.TrimSpace(zone.Zone) == "" {
+		return fmt.Errorf("projectID and zone are required")
+	}
+	now := time.Now()
+	if zone.FirstSeenAt.IsZero() {
+		zone.FirstSeenAt = now
+	}
+	zone.LastSeenAt = now
+	return d.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "zone"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"root_domain":  zone.RootDomain,
+			"ips":          zone.IPs,
+			"cname":        zone.CNAME,
+			"job_id":       zone.JobID,
+			"dropped_hits": gorm.Expr("wildcard_zones.dropped_hits + ?", zone.DroppedHits),
+			"last_seen_at": now,
+		}),
+	}).Create(zone).Error
+}
+
+// DeleteWildcardCandidates removes the pending asset candidates of projectID
+// that turned out to be wildcard hits, together with the source rows jobID
+// created for them. Verified candidates are kept.
+func (d *Database) DeleteWildcardCandidates(projectID, jobID string, domains []string) (int64, error) {
+	if len(domains) == 0 {
+		return 0, nil
+	}
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	var deleted int64
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("project_id = ? AND domain IN ? AND verify_status = ?", projectID, normalized, "pending").
+			Delete(&AssetCandidate{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		return tx.Where("project_id = ? AND domain IN ? AND first_job_id = ?", projectID, normalized, jobID).
+			Delete(&AssetSource{}).Error
+	})
+	return deleted, err
+}
+
+// DNSRecordChange is the difference between the stored records of one type
+// and a new resolution.
+type DNSRecordChange struct {
+	Host       string
+	RecordType string
+	Added      []string
+	Removed    []string
+}
+
+// SyncDNSRecords replaces the stored records of host with records (keyed by
+// record type) and returns what changed. Record types listed in failed were
+// not resolved this time; their stored rows are left untouched. known is
+// false when the host had no stored records, in which case every record
+// counts as added.
+func (d *Database) SyncDNSRecords(projectID, rootDomain, host, jobID string, records map[string][]string, failed []string) (changes []DNSRecordChange, known bool, err error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if strings.TrimSpace(projectID) == "" || host == "" {
+		return nil, false, fmt.Errorf("projectID and host are required")
+	}
+	now := time.Now()
+	err = d.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []DNSRecord
+		if err := tx.Where("project_id = ? AND host = ?", projectID, host).Find(&existing).Error; err != nil {
+			return err
+		}
+		known = len(existing) > 0
+
+		stored := make(map[string]map[string]uint, 6)
+		for _, row := range existing {
+			if stored[row.RecordType] == nil {
+				stored[row.RecordType] = make(map[string]uint)
+			}
+			stored[row.RecordType][row.Value] = row.ID
+		}
+		skip := make(map[string]bool, len(failed))
+		for _, typ := range failed {
+			skip[strings.ToUpper(typ)] = true
+		}
+		types := make([]string, 0, 6)
+		for typ := range records {
+			if !skip[typ] {
+				types = append(types, typ)
+			}
+		}
+		for typ := range stored {
+			if _, ok := records[typ]; !ok && !skip[typ] {
+				types = append(types, typ)
+			}
+		}
+		sort.Strings(types)
+
+		var removedIDs []uint
+		for _, typ := range types {
+			change := DNSRecordChange{Host: host, RecordType: typ}
+			current := make(map[string]bool, len(records[typ]))
+			for _, value := range records[typ] {
+				if value == "" || current[value] {
+					continue
+				}
+				current[value] = true
+				if _, ok := stored[typ][value]; ok {
+					continue
+				}
+				change.Added = append(change.Added, value)
+				row := DNSRecord{
+					ProjectID:   projectID,
+					Host:        host,
+					RecordType:  typ,
+					Value:       value,
+					RootDomain:  rootDomain,
+					JobID:       jobID,
+					FirstSeenAt: now,
+					LastSeenAt:  now,
+				}
+				if err := tx.Create(&row).Error; err != nil {
+					return err
+				}
+			}
+			for value, id := range stored[typ] {
+				if !current[value] {
+					change.Removed = append(change.Removed, value)
+					removedIDs = append(removedIDs, id)
+				}
+			}
+			sort.Strings(change.Removed)
+			if len(change.Added) > 0 || len(change.Removed) > 0 {
+				changes = append(changes, change)
+			}
+		}
+		if len(removedIDs) > 0 {
+			if err := tx.Where("id IN ?", removedIDs).Delete(&DNSRecord{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&DNSRecord{}).
+			Where("project_id = ? AND host = ?", projectID, host).
+			Updates(map[string]interface{}{"last_seen_at": now, "job_id": jobID, "root_domain": rootDomain}).Error
+	})
+	return changes, known, err
+}
+
+// ListDNSRecords returns the DNS records of projectID, optionally limited to
+// one host, hosts under rootDomain, or one record type.
+func (d *Database) ListDNSRecords(projectID, host, rootDomain, recordType string, limit int) ([]DNSRecord, error) {
+	q := d.DB.Where("project_id = ?", projectID)
+	if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+		q = q.Where("host = ?", host)
+	}
+	if rootDomain != "" {
+		q = q.Where("host = ? OR host LIKE ?", rootDomain, "%."+rootDomain)
+	}
+	if recordType = strings.ToUpper(strings.TrimSpace(recordType)); recordType != "" {
+		q = q.Where("record_type = ?", recordType)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var rows []DNSRecord
+	err := q.Order("host asc, record_type asc, value asc").Find(&rows).Error
+	return rows, err
+}
+
+// BruteforceWordStat is the outcome of one word in a bruteforce run.
+type BruteforceWordStat struct {
+	Word   string
+	Hits   int
+	Misses int
+}
+
+// RecordBruteforceWordStats adds the hit and miss counts of a bruteforce run
+// to the project wordlist. Words seen for the first time are stored as
+// learned words.
+func (d *Database) RecordBruteforceWordStats(projectID string, stats []BruteforceWordStat) error {
+	if strings.TrimSpace(projectID) == "" || len(stats) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]BruteforceWord, 0, len(stats))
+	for _, stat := range stats {
+		word := strings.ToLower(strings.TrimSpace(stat.Word))
+		if word == "" || (stat.Hits == 0 && stat.Misses == 0) {
+			continue
+		}
+		row := BruteforceWord{
+			ProjectID: projectID,
+			Word:      word,
+			Source:    BruteforceWordLearned,
+			Hits:      stat.Hits,
+			Misses:    stat.Misses,
+		}
+		if stat.Hits > 0 {
+			row.LastHitAt = &now
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return d.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "word"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"hits":        gorm.Expr("bruteforce_words.hits + excluded.hits"),
+			"misses":      gorm.Expr("bruteforce_words.misses + excluded.misses"),
+			"last_hit_at": gorm.Expr("COALESCE(excluded.last_hit_at, bruteforce_words.last_hit_at)"),
+			"updated_at":  now,
+		}),
+	}).CreateInBatches(&rows, 500).Error
+}
+
+// AddBruteforceWords stores manually uploaded words. Words already learned
+// keep their counters and are marked manual. It returns the number of words
+// written.
+func (d *Database) AddBruteforceWords(projectID string, words []string) (int, error) {
+	seen := make(map[string]bool, len(words))
+	rows := make([]BruteforceWord, 0, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		rows = append(rows, BruteforceWord{ProjectID: projectID, Word: word, Source: BruteforceWordManual})
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	err := d.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "word"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"source": BruteforceWordManual, "updated_at": time.Now()}),
+	}).CreateInBatches(&rows, 500).Error
+	return len(rows), err
+}
+
+// bruteforceWordRank orders words by their smoothed hit rate, so a word
+// with one hit in two tries ranks above an untried word, which ranks above
+// a word that only ever missed.
+const bruteforceWordRank = "(bruteforce_words.hits + 1.0) / (bruteforce_words.hits + bruteforce_words.misses + 2.0) DESC, bruteforce_words.hits DESC, bruteforce_words.word ASC"
+
+// ListBruteforceWords returns the wordlist of projectID ranked by hit rate,
+// optionally limited to one source.
+func (d *Database) ListBruteforceWords(projectID, source string, limit int) ([]BruteforceWord, error) {
+	q := d.DB.Where("project_id = ?", projectID)
+	if source = strings.ToLower(strings.TrimSpace(source)); source != "" {
+		q = q.Where("source = ?", source)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var rows []BruteforceWord
+	err := q.Order(bruteforceWordRank).Find(&rows).Error
+	return rows, err
+}
+
+// DeleteBruteforceWords removes words from the wordlist of projectID.
+func (d *Database) DeleteBruteforceWords(projectID string, words []string) (int64, error) {
+	for i := range words {
+		words[i] = strings.ToLower(strings.TrimSpace(words[i]))
+	}
+	res := d.DB.Where("project_id = ? AND word IN ?", projectID, words).Delete(&BruteforceWord{})
+	return res.RowsAffected, res.Error
+}
+
+// PruneBruteforceWords removes words tried at least minTries times whose hit
+// rate is at or below maxHitRate. Manual words are kept unless
+// includeManual is set.
+func (d *Database) PruneBruteforceWords(projectID string, minTries int, maxHitRate float64, includeManual bool) (int64, error) {
+	if minTries < 1 {
+		minTries = 1
+	}
+	q := d.DB.Where("project_id = ? AND hits + misses >= ? AND hits <= ? * (hits + misses)", projectID, minTries, maxHitRate)
+	if !includeManual {
+		q = q.Where("source <> ?", BruteforceWordManual)
+	}
+	res := q.Delete(&BruteforceWord{})
+	return res.RowsAffected, res.Error
+}
+
+// AddDNSResolvers adds addresses to the managed resolver pool as unchecked
+// servers. Known addresses are left unchanged. It returns the addresses that
+// were new.
+func (d *Database) AddDNSResolvers(addresses []string) ([]string, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	var existing []string
+	if err := d.DB.Model(&DNSResolver{}).Where("address IN ?", addresses).Pluck("address", &existing).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, addr := range existing {
+		known[addr] = true
+	}
+	var added []string
+	for _, addr := range addresses {
+		if known[addr] {
+			continue
+		}
+		known[addr] = true
+		row := DNSResolver{Address: addr, Status: ResolverUnchecked}
+		if err := d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			return added, err
+		}
+		added = append(added, addr)
+	}
+	return added, nil
+}
+
+// ListDNSResolvers returns the managed resolver pool, optionally limited to
+// one status, fastest first.
+func (d *Database) ListDNSResolvers(status string) ([]DNSResolver, error) {
+	q := d.DB.Model(&DNSResolver{})
+	if status = strings.TrimSpace(status); status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var rows []DNSResolver
+	err := q.Order("CASE WHEN status = 'healthy' THEN 0 ELSE 1 END, latency_ms asc, address asc").Find(&rows).Error
+	return rows, err
+}
+
+// RecordDNSResolverCheck stores the outcome of a health check. A failed check
+// increments the consecutive failure count; a passing check resets it.
+func (d *Database) RecordDNSResolverCheck(address string, healthy bool, latencyMs int, checkErr string) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_checked_at": now,
+		"last_error":      checkErr,
+	}
+	if healthy {
+		updates["status"] = ResolverHealthy
+		updates["latency_ms"] = latencyMs
+		updates["failures"] = 0
+	} else {
+		updates["status"] = ResolverUnhealthy
+		updates["failures"] = gorm.Expr("failures + 1")
+	}
+	return d.DB.Model(&DNSResolver{}).Where("address = ?", address).Updates(updates).Error
+}
+
+// DeleteDNSResolvers removes addresses from the managed resolver pool.
+func (d *Database) DeleteDNSResolvers(addresses []string) (int64, error) {
+	if len(addresses) == 0 {
+		return 0, nil
+	}
+	res := d.DB.Where("address IN ?", addresses).Delete(&DNSResolver{})
+	return res.RowsAffected, res.Error
+}
+
+// ListAPIKeys returns the API keys of projectID, or the global keys when
+// projectID is empty. With withGlobal set, global keys are included too.
+func (d *Database) ListAPIKeys(projectID string, withGlobal bool) ([]APIKey, error) {
+	q := d.DB.Model(&APIKey{})
+	if withGlobal && projectID != "" {
+		q = q.Where("project_id = ? OR project_id = ''", projectID)
+	} else {
+		q = q.Where("project_id = ?", projectID)
+	}
+	var rows []APIKey
+	err := q.Order("provider asc, project_id desc, period_usage asc, id asc").Find(&rows).Error
+	return rows, err
+}
+
+// RecordAPIKeyUse counts one use of key id, restarting the monthly counter
+// when a new month has begun.
+func (d *Database) RecordAPIKeyUse(id uint) error {
+	now := time.Now()
+	month := MonthStart(now)
+	return d.DB.Model(&APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"usage_count":  gorm.Expr("usage_count + 1"),
+		"period_usage": gorm.Expr("CASE WHEN period_start IS NOT NULL AND period_start >= ? THEN period_usage + 1 ELSE 1 END", month),
+		"period_start": month,
+		"last_used_at": now,
+	}).Error
+}
+
+// ListProjectIPs returns the distinct IPs seen on the assets and open ports
+// of projectID.
+func (d *Database) ListProjectIPs(projectID string) ([]string, error) {
+	var ips []string
+	err := d.DB.Raw(`SELECT ip FROM assets WHERE project_id = ? AND deleted_at IS NULL AND COALESCE(ip, '') <> ''
+		UNION SELECT ip FROM ports WHERE project_id = ? AND deleted_at IS NULL AND COALESCE(ip, '') <> ''`,
+		projectID, projectID).Scan(&ips).Error
+	return ips, err
+}
+
+// AddNetblockSuggestions stores rows as pending suggestions. Netblocks
+// already suggested keep their review status; only their owner and match
+// count are refreshed. It returns the number of new suggestions.
+func (d *Database) AddNetblockSuggestions(projectID string, rows []NetblockSuggestion) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	cidrs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		cidrs = append(cidrs, row.CIDR)
+	}
+	added := 0
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []NetblockSuggestion
+		if err := tx.Where("project_id = ? AND cidr IN ?", projectID, cidrs).Find(&existing).Error; err != nil {
+			return err
+		}
+		known := make(map[string]uint, len(existing))
+		for _, row := range existing {
+			known[row.CIDR] = row.ID
+		}
+		for _, row := range rows {
+			if id, ok := known[row.CIDR]; ok {
+				if err := tx.Model(&NetblockSuggestion{}).Where("id = ?", id).Updates(map[string]interface{}{
+					"org":         row.Org,
+					"matched_ips": row.MatchedIPs,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			row.ID = 0
+			row.ProjectID = projectID
+			row.Status = NetblockPending
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	return added, err
+}
+
+// ListNetblockSuggestions returns the netblocks suggested for projectID,
+// optionally only those with status.
+func (d *Database) ListNetblockSuggestions(projectID, status string, limit int) ([]NetblockSuggestion, error) {
+	q := d.DB.Where("project_id = ?", projectID)
+	if status = strings.TrimSpace(status); status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var rows []NetblockSuggestion
+	err := q.Order("matched_ips desc, asn asc, cidr asc").Find(&rows).Error
+	return rows, err
+}
+
+// ReviewNetblockSuggestions sets the status of the suggestions ids of
+// projectID and returns the updated rows.
+func (d *Database) ReviewNetblockSuggestions(projectID string, ids []uint, status, reviewer string) ([]NetblockSuggestion, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	if err := d.DB.Model(&NetblockSuggestion{}).Where("project_id = ? AND id IN ?", projectID, ids).Updates(map[string]interface{}{
+		"status":      status,
+		"reviewed_by": reviewer,
+		"reviewed_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	var rows []NetblockSuggestion
+	err := d.DB.Where("project_id = ? AND id IN ?", projectID, ids).Order("cidr asc").Find(&rows).Error
+	return rows, err
+}
+
+// SetMonitorRunDNSChanges stores the number of DNS change events of a run.
+func (d *Database) SetMonitorRunDNSChanges(runID uint, count int) error {
+	return d.DB.Model(&MonitorRun{}).Where("id = ?", runID).Update("dns_changed", count).Error
+}
+
+// SetMonitorRunCertChanges stores the number of certificate events of a run.
+func (d *Database) SetMonitorRunCertChanges(runID uint, count int) error {
+	return d.DB.Model(&MonitorRun{}).Where("id = ?", runID).Update("cert_changed", count).Error
+}
+
+// ListWildcardZones returns the wildcard zones of projectID ordered by zone.
+func (d *Database) ListWildcardZones(projectID string) ([]WildcardZone, error) {
+	var zones []WildcardZone
+	err := d.DB.Where("project_id = ?", projectID).Order("zone asc").Find(&zones).Error
+	return zones, err
+}
+
+// ListWorkers returns all registered workers, most recently seen first.
+func (d *Database) ListWorkers() ([]Worker, error) {
+	var workers []Worker
+	err := d.DB.Order("last_heartbeat_at desc").Find(&workers).Error
+	return workers, err
+}
+
+// RecoverExpiredScanJobLeases fails running scan jobs whose worker stopped
+// renewing their lease.
+func (d *Database) RecoverExpiredScanJobLeases() ([]RecoveredScanJob, error) {
+	recoveredAt := time.Now()
+	var expired []ScanJob
+	if err := d.DB.Model(&ScanJob{}).
+		Where("mode = ? AND status = ? AND lease_until IS NOT NULL AND lease_until < ?", "scan", "running", recoveredAt).
+		Find(&expired).Error; err != nil {
+		return nil, err
+	}
+	recovered := make([]RecoveredScanJob, 0, len(expired))
+	for _, job := range expired {
+		errMsg := fmt.Sprintf("scan job lease expired (worker=%s, lease_until=%s, recovered_at=%s)",
+			job.WorkerID, formatTimeRFC3339OrUnknown(job.LeaseUntil), recoveredAt.Format(time.RFC3339))
+		result := d.DB.Model(&ScanJob{}).
+			Where("id = ? AND status = ? AND lease_until < ?", job.ID, "running", recoveredAt).
+			Updates(map[string]interface{}{
+				"status":        "failed",
+				"finished_at":   recoveredAt,
+				"error_message": errMsg,
+			})
+		if result.Error != nil {
+			return recovered, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		item := RecoveredScanJob{
+			ProjectID:    job.ProjectID,
+			JobID:        job.JobID,
+			RootDomain:   job.RootDomain,
+			StartedAt:    job.StartedAt,
+			JobUpdatedAt: job.UpdatedAt,
+			CutoffAt:     recoveredAt,
+			RecoveredAt:  recoveredAt,
+			ErrorMessage: errMsg,
+		}
+		var stage ScanStage
+		if err := d.DB.Where("project_id = ? AND job_id = ?", job.ProjectID, job.JobID).Order("updated_at desc").Take(&stage).Error; err == nil {
+			item.LastStage = strings.TrimSpace(stage.Stage)
+			item.LastStageStatus = strings.TrimSpace(stage.Status)
+			if !stage.UpdatedAt.IsZero() {
+				t := stage.UpdatedAt
+				item.LastStageUpdatedAt = &t
+			}
+			item.LastStageError = strings.TrimSpace(stage.Error)
+		}
+		recovered = append(recovered, item)
+	}
+	return recovered, nil
+}
+
 type RecoveredScanJob struct {
 	ProjectID          string
 	JobID              string
@@ -1988,6 +3373,7 @@ func (d *Database) RecoverStaleRunningScanJobsDetailed(staleAfter time.Duration)
 	var staleJobs []ScanJob
 	if err := d.DB.Model(&ScanJob{}).
 		Where("mode = ? AND status = ? AND started_at IS NOT NULL AND started_at <= ?", "scan", "running", cutoff).
+		Where("lease_until IS NULL OR lease_until < ?", recoveredAt).
 		Order("started_at asc").
 		Find(&staleJobs).Error; err != nil {
 		return nil, err
@@ -2099,6 +3485,42 @@ func getIntValue(data map[string]interface{}, key string) int {
 	return 0
 }
 
+func getBoolValue(data map[string]interface{}, key string) bool {
+	value, _ := data[key].(bool)
+	return value
+}
+
+// getStringsValue accepts string lists as produced by plugins ([]string) or
+// decoded from JSON ([]interface{}).
+func getStringsValue(data map[string]interface{}, key string) []string {
+	out := []string{}
+	switch v := data[key].(type) {
+	case []string:
+		out = append(out, v...)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// getTimeValue accepts a time.Time or an RFC 3339 string, as found in
+// results restored from checkpoints.
+func getTimeValue(data map[string]interface{}, key string) time.Time {
+	switch v := data[key].(type) {
+	case time.Time:
+		return v.UTC()
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(v)); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
 func defaultProtocol(v string) string {
 	p := strings.TrimSpace(strings.ToLower(v))
 	if p == "" {
@@ -53,6 +53,7 @@ type Asset struct {
 	Technologies JSONB          `gorm:"type:jsonb" json:"technologies"`
 	SourceJobID  string         `gorm:"index" json:"source_job_id"`
 	SourceModule string         `gorm:"index" json:"source_module"`
+	DiscoveryHop int            `gorm:"not null;default:0" json:"discovery_hop"` // recursive hop that first found the asset, 0 = direct
 	FirstSeenAt  time.Time      `json:"first_seen_at"`
 	LastSeen     time.Time      `json:"last_seen"`
 	CreatedAt    time.Time      `json:"created_at"`
@@ -66,6 +67,73 @@ func (Asset) TableName() string {
 	return "assets"
 }
 
+// WebResponse holds the HTTP response metadata of one probed URL of an
+// asset. Rows are keyed by asset and URL and overwritten by each probe.
+type WebResponse struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	ProjectID     string    `gorm:"index;not null" json:"project_id"`
+	AssetID       uint      `gorm:"uniqueIndex:idx_web_responses_asset_url;not null" json:"asset_id"`
+	Domain        string    `gorm:"index" json:"domain"`
+	URL           string    `gorm:"uniqueIndex:idx_web_responses_asset_url;type:text;not null" json:"url"`
+	FinalURL      string    `gorm:"type:text" json:"final_url"`
+	Location      string    `gorm:"type:text" json:"location"` // Location header of the first response
+	StatusCode    int       `json:"status_code"`
+	ContentType   string    `json:"content_type"`
+	ContentLength int       `json:"content_length"`
+	BodySHA256    string    `gorm:"index;size:64" json:"body_sha256"`
+	Server        string    `gorm:"index" json:"server"`
+	CDN           string    `gorm:"index" json:"cdn"`
+	Headers       JSONB     `gorm:"type:jsonb" json:"headers"`
+	ResponseTime  int       `json:"response_time_ms"`
+	FaviconMMH3   string    `gorm:"index" json:"favicon_mmh3"`
+	FaviconMD5    string    `gorm:"size:32" json:"favicon_md5"`
+	FaviconURL    string    `gorm:"type:text" json:"favicon_url"`
+	SourceJobID   string    `json:"source_job_id"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+func (WebResponse) TableName() string {
+	return "web_responses"
+}
+
+// Certificate is the TLS leaf certificate last seen on one host:port of a
+// project. Host is the name sent as SNI, or the IP for ports scanned by
+// address. Rows on port 0 come from Certificate Transparency logs (Source
+// crtsh or certspotter) and hold the newest certificate logged for Host.
+type Certificate struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	ProjectID          string    `gorm:"uniqueIndex:idx_certificates_project_host_port;not null" json:"project_id"`
+	RootDomain         string    `gorm:"index" json:"root_domain"`
+	AssetID            uint      `gorm:"index" json:"asset_id"` // 0 when the host has no asset yet
+	PortID             uint      `gorm:"index" json:"port_id"`  // 0 when the port was not scanned
+	Host               string    `gorm:"uniqueIndex:idx_certificates_project_host_port;not null" json:"host"`
+	Port               int       `gorm:"uniqueIndex:idx_certificates_project_host_port;not null" json:"port"`
+	IP                 string    `json:"ip"`
+	URL                string    `gorm:"type:text" json:"url"`
+	Subject            string    `gorm:"type:text" json:"subject"`
+	SubjectCN          string    `gorm:"index" json:"subject_cn"`
+	Issuer             string    `gorm:"type:text" json:"issuer"`
+	IssuerCN           string    `json:"issuer_cn"`
+	SANs               JSONB     `gorm:"type:jsonb" json:"sans"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `gorm:"index" json:"not_after"`
+	Serial             string    `json:"serial"`
+	KeyType            string    `json:"key_type"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	FingerprintSHA256  string    `gorm:"index;size:64" json:"fingerprint_sha256"`
+	SelfSigned         bool      `gorm:"index" json:"self_signed"`
+	Source             string    `json:"source"` // plugin that last captured it
+	SourceJobID        string    `json:"source_job_id"`
+	FirstSeenAt        time.Time `json:"first_seen_at"`
+	ChangedAt          time.Time `json:"changed_at"` // when the fingerprint last changed
+	LastSeen           time.Time `json:"last_seen"`
+}
+
+func (Certificate) TableName() string {
+	return "certificates"
+}
+
 // AssetCandidate stores discovered-but-not-yet-verified asset candidates.
 // A candidate becomes verified after at least one successful active signal
 // (e.g. httpx live URL or open port).
@@ -94,6 +162,25 @@ func (AssetCandidate) TableName() string {
 	return "asset_candidates"
 }
 
+// AssetSource records that a source (subfinder, chaos, dnsx_bruteforce,
+// ctlogs, ...) reported a host. A host has one row per source.
+type AssetSource struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	ProjectID   string    `gorm:"index:idx_asset_sources_project_domain_source,unique;not null" json:"project_id"`
+	Domain      string    `gorm:"index:idx_asset_sources_project_domain_source,unique;not null" json:"domain"`
+	Source      string    `gorm:"index:idx_asset_sources_project_domain_source,unique;size:64;not null" json:"source"`
+	RootDomain  string    `gorm:"index" json:"root_domain"`
+	FirstJobID  string    `json:"first_job_id"`
+	LastJobID   string    `json:"last_job_id"`
+	HitCount    int       `gorm:"not null;default:1" json:"hit_count"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+func (AssetSource) TableName() string {
+	return "asset_sources"
+}
+
 // Port port model.
 type Port struct {
 	ID           uint           `gorm:"primarykey" json:"id"`
@@ -109,6 +196,7 @@ type Port struct {
 	Banner       string         `json:"banner"`
 	SourceJobID  string         `gorm:"index" json:"source_job_id"`
 	SourceModule string         `gorm:"index" json:"source_module"`
+	DiscoveryHop int            `gorm:"not null;default:0" json:"discovery_hop"` // recursive hop that first found the port, 0 = direct
 	FirstSeenAt  time.Time      `json:"first_seen_at"`
 	LastSeen     time.Time      `json:"last_seen"`
 	CreatedAt    time.Time      `json:"created_at"`
@@ -150,6 +238,7 @@ type Vulnerability struct {
 	ReopenCount      int            `json:"reopen_count"`
 	LastTransitionAt *time.Time     `json:"last_transition_at"`
 	SourceJobID      string         `gorm:"index" json:"source_job_id"`
+	DiscoveryHop     int            `gorm:"not null;default:0" json:"discovery_hop"` // recursive hop that first found the finding, 0 = direct
 	Raw              JSONB          `gorm:"type:jsonb" json:"raw"`
 	FirstSeenAt      time.Time      `json:"first_seen_at"`
 	LastSeen         time.Time      `json:"last_seen"`
@@ -178,6 +267,8 @@ type MonitorRun struct {
 	PortOpened    int            `json:"port_opened_count"`
 	PortClosed    int            `json:"port_closed_count"`
 	ServiceChange int            `json:"service_changed_count"`
+	DNSChanged    int            `json:"dns_changed_count"`
+	CertChanged   int            `json:"cert_changed_count"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
@@ -329,6 +420,7 @@ type ScanJob struct {
 	RootDomain   string         `gorm:"index;not null" json:"root_domain"`
 	Mode         string         `gorm:"not null" json:"mode"`         // scan or monitor
 	Modules      string         `gorm:"type:text" json:"modules"`     // comma-separated
+	Pipeline     string         `gorm:"type:text" json:"pipeline"`    // named DAG pipeline; empty for module-based jobs
 	Status       string         `gorm:"index;not null" json:"status"` // pending/running/success/failed/canceled
 	EnableNuclei bool           `json:"enable_nuclei"`
 	ActiveSubs   bool           `json:"active_subs"`
@@ -336,6 +428,12 @@ type ScanJob struct {
 	DNSResolvers string         `gorm:"type:text" json:"dns_resolvers"`
 	DryRun       bool           `json:"dry_run"`
 	Notify       bool           `gorm:"default:true" json:"notify"`
+	ResumeCount  int            `gorm:"not null;default:0" json:"resume_count"`
+	Requires     string         `gorm:"type:text" json:"requires"`               // comma-separated plugin names the job runs
+	WorkerTags   string         `gorm:"type:text" json:"worker_tags"`            // comma-separated tags a worker must carry
+	RecurseDepth int            `gorm:"not null;default:0" json:"recurse_depth"` // recursive discovery hops, 0 = off
+	WorkerID     string         `gorm:"index" json:"worker_id"`
+	LeaseUntil   *time.Time     `gorm:"index" json:"lease_until"`
 	ErrorMessage string         `gorm:"type:text" json:"error_message"`
 	DurationSec  int            `json:"duration_sec"`
 	SubdomainCnt int            `json:"subdomain_cnt"`
@@ -353,6 +451,28 @@ func (ScanJob) TableName() string {
 	return "scan_jobs"
 }
 
+// Worker stores one registered execution worker and its last heartbeat.
+type Worker struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	WorkerID        string    `gorm:"uniqueIndex;not null" json:"worker_id"`
+	Hostname        string    `json:"hostname"`
+	Region          string    `gorm:"index" json:"region"`
+	EgressIP        string    `json:"egress_ip"`                      // public egress IP, from WORKER_EGRESS_IP
+	LocalIP         string    `json:"local_ip"`                       // source address of the default route
+	Capabilities    JSONB     `gorm:"type:jsonb" json:"capabilities"` // plugin names usable on this worker
+	Tags            JSONB     `gorm:"type:jsonb" json:"tags"`
+	CurrentJobID    string    `json:"current_job_id"`
+	StartedAt       time.Time `json:"started_at"`
+	LastHeartbeatAt time.Time `gorm:"index" json:"last_heartbeat_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName table name.
+func (Worker) TableName() string {
+	return "workers"
+}
+
 // MonitorTask stores scheduled monitor jobs.
 type MonitorTask struct {
 	ID          uint           `gorm:"primarykey" json:"id"`
@@ -378,18 +498,21 @@ func (MonitorTask) TableName() string {
 
 // Project stores project metadata and ownership.
 type Project struct {
-	ID          string         `gorm:"primaryKey;size:64" json:"id"`
-	Name        string         `gorm:"index;not null" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	Owner       string         `gorm:"index" json:"owner"`
-	Tags        JSONB          `gorm:"type:jsonb" json:"tags"`
-	Archived    bool           `gorm:"index;default:false" json:"archived"`
-	AIEnabled   bool           `gorm:"index;default:true" json:"ai_enabled"`
-	LastScanAt  *time.Time     `json:"last_scan_at"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	Scopes      []ProjectScope `gorm:"foreignKey:ProjectID" json:"scopes"`
+	ID                 string         `gorm:"primaryKey;size:64" json:"id"`
+	Name               string         `gorm:"index;not null" json:"name"`
+	Description        string         `gorm:"type:text" json:"description"`
+	Owner              string         `gorm:"index" json:"owner"`
+	Tags               JSONB          `gorm:"type:jsonb" json:"tags"`
+	Archived           bool           `gorm:"index;default:false" json:"archived"`
+	AIEnabled          bool           `gorm:"index;default:true" json:"ai_enabled"`
+	HostRateLimitRPS   float64        `gorm:"not null;default:0" json:"host_rate_limit_rps"`
+	HostMaxConcurrency int            `gorm:"not null;default:0" json:"host_max_concurrency"`
+	WildcardFilter     bool           `gorm:"not null;default:true" json:"wildcard_filter"`
+	LastScanAt         *time.Time     `json:"last_scan_at"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+	Scopes             []ProjectScope `gorm:"foreignKey:ProjectID" json:"scopes"`
 }
 
 func (Project) TableName() string {
@@ -411,6 +534,179 @@ func (ProjectScope) TableName() string {
 	return "project_scopes"
 }
 
+// ProjectScopeRule refines a project's root-domain scope with include and
+// exclude patterns. Kind is domain, wildcard, regex or cidr.
+type ProjectScopeRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ProjectID string    `gorm:"index;not null" json:"project_id"`
+	Action    string    `gorm:"size:16;not null" json:"action"`
+	Kind      string    `gorm:"size:16;not null" json:"kind"`
+	Pattern   string    `gorm:"not null" json:"pattern"`
+	Note      string    `gorm:"type:text" json:"note"`
+	Enabled   bool      `gorm:"index;default:true" json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ProjectScopeRule) TableName() string {
+	return "project_scope_rules"
+}
+
+// WildcardZone records a parent zone detected to answer random labels, so
+// names under it only count when they resolve differently.
+type WildcardZone struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	ProjectID   string    `gorm:"index:idx_wildcard_zones_project_zone,unique;not null" json:"project_id"`
+	Zone        string    `gorm:"index:idx_wildcard_zones_project_zone,unique;not null" json:"zone"`
+	RootDomain  string    `gorm:"index" json:"root_domain"`
+	IPs         JSONB     `gorm:"type:jsonb" json:"ips"`
+	CNAME       string    `json:"cname"`
+	JobID       string    `gorm:"index" json:"job_id"`
+	DroppedHits int       `gorm:"not null;default:0" json:"dropped_hits"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+func (WildcardZone) TableName() string {
+	return "wildcard_zones"
+}
+
+// DNSRecord is one record of a host's DNS inventory. The rows of a host are
+// replaced by each resolution, so they always hold the latest answer.
+type DNSRecord struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	ProjectID   string    `gorm:"index:idx_dns_records_project_host;not null" json:"project_id"`
+	Host        string    `gorm:"index:idx_dns_records_project_host;not null" json:"host"`
+	RecordType  string    `gorm:"index;size:8;not null" json:"record_type"`
+	Value       string    `gorm:"type:text;not null" json:"value"`
+	RootDomain  string    `gorm:"index" json:"root_domain"`
+	JobID       string    `json:"job_id"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+func (DNSRecord) TableName() string {
+	return "dns_records"
+}
+
+// Bruteforce word sources.
+const (
+	BruteforceWordLearned = "learned"
+	BruteforceWordManual  = "manual"
+)
+
+// BruteforceWord is one word of a project's bruteforce wordlist. Hits and
+// Misses count the root domains for which <word>.<root> did or did not
+// resolve during active bruteforce; manual words are uploaded by users.
+type BruteforceWord struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	ProjectID string     `gorm:"index:idx_bruteforce_words_project_word,unique;not null" json:"project_id"`
+	Word      string     `gorm:"index:idx_bruteforce_words_project_word,unique;size:128;not null" json:"word"`
+	Source    string     `gorm:"size:16;not null;default:learned" json:"source"`
+	Hits      int        `gorm:"not null;default:0" json:"hits"`
+	Misses    int        `gorm:"not null;default:0" json:"misses"`
+	LastHitAt *time.Time `json:"last_hit_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (BruteforceWord) TableName() string {
+	return "bruteforce_words"
+}
+
+// DNS resolver health states.
+const (
+	ResolverUnchecked = "unchecked"
+	ResolverHealthy   = "healthy"
+	ResolverUnhealthy = "unhealthy"
+)
+
+// DNSResolver is one upstream server of the managed resolver pool. Failures
+// counts consecutive failed health checks.
+type DNSResolver struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	Address       string     `gorm:"uniqueIndex;size:64;not null" json:"address"`
+	Status        string     `gorm:"index;size:16;not null;default:unchecked" json:"status"`
+	LatencyMs     int        `json:"latency_ms"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	LastCheckedAt *time.Time `json:"last_checked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (DNSResolver) TableName() string {
+	return "dns_resolvers"
+}
+
+// APIKey is a passive-source credential. Secret holds the encrypted key and
+// Hint its last characters for display. Keys with an empty ProjectID are
+// global; project keys replace the global keys of the same provider.
+// PeriodUsage counts uses in the calendar month starting at PeriodStart.
+type APIKey struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	ProjectID    string     `gorm:"index;not null;default:''" json:"project_id"`
+	Provider     string     `gorm:"index;size:32;not null" json:"provider"`
+	Label        string     `json:"label"`
+	Secret       string     `gorm:"type:text;not null" json:"-"`
+	Hint         string     `gorm:"size:16" json:"hint"`
+	Enabled      bool       `gorm:"not null" json:"enabled"`
+	MonthlyLimit int        `gorm:"not null;default:0" json:"monthly_limit"`
+	UsageCount   int64      `gorm:"not null;default:0" json:"usage_count"`
+	PeriodUsage  int        `gorm:"not null;default:0" json:"period_usage"`
+	PeriodStart  *time.Time `json:"period_start"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Exhausted reports whether the key used up its monthly limit at now.
+func (k APIKey) Exhausted(now time.Time) bool {
+	if k.MonthlyLimit <= 0 || k.PeriodStart == nil || k.PeriodStart.Before(MonthStart(now)) {
+		return false
+	}
+	return k.PeriodUsage >= k.MonthlyLimit
+}
+
+// MonthStart returns the first instant of the calendar month of t.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// Netblock suggestion review states.
+const (
+	NetblockPending  = "pending"
+	NetblockApproved = "approved"
+	NetblockRejected = "rejected"
+)
+
+// NetblockSuggestion is a prefix announced by an AS whose owner matches the
+// project's organisation. Approved netblocks are in scope and their
+// addresses are port scanned as IP-only targets. MatchedIPs counts project
+// asset IPs inside the prefix when it was suggested.
+type NetblockSuggestion struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	ProjectID  string     `gorm:"index:idx_netblock_suggestions_project_cidr,unique;not null" json:"project_id"`
+	CIDR       string     `gorm:"column:cidr;index:idx_netblock_suggestions_project_cidr,unique;size:64;not null" json:"cidr"`
+	ASN        uint32     `gorm:"column:asn;index;not null" json:"asn"`
+	Org        string     `json:"org"`
+	Country    string     `gorm:"size:8" json:"country"`
+	Status     string     `gorm:"index;size:16;not null;default:pending" json:"status"`
+	MatchedIPs int        `gorm:"column:matched_ips;not null;default:0" json:"matched_ips"`
+	ReviewedBy string     `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (NetblockSuggestion) TableName() string {
+	return "netblock_suggestions"
+}
+
 // AppSetting stores JSON settings payloads keyed by name.
 type AppSetting struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -480,6 +776,7 @@ type ScanStage struct {
 	InputCount  int            `json:"input_count"`
 	OutputCount int            `json:"output_count"`
 	Error       string         `gorm:"type:text" json:"error"`
+	Output      JSONB          `gorm:"type:jsonb" json:"-"` // checkpointed stage output used for resume
 	StartedAt   *time.Time     `json:"started_at"`
 	FinishedAt  *time.Time     `json:"finished_at"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -0,0 +1,24 @@
+package engine
+
+// CheckpointStore persists the output of completed pipeline stages so an
+// interrupted job can resume without re-running them.
+type CheckpointStore interface {
+	// Load returns the stored results of stage and whether a checkpoint exists.
+	Load(stage string) ([]Result, bool)
+	// Save records the results of a successfully completed stage.
+	Save(stage string, inputCount int, results []Result)
+}
+
+func loadCheckpoint(store CheckpointStore, stage string) ([]Result, bool) {
+	if store == nil {
+		return nil, false
+	}
+	return store.Load(stage)
+}
+
+func saveCheckpoint(store CheckpointStore, stage string, inputCount int, results []Result) {
+	if store == nil {
+		return
+	}
+	store.Save(stage, inputCount, results)
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Passive-source credential providers.
+const (
+	ProviderChaos          = "chaos"
+	ProviderShodan         = "shodan"
+	ProviderSecurityTrails = "securitytrails"
+	ProviderVirusTotal     = "virustotal"
+	ProviderCensys         = "censys"
+	ProviderCertspotter    = "certspotter"
+	ProviderGitHub         = "github"
+	ProviderBinaryEdge     = "binaryedge"
+)
+
+// CredentialProviders lists the providers API keys can be managed for.
+var CredentialProviders = []string{
+	ProviderChaos, ProviderShodan, ProviderSecurityTrails, ProviderVirusTotal,
+	ProviderCensys, ProviderCertspotter, ProviderGitHub, ProviderBinaryEdge,
+}
+
+// IsCredentialProvider reports whether name is a known provider.
+func IsCredentialProvider(name string) bool {
+	for _, p := range CredentialProviders {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Credential is one API key handed to scanners. Censys keys are "id:secret".
+type Credential struct {
+	ID       uint
+	Provider string
+	Value    string
+}
+
+// Credentials holds the API key of each provider for one job. OnUse, when
+// set, is called every time a scanner takes a key.
+type Credentials struct {
+	keys  map[string]Credential
+	onUse func(Credential)
+}
+
+// NewCredentials keeps the first key of each provider in keys.
+func NewCredentials(keys []Credential, onUse func(Credential)) *Credentials {
+	c := &Credentials{keys: make(map[string]Credential, len(keys)), onUse: onUse}
+	for _, key := range keys {
+		if key.Value == "" {
+			continue
+		}
+		if _, ok := c.keys[key.Provider]; !ok {
+			c.keys[key.Provider] = key
+		}
+	}
+	return c
+}
+
+// Providers returns the providers that have a key, sorted.
+func (c *Credentials) Providers() []string {
+	if c == nil {
+		return nil
+	}
+	out := make([]string, 0, len(c.keys))
+	for p := range c.keys {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Key returns the key of provider without reporting a use. Callers that
+// hand the key to a tool later report it with Use once the tool runs.
+func (c *Credentials) Key(provider string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	key, ok := c.keys[provider]
+	return key.Value, ok
+}
+
+// Use returns the key of provider and reports the use.
+func (c *Credentials) Use(provider string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	key, ok := c.keys[provider]
+	if !ok {
+		return "", false
+	}
+	if c.onUse != nil {
+		c.onUse(key)
+	}
+	return key.Value, true
+}
+
+type credentialsKey struct{}
+
+// WithCredentials returns a context whose scanners use creds.
+func WithCredentials(ctx context.Context, creds *Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, creds)
+}
+
+// CredentialsFromContext returns the credentials attached to ctx, or nil.
+func CredentialsFromContext(ctx context.Context) *Credentials {
+	creds, _ := ctx.Value(credentialsKey{}).(*Credentials)
+	return creds
+}
+
+// APIKey returns the managed key of provider from ctx, falling back to the
+// first non-empty environment variable of envKeys.
+func APIKey(ctx context.Context, provider string, envKeys ...string) string {
+	if key, ok := CredentialsFromContext(ctx).Use(provider); ok {
+		return key
+	}
+	for _, env := range envKeys {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,467 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PipelineInputNode is the implicit source node that carries the job input.
+const PipelineInputNode = "input"
+
+// Edge formats control how upstream results are rendered as scanner input.
+const (
+	EdgeFormatHost       = "host"         // bare hostname
+	EdgeFormatURL        = "url"          // plain URL
+	EdgeFormatURLRoot    = "url_root"     // "url|rootDomain", used by vuln/screenshot scanners
+	EdgeFormatHostPort   = "host_port"    // "host:port", used to probe alt-web ports
+	EdgeFormatIPPortHost = "ip_port_host" // "ip:port:host", used by fingerprinting scanners
+)
+
+// PipelineDefinition describes a named scan flow as a DAG of scanner nodes.
+type PipelineDefinition struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Nodes       []PipelineNode `json:"nodes"`
+}
+
+// PipelineNode is one scanner invocation inside a pipeline definition.
+type PipelineNode struct {
+	ID      string            `json:"id"`
+	Scanner string            `json:"scanner"`
+	Options map[string]string `json:"options,omitempty"`
+	Inputs  []PipelineEdge    `json:"inputs,omitempty"`
+}
+
+// PipelineEdge routes results of an upstream node into a downstream node.
+// Types limits which result types pass, Where filters on result fields
+// (any listed value matches) and Format selects the input rendering.
+type PipelineEdge struct {
+	From   string              `json:"from"`
+	Types  []string            `json:"types,omitempty"`
+	Format string              `json:"format,omitempty"`
+	Where  map[string][]string `json:"where,omitempty"`
+}
+
+// ScannerResolver builds the scanner for a pipeline node.
+type ScannerResolver func(node PipelineNode) (Scanner, error)
+
+// Validate checks node ids, edge references and that the graph is acyclic.
+func (d PipelineDefinition) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return fmt.Errorf("pipeline name is required")
+	}
+	if len(d.Nodes) == 0 {
+		return fmt.Errorf("pipeline %s has no nodes", d.Name)
+	}
+	ids := make(map[string]bool, len(d.Nodes))
+	for _, node := range d.Nodes {
+		id := strings.TrimSpace(node.ID)
+		if id == "" {
+			return fmt.Errorf("pipeline %s: node id is required", d.Name)
+		}
+		if id == PipelineInputNode {
+			return fmt.Errorf("pipeline %s: node id %q is reserved", d.Name, PipelineInputNode)
+		}
+		if ids[id] {
+			return fmt.Errorf("pipeline %s: duplicate node id %s", d.Name, id)
+		}
+		if strings.TrimSpace(node.Scanner) == "" {
+			return fmt.Errorf("pipeline %s: node %s has no scanner", d.Name, id)
+		}
+		ids[id] = true
+	}
+	for _, node := range d.Nodes {
+		for _, edge := range node.Inputs {
+			from := strings.TrimSpace(edge.From)
+			if from != PipelineInputNode && !ids[from] {
+				return fmt.Errorf("pipeline %s: node %s reads from unknown node %q", d.Name, node.ID, edge.From)
+			}
+			switch edge.Format {
+			case "", EdgeFormatHost, EdgeFormatURL, EdgeFormatURLRoot, EdgeFormatHostPort, EdgeFormatIPPortHost:
+			default:
+				return fmt.Errorf("pipeline %s: node %s uses unknown edge format %q", d.Name, node.ID, edge.Format)
+			}
+		}
+	}
+	_, err := d.levels()
+	return err
+}
+
+// levels groups nodes into waves; every node only depends on earlier waves.
+func (d PipelineDefinition) levels() ([][]PipelineNode, error) {
+	indegree := make(map[string]int, len(d.Nodes))
+	children := make(map[string][]string)
+	byID := make(map[string]PipelineNode, len(d.Nodes))
+	for _, node := range d.Nodes {
+		byID[node.ID] = node
+		indegree[node.ID] += 0
+		seen := make(map[string]bool)
+		for _, edge := range node.Inputs {
+			from := strings.TrimSpace(edge.From)
+			if from == PipelineInputNode || seen[from] {
+				continue
+			}
+			seen[from] = true
+			indegree[node.ID]++
+			children[from] = append(children[from], node.ID)
+		}
+	}
+
+	var out [][]PipelineNode
+	var ready []string
+	for _, node := range d.Nodes {
+		if indegree[node.ID] == 0 {
+			ready = append(ready, node.ID)
+		}
+	}
+	visited := 0
+	for len(ready) > 0 {
+		wave := make([]PipelineNode, 0, len(ready))
+		var next []string
+		for _, id := range ready {
+			wave = append(wave, byID[id])
+			visited++
+			for _, child := range children[id] {
+				indegree[child]--
+				if indegree[child] == 0 {
+					next = append(next, child)
+				}
+			}
+		}
+		sort.Strings(next)
+		out = append(out, wave)
+		ready = next
+	}
+	if visited != len(d.Nodes) {
+		return nil, fmt.Errorf("pipeline %s contains a cycle", d.Name)
+	}
+	return out, nil
+}
+
+// ScannerNames returns the distinct scanner names referenced by the pipeline.
+func (d PipelineDefinition) ScannerNames() []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(d.Nodes))
+	for _, node := range d.Nodes {
+		name := strings.ToLower(strings.TrimSpace(node.Scanner))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// DAGPipeline executes a PipelineDefinition.
+type DAGPipeline struct {
+	def           PipelineDefinition
+	resolve       ScannerResolver
+	resultHandler ResultHandler
+	checkpoints   CheckpointStore
+	scope         *Scope
+}
+
+// NewDAGPipeline validates def and returns an executable pipeline.
+func NewDAGPipeline(def PipelineDefinition, resolve ScannerResolver) (*DAGPipeline, error) {
+	if resolve == nil {
+		return nil, fmt.Errorf("scanner resolver is required")
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &DAGPipeline{def: def, resolve: resolve}, nil
+}
+
+// SetResultHandler registers a callback that receives results as they arrive.
+func (p *DAGPipeline) SetResultHandler(handler ResultHandler) {
+	p.resultHandler = handler
+}
+
+// SetCheckpointStore enables per-node checkpoints keyed by node id.
+func (p *DAGPipeline) SetCheckpointStore(store CheckpointStore) {
+	p.checkpoints = store
+}
+
+// SetScope drops out-of-scope hosts from node outputs so they never reach
+// downstream nodes.
+func (p *DAGPipeline) SetScope(scope *Scope) {
+	p.scope = scope
+}
+
+func (p *DAGPipeline) emit(result Result) {
+	if p.resultHandler != nil {
+		p.resultHandler(result)
+	}
+}
+
+// Execute runs every node wave by wave. Nodes in the same wave run in
+// parallel. Missing tools are skipped; other node failures starve their
+// downstream nodes and are returned together once the graph has finished.
+func (p *DAGPipeline) Execute(ctx context.Context, input []string) ([]Result, error) {
+	levels, err := p.def.levels()
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string][]Result{PipelineInputNode: inputAsResults(input)}
+	var allResults []Result
+	var nodeErrs []error
+	seenDomains := make(map[string]bool)
+
+	for _, wave := range levels {
+		if ctx.Err() != nil {
+			nodeErrs = append(nodeErrs, ctx.Err())
+			break
+		}
+
+		var wg sync.WaitGroup
+		waveResults := make([]scannerResult, len(wave))
+		for i, node := range wave {
+			nodeInput := p.collectNodeInput(node, outputs)
+			wg.Add(1)
+			go func(i int, node PipelineNode, nodeInput []string) {
+				defer wg.Done()
+				waveResults[i] = p.runNode(ctx, node, nodeInput)
+			}(i, node, nodeInput)
+		}
+		wg.Wait()
+
+		for i, node := range wave {
+			sr := waveResults[i]
+			allResults = append(allResults, sr.statuses...)
+			if sr.err != nil {
+				if strings.Contains(sr.err.Error(), "not found in PATH") {
+					fmt.Printf("[WARN] [%s] tool not found in PATH, skipped\n", sr.name)
+				} else {
+					fmt.Printf("[WARN] [%s] pipeline node %s failed: %v\n", sr.name, node.ID, sr.err)
+					nodeErrs = append(nodeErrs, fmt.Errorf("node %s (%s): %w", node.ID, sr.name, sr.err))
+				}
+			}
+			nodeResults := sr.results
+			if p.scope != nil {
+				nodeResults = make([]Result, 0, len(sr.results))
+				var rejected []string
+				for _, result := range sr.results {
+					if ok, reason := p.scope.CheckResult(result); !ok {
+						rejected = append(rejected, resultTarget(result)+" ("+reason+")")
+						continue
+					}
+					nodeResults = append(nodeResults, result)
+				}
+				logOutOfScope("node "+node.ID, rejected)
+			}
+			outputs[node.ID] = nodeResults
+			for _, result := range nodeResults {
+				if result.Type == "domain" {
+					domain, _ := result.Data.(string)
+					if domain == "" || seenDomains[domain] {
+						continue
+					}
+					seenDomains[domain] = true
+				}
+				allResults = append(allResults, result)
+			}
+		}
+	}
+
+	return allResults, errors.Join(nodeErrs...)
+}
+
+func (p *DAGPipeline) runNode(ctx context.Context, node PipelineNode, input []string) scannerResult {
+	stage := "node:" + node.ID
+	if restored, ok := loadCheckpoint(p.checkpoints, stage); ok {
+		fmt.Printf("[Checkpoint] node %s restored %d results\n", node.ID, len(restored))
+		return scannerResult{name: node.Scanner, results: restored}
+	}
+	scanner, err := p.resolve(node)
+	if err != nil {
+		status := buildPluginStatusResult(node.Scanner, 0, err, 0)
+		p.emit(status)
+		return scannerResult{name: node.Scanner, err: err, statuses: []Result{status}}
+	}
+	if len(input) == 0 {
+		status := buildPluginStatusResult(scanner.Name(), 0, nil, 0)
+		p.emit(status)
+		return scannerResult{name: scanner.Name(), statuses: []Result{status}}
+	}
+
+	fmt.Printf("[Pipeline] %s (%s) processing %d inputs...\n", node.ID, scanner.Name(), len(input))
+	start := time.Now()
+	results, err := ExecuteScanner(ctx, scanner, input, p.resultHandler)
+	status := buildPluginStatusResult(scanner.Name(), len(results), err, time.Since(start))
+	p.emit(status)
+	if err == nil {
+		saveCheckpoint(p.checkpoints, stage, len(input), results)
+	}
+	return scannerResult{name: scanner.Name(), results: results, err: err, statuses: []Result{status}}
+}
+
+func (p *DAGPipeline) collectNodeInput(node PipelineNode, outputs map[string][]Result) []string {
+	edges := node.Inputs
+	if len(edges) == 0 {
+		edges = []PipelineEdge{{From: PipelineInputNode}}
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, edge := range edges {
+		for _, result := range outputs[strings.TrimSpace(edge.From)] {
+			if !edgeAcceptsResult(edge, result) {
+				continue
+			}
+			target := formatEdgeTarget(edge.Format, result)
+			if target == "" || seen[target] {
+				continue
+			}
+			seen[target] = true
+			out = append(out, target)
+		}
+	}
+	return out
+}
+
+func inputAsResults(input []string) []Result {
+	out := make([]Result, 0, len(input))
+	for _, item := range input {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		out = append(out, Result{Type: "domain", Data: item})
+	}
+	return out
+}
+
+func edgeAcceptsResult(edge PipelineEdge, result Result) bool {
+	if result.Type == "plugin_status" {
+		return false
+	}
+	if len(edge.Types) > 0 {
+		matched := false
+		for _, t := range edge.Types {
+			if strings.EqualFold(strings.TrimSpace(t), result.Type) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for key, allowed := range edge.Where {
+		if len(allowed) == 0 {
+			continue
+		}
+		if !fieldMatches(resultField(result, key), allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+func resultField(result Result, key string) interface{} {
+	if s, ok := result.Data.(string); ok {
+		if key == "domain" || key == "host" {
+			return s
+		}
+		return nil
+	}
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return data[key]
+}
+
+func fieldMatches(value interface{}, allowed []string) bool {
+	var candidates []string
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []string:
+		candidates = v
+	case []interface{}:
+		for _, item := range v {
+			candidates = append(candidates, fmt.Sprint(item))
+		}
+	default:
+		candidates = []string{fmt.Sprint(v)}
+	}
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		for _, want := range allowed {
+			if strings.EqualFold(candidate, strings.TrimSpace(want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatEdgeTarget(format string, result Result) string {
+	if domain, ok := result.Data.(string); ok {
+		if result.Type != "domain" {
+			return ""
+		}
+		switch format {
+		case "", EdgeFormatHost:
+			return domain
+		default:
+			return ""
+		}
+	}
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	url, _ := data["url"].(string)
+	ip, _ := data["ip"].(string)
+	port := interfaceToInt(data["port"])
+	host, _ := data["host"].(string)
+	if host == "" {
+		host, _ = data["domain"].(string)
+	}
+
+	if format == "" {
+		switch result.Type {
+		case "web_service":
+			format = EdgeFormatURLRoot
+		case "open_port", "port_service":
+			format = EdgeFormatIPPortHost
+		default:
+			format = EdgeFormatHost
+		}
+	}
+
+	switch format {
+	case EdgeFormatHost:
+		return host
+	case EdgeFormatURL:
+		return url
+	case EdgeFormatURLRoot:
+		if url == "" {
+			return ""
+		}
+		return url + "|" + extractRootDomain(host)
+	case EdgeFormatHostPort:
+		target := host
+		if target == "" {
+			target = ip
+		}
+		if target == "" || port <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s:%d", target, port)
+	case EdgeFormatIPPortHost:
+		if ip == "" || port <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s:%d:%s", ip, port, host)
+	}
+	return ""
+}
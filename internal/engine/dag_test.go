@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func levelIDs(levels [][]PipelineNode) [][]string {
+	out := make([][]string, 0, len(levels))
+	for _, wave := range levels {
+		ids := make([]string, 0, len(wave))
+		for _, node := range wave {
+			ids = append(ids, node.ID)
+		}
+		out = append(out, ids)
+	}
+	return out
+}
+
+func TestPipelineLevels(t *testing.T) {
+	from := func(ids ...string) []PipelineEdge {
+		edges := make([]PipelineEdge, 0, len(ids))
+		for _, id := range ids {
+			edges = append(edges, PipelineEdge{From: id})
+		}
+		return edges
+	}
+	tests := []struct {
+		name  string
+		nodes []PipelineNode
+		want  [][]string
+	}{
+		{
+			name: "chain",
+			nodes: []PipelineNode{
+				{ID: "subs", Scanner: "subfinder", Inputs: from(PipelineInputNode)},
+				{ID: "httpx", Scanner: "httpx", Inputs: from("subs")},
+				{ID: "nuclei", Scanner: "nuclei", Inputs: from("httpx")},
+			},
+			want: [][]string{{"subs"}, {"httpx"}, {"nuclei"}},
+		},
+		{
+			name: "fan out and join",
+			nodes: []PipelineNode{
+				{ID: "subfinder", Scanner: "subfinder"},
+				{ID: "crtsh", Scanner: "crtsh"},
+				{ID: "resolve", Scanner: "dnsx", Inputs: from("subfinder", "crtsh")},
+				{ID: "ports", Scanner: "naabu", Inputs: from("resolve")},
+				{ID: "web", Scanner: "httpx", Inputs: from("resolve")},
+				{ID: "nuclei", Scanner: "nuclei", Inputs: from("web", "ports")},
+			},
+			want: [][]string{{"subfinder", "crtsh"}, {"resolve"}, {"ports", "web"}, {"nuclei"}},
+		},
+		{
+			name: "duplicate edges count once",
+			nodes: []PipelineNode{
+				{ID: "a", Scanner: "a"},
+				{ID: "b", Scanner: "b", Inputs: []PipelineEdge{{From: "a", Types: []string{"domain"}}, {From: "a", Types: []string{"web_service"}}}},
+			},
+			want: [][]string{{"a"}, {"b"}},
+		},
+		{
+			name: "later waves are sorted by id",
+			nodes: []PipelineNode{
+				{ID: "root", Scanner: "root"},
+				{ID: "zeta", Scanner: "z", Inputs: from("root")},
+				{ID: "alpha", Scanner: "a", Inputs: from("root")},
+			},
+			want: [][]string{{"root"}, {"alpha", "zeta"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := PipelineDefinition{Name: tt.name, Nodes: tt.nodes}
+			if err := def.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			levels, err := def.levels()
+			if err != nil {
+				t.Fatalf("levels: %v", err)
+			}
+			if got := levelIDs(levels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("levels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     PipelineDefinition
+		wantErr string
+	}{
+		{
+			name:    "cycle",
+			def:     PipelineDefinition{Name: "p", Nodes: []PipelineNode{{ID: "a", Scanner: "a", Inputs: []PipelineEdge{{From: "b"}}}, {ID: "b", Scanner: "b", Inputs: []PipelineEdge{{From: "a"}}}}},
+			wantErr: "cycle",
+		},
+		{
+			name:    "unknown upstream",
+			def:     PipelineDefinition{Name: "p", Nodes: []PipelineNode{{ID: "a", Scanner: "a", Inputs: []PipelineEdge{{From: "missing"}}}}},
+			wantErr: "unknown node",
+		},
+		{
+			name:    "duplicate id",
+			def:     PipelineDefinition{Name: "p", Nodes: []PipelineNode{{ID: "a", Scanner: "a"}, {ID: "a", Scanner: "b"}}},
+			wantErr: "duplicate node id",
+		},
+		{
+			name:    "reserved id",
+			def:     PipelineDefinition{Name: "p", Nodes: []PipelineNode{{ID: PipelineInputNode, Scanner: "a"}}},
+			wantErr: "reserved",
+		},
+		{
+			name:    "unknown edge format",
+			def:     PipelineDefinition{Name: "p", Nodes: []PipelineNode{{ID: "a", Scanner: "a", Inputs: []PipelineEdge{{From: PipelineInputNode, Format: "csv"}}}}},
+			wantErr: "edge format",
+		},
+		{
+			name:    "missing scanner",
+			def:     PipelineDefinition{Name: "p", Nodes: []PipelineNode{{ID: "a"}}},
+			wantErr: "no scanner",
+		},
+		{
+			name:    "no nodes",
+			def:     PipelineDefinition{Name: "p"},
+			wantErr: "no nodes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostBudget caps the load all scanners of one job may put on a single
+// target host or IP. Zero values mean unlimited.
+type HostBudget struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	MaxConcurrent     int     `json:"maxConcurrent"`
+}
+
+// Enabled reports whether the budget limits anything.
+func (b HostBudget) Enabled() bool {
+	return b.RequestsPerSecond > 0 || b.MaxConcurrent > 0
+}
+
+// Scale converts the per-host budget into global rate and concurrency values
+// for external tools that only take process-wide flags, assuming their load
+// is spread over hosts distinct targets. Each value is capped at the tool's
+// own default and is 0 when that part of the budget is unlimited.
+func (b HostBudget) Scale(hosts, defaultRate, defaultConcurrency int) (rate int, concurrency int) {
+	if hosts < 1 {
+		hosts = 1
+	}
+	if b.RequestsPerSecond > 0 {
+		rate = int(math.Ceil(b.RequestsPerSecond * float64(hosts)))
+		if defaultRate > 0 && rate > defaultRate {
+			rate = defaultRate
+		}
+	}
+	if b.MaxConcurrent > 0 {
+		concurrency = b.MaxConcurrent * hosts
+		if defaultConcurrency > 0 && concurrency > defaultConcurrency {
+			concurrency = defaultConcurrency
+		}
+	}
+	return rate, concurrency
+}
+
+// HostLimiter enforces a HostBudget for in-process scanners. A budget is
+// tracked per host name and per IP, so virtual hosts served by one origin
+// share the origin's budget. A nil limiter allows everything.
+type HostLimiter struct {
+	budget HostBudget
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+	ips   map[string][]string
+}
+
+type hostSlot struct {
+	sem chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// hostResolveTimeout bounds the lookup of a host's IPs for the budget.
+const hostResolveTimeout = 3 * time.Second
+
+// NewHostLimiter creates a limiter for budget.
+func NewHostLimiter(budget HostBudget) *HostLimiter {
+	return &HostLimiter{budget: budget, hosts: make(map[string]*hostSlot), ips: make(map[string][]string)}
+}
+
+// Budget returns the limiter's budget.
+func (l *HostLimiter) Budget() HostBudget {
+	if l == nil {
+		return HostBudget{}
+	}
+	return l.budget
+}
+
+// Acquire blocks until target's host and the IPs it connects to have a free
+// connection slot and request token. ips are the addresses the caller is
+// about to connect to; without them a host name is resolved once per
+// limiter. The returned release must be called when the request is done.
+func (l *HostLimiter) Acquire(ctx context.Context, target string, ips ...string) (func(), error) {
+	noop := func() {}
+	if l == nil || !l.budget.Enabled() {
+		return noop, nil
+	}
+	slots := l.slots(ctx, HostKey(target), ips)
+
+	// Slots are taken in key order so two requests sharing an IP cannot
+	// each hold the slot the other waits for.
+	var held []*hostSlot
+	release := func() {
+		for _, slot := range held {
+			<-slot.sem
+		}
+	}
+	for _, slot := range slots {
+		if slot.sem == nil {
+			continue
+		}
+		select {
+		case slot.sem <- struct{}{}:
+			held = append(held, slot)
+		case <-ctx.Done():
+			release()
+			return noop, ctx.Err()
+		}
+	}
+
+	if l.budget.RequestsPerSecond > 0 {
+		interval := time.Duration(float64(time.Second) / l.budget.RequestsPerSecond)
+		var wait time.Duration
+		for _, slot := range slots {
+			if w := slot.reserve(interval); w > wait {
+				wait = w
+			}
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				release()
+				return noop, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
+
+// reserve takes the next request token of the slot and returns how long the
+// caller has to wait for it.
+func (s *hostSlot) reserve(interval time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	wait := s.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+		s.next = now
+	}
+	s.next = s.next.Add(interval)
+	return wait
+}
+
+// slots returns the slots of host and its IPs, sorted by key.
+func (l *HostLimiter) slots(ctx context.Context, host string, ips []string) []*hostSlot {
+	keys := []string{host}
+	if len(ips) == 0 && net.ParseIP(host) == nil {
+		ips = l.resolve(ctx, host)
+	}
+	for _, ip := range ips {
+		if parsed := net.ParseIP(strings.TrimSpace(ip)); parsed != nil {
+			keys = append(keys, parsed.String())
+		}
+	}
+	sort.Strings(keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]*hostSlot, 0, len(keys))
+	for i, key := range keys {
+		if key == "" || (i > 0 && key == keys[i-1]) {
+			continue
+		}
+		slot, ok := l.hosts[key]
+		if !ok {
+			slot = &hostSlot{}
+			if l.budget.MaxConcurrent > 0 {
+				slot.sem = make(chan struct{}, l.budget.MaxConcurrent)
+			}
+			l.hosts[key] = slot
+		}
+		out = append(out, slot)
+	}
+	return out
+}
+
+// resolve returns the IPs of host, looked up once per limiter. A failed
+// lookup leaves the host budget as the only limit.
+func (l *HostLimiter) resolve(ctx context.Context, host string) []string {
+	if host == "" {
+		return nil
+	}
+	l.mu.Lock()
+	ips, ok := l.ips[host]
+	l.mu.Unlock()
+	if ok {
+		return ips
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, hostResolveTimeout)
+	defer cancel()
+	ips, _ = net.DefaultResolver.LookupHost(lookupCtx, host)
+	l.mu.Lock()
+	l.ips[host] = ips
+	l.mu.Unlock()
+	return ips
+}
+
+// HostKey reduces a URL, host:port, "ip:port:host" or "target|rootDomain"
+// input to the lower-cased host or IP the budget is tracked under.
+func HostKey(target string) string {
+	target = strings.TrimSpace(target)
+	if idx := strings.Index(target, "|"); idx >= 0 {
+		target = target[:idx]
+	}
+	// "ip:port:host" inputs of the port chain are tracked under their IP.
+	if parts := strings.SplitN(target, ":", 3); len(parts) == 3 && net.ParseIP(parts[0]) != nil {
+		return parts[0]
+	}
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil && u.Host != "" {
+			target = u.Host
+		}
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		target = host
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.Trim(target, "[]")), ".")
+}
+
+// CountHosts returns the number of distinct hosts in input.
+func CountHosts(input []string) int {
+	seen := make(map[string]bool, len(input))
+	for _, item := range input {
+		if key := HostKey(item); key != "" {
+			seen[key] = true
+		}
+	}
+	return len(seen)
+}
+
+type hostLimiterKey struct{}
+
+// WithHostLimiter returns a context whose scanners share limiter.
+func WithHostLimiter(ctx context.Context, limiter *HostLimiter) context.Context {
+	return context.WithValue(ctx, hostLimiterKey{}, limiter)
+}
+
+// HostLimiterFromContext returns the limiter attached to ctx, or nil.
+func HostLimiterFromContext(ctx context.Context) *HostLimiter {
+	limiter, _ := ctx.Value(hostLimiterKey{}).(*HostLimiter)
+	return limiter
+}
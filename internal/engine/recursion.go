@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Recursion configures recursive discovery in the network stage. Hostnames
+// that later stages reveal (httpx redirects, TLS SANs, CNAME targets,
+// takeover checks) are fed back through httpx, ports and vulns until no new
+// in-scope host appears or MaxDepth extra hops have run.
+type Recursion struct {
+	MaxDepth int
+	// InScope reports whether a discovered host may be scanned. When nil, the
+	// pipeline scope is used, or else hosts under the root domains of the
+	// stage input are accepted.
+	InScope func(host string) bool
+}
+
+// Enabled reports whether recursion runs at least one extra hop.
+func (r Recursion) Enabled() bool {
+	return r.MaxDepth > 0
+}
+
+// SetRecursion enables recursive discovery for the network stage.
+func (p *Pipeline) SetRecursion(r Recursion) {
+	p.recursion = r
+}
+
+// runRecursiveNetworkStage runs the network stage on input and then on every
+// batch of newly discovered in-scope hosts. Results of hop N > 0 carry
+// discovery_hop=N; each new host is also emitted as a domain result.
+func (p *Pipeline) runRecursiveNetworkStage(ctx context.Context, input []string) ([]Result, error) {
+	allResults, err := p.runNetworkHop(ctx, input)
+	if err != nil || !p.recursion.Enabled() {
+		return allResults, err
+	}
+
+	inScope := p.recursion.InScope
+	if inScope == nil && p.scope != nil {
+		inScope = p.scope.Allows
+	}
+	if inScope == nil {
+		inScope = rootDomainScope(input)
+	}
+	seen := make(map[string]bool, len(input))
+	for _, item := range input {
+		if host := HostKey(item); host != "" {
+			seen[host] = true
+		}
+	}
+
+	last := allResults
+	for hop := 1; hop <= p.recursion.MaxDepth; hop++ {
+		if ctx.Err() != nil {
+			break
+		}
+		var next []string
+		for _, result := range last {
+			for _, host := range DiscoveredHosts(result) {
+				if seen[host] {
+					continue
+				}
+				seen[host] = true
+				if inScope(host) {
+					next = append(next, host)
+				}
+			}
+		}
+		if len(next) == 0 {
+			fmt.Printf("[Recursive] No new in-scope hosts after hop %d, stopping\n", hop-1)
+			break
+		}
+		sort.Strings(next)
+		fmt.Printf("[Recursive] Hop %d/%d: scanning %d new hosts\n", hop, p.recursion.MaxDepth, len(next))
+
+		for _, host := range next {
+			domain := Domain(host).Result()
+			p.emit(domain)
+			allResults = append(allResults, domain)
+		}
+
+		hopPipeline := *p
+		hopPipeline.resultHandler = discoveryHopHandler(p.resultHandler, hop)
+		hopPipeline.checkpoints = prefixedCheckpointStore(p.checkpoints, fmt.Sprintf("hop%d:", hop))
+		hopResults, err := hopPipeline.runNetworkHop(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range hopResults {
+			setDiscoveryHop(result, hop)
+		}
+		allResults = append(allResults, hopResults...)
+		last = hopResults
+	}
+	return allResults, nil
+}
+
+// DiscoveredHosts returns the hostnames a result points at, lower-cased and
+// without wildcard labels. IP addresses are skipped.
+func DiscoveredHosts(r Result) []string {
+	var raw []string
+	switch r.Type {
+	case ResultTypeDomain:
+		if s, ok := r.Data.(string); ok {
+			raw = append(raw, s)
+		}
+	case ResultTypeWebService:
+		data, _ := r.Data.(map[string]interface{})
+		raw = append(raw, mapStringValue(data, "url"), mapStringValue(data, "domain"))
+		if location := mapStringValue(data, "location"); strings.Contains(location, "://") {
+			raw = append(raw, location)
+		}
+		raw = append(raw, mapStringsValue(data, "cnames")...)
+		raw = append(raw, mapStringsValue(data, "tls_names")...)
+	case ResultTypeVulnerability:
+		data, _ := r.Data.(map[string]interface{})
+		raw = append(raw, mapStringValue(data, "host"), mapStringValue(data, "domain"), mapStringValue(data, "cname"))
+	case ResultTypeOpenPort, ResultTypePortService:
+		data, _ := r.Data.(map[string]interface{})
+		raw = append(raw, mapStringValue(data, "host"), mapStringValue(data, "domain"))
+	case ResultTypeTLSCertificate:
+		data, _ := r.Data.(map[string]interface{})
+		raw = append(raw, mapStringValue(data, "host"))
+		raw = append(raw, mapStringsValue(data, "sans")...)
+	}
+
+	var out []string
+	seen := make(map[string]bool, len(raw))
+	for _, item := range raw {
+		host := strings.TrimPrefix(HostKey(item), "*.")
+		if host == "" || seen[host] || !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+			continue
+		}
+		seen[host] = true
+		out = append(out, host)
+	}
+	return out
+}
+
+// rootDomainScope accepts hosts at or below the root domains of input.
+func rootDomainScope(input []string) func(string) bool {
+	roots := make(map[string]bool)
+	for _, item := range input {
+		if root := extractRootDomain(HostKey(item)); root != "" {
+			roots[root] = true
+		}
+	}
+	return func(host string) bool {
+		for root := range roots {
+			if host == root || strings.HasSuffix(host, "."+root) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func discoveryHopHandler(next ResultHandler, hop int) ResultHandler {
+	if next == nil {
+		return nil
+	}
+	return func(result Result) {
+		setDiscoveryHop(result, hop)
+		next(result)
+	}
+}
+
+// setDiscoveryHop tags the results the network stage stores with the hop
+// that found them.
+func setDiscoveryHop(result Result, hop int) {
+	switch result.Type {
+	case ResultTypeWebService, ResultTypeOpenPort, ResultTypePortService, ResultTypeVulnerability, ResultTypeTLSCertificate:
+	default:
+		return
+	}
+	if data, ok := result.Data.(map[string]interface{}); ok {
+		data["discovery_hop"] = hop
+	}
+}
+
+type prefixedCheckpoints struct {
+	store  CheckpointStore
+	prefix string
+}
+
+func prefixedCheckpointStore(store CheckpointStore, prefix string) CheckpointStore {
+	if store == nil {
+		return nil
+	}
+	return prefixedCheckpoints{store: store, prefix: prefix}
+}
+
+func (c prefixedCheckpoints) Load(stage string) ([]Result, bool) {
+	return c.store.Load(c.prefix + stage)
+}
+
+func (c prefixedCheckpoints) Save(stage string, inputCount int, results []Result) {
+	c.store.Save(c.prefix+stage, inputCount, results)
+}
+
+func mapStringValue(data map[string]interface{}, key string) string {
+	s, _ := data[key].(string)
+	return s
+}
+
+func mapStringsValue(data map[string]interface{}, key string) []string {
+	switch v := data[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,715 @@
+package engine
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResultSchemaVersion is the version of the result payload schema in
+// schema/results.v1.json. Bump it together with the schema file whenever a
+// field is renamed or removed.
+const ResultSchemaVersion = 1
+
+//go:embed schema/results.v1.json
+var resultSchema []byte
+
+// ResultSchema returns the JSON schema describing every result payload.
+func ResultSchema() []byte {
+	return resultSchema
+}
+
+// Result types.
+const (
+	ResultTypeDomain         = "domain"
+	ResultTypeDictWord       = "dict_word"
+	ResultTypeWebService     = "web_service"
+	ResultTypeOpenPort       = "open_port"
+	ResultTypePortService    = "port_service"
+	ResultTypeVulnerability  = "vulnerability"
+	ResultTypeScreenshot     = "screenshot"
+	ResultTypePluginStatus   = "plugin_status"
+	ResultTypeCTCertificate  = "ct_certificate"
+	ResultTypeZoneExposure   = "dns_zone_exposure"
+	ResultTypeDNSRecords     = "dns_records"
+	ResultTypeTLSCertificate = "tls_certificate"
+)
+
+// Domain is a discovered hostname. Its payload is the bare string.
+type Domain string
+
+// Result wraps d into a domain result.
+func (d Domain) Result() Result {
+	return Result{Type: ResultTypeDomain, Data: string(d)}
+}
+
+// WithoutDomains returns results minus the domain results for hosts.
+// Hosts are compared case-insensitively; other result types are kept.
+func WithoutDomains(results []Result, hosts []string) []Result {
+	if len(hosts) == 0 {
+		return results
+	}
+	drop := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		drop[strings.ToLower(strings.TrimSpace(host))] = true
+	}
+	out := make([]Result, 0, len(results))
+	for _, r := range results {
+		if host, ok := r.Data.(string); ok && r.Type == ResultTypeDomain && drop[strings.ToLower(strings.TrimSpace(host))] {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// DictWord is one generated brute-force label. Its payload is the bare string.
+type DictWord string
+
+// Result wraps w into a dict_word result.
+func (w DictWord) Result() Result {
+	return Result{Type: ResultTypeDictWord, Data: string(w)}
+}
+
+// WebService is a live HTTP(S) endpoint.
+type WebService struct {
+	URL           string            `json:"url"`
+	StatusCode    int               `json:"status_code"`
+	Title         string            `json:"title"`
+	Technologies  []string          `json:"technologies"`
+	IP            string            `json:"ip"`
+	Domain        string            `json:"domain"`
+	RootDomain    string            `json:"root_domain,omitempty"`
+	Location      string            `json:"location,omitempty"`
+	RedirectChain []string          `json:"redirect_chain,omitempty"` // URLs followed after url, in order
+	Webserver     string            `json:"webserver,omitempty"`
+	FinalURL      string            `json:"final_url,omitempty"` // URL of the last response after redirects
+	ContentType   string            `json:"content_type,omitempty"`
+	ContentLength int               `json:"content_length,omitempty"`
+	BodySHA256    string            `json:"body_sha256,omitempty"`
+	ResponseTime  int               `json:"response_time_ms,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	CDN           string            `json:"cdn,omitempty"`          // CDN or WAF name
+	FaviconMMH3   string            `json:"favicon_mmh3,omitempty"` // Shodan-compatible favicon hash
+	FaviconMD5    string            `json:"favicon_md5,omitempty"`
+	FaviconURL    string            `json:"favicon_url,omitempty"`
+	CNAMEs        []string          `json:"cnames,omitempty"`
+	TLSNames      []string          `json:"tls_names,omitempty"`
+	DiscoveryHop  int               `json:"discovery_hop,omitempty"`
+	DiscoveredAt  time.Time         `json:"discovered_at"`
+}
+
+// Result wraps w into a web_service result.
+func (w WebService) Result() Result {
+	data := map[string]interface{}{
+		"url":           w.URL,
+		"status_code":   w.StatusCode,
+		"title":         w.Title,
+		"technologies":  w.Technologies,
+		"ip":            w.IP,
+		"domain":        w.Domain,
+		"discovered_at": w.DiscoveredAt,
+	}
+	if w.RootDomain != "" {
+		data["root_domain"] = w.RootDomain
+	}
+	if w.Location != "" {
+		data["location"] = w.Location
+	}
+	if len(w.RedirectChain) > 0 {
+		data["redirect_chain"] = w.RedirectChain
+	}
+	if w.Webserver != "" {
+		data["webserver"] = w.Webserver
+	}
+	if w.FinalURL != "" {
+		data["final_url"] = w.FinalURL
+	}
+	if w.ContentType != "" {
+		data["content_type"] = w.ContentType
+	}
+	if w.ContentLength > 0 {
+		data["content_length"] = w.ContentLength
+	}
+	if w.BodySHA256 != "" {
+		data["body_sha256"] = w.BodySHA256
+	}
+	if w.ResponseTime > 0 {
+		data["response_time_ms"] = w.ResponseTime
+	}
+	if len(w.Headers) > 0 {
+		data["headers"] = w.Headers
+	}
+	if w.CDN != "" {
+		data["cdn"] = w.CDN
+	}
+	if w.FaviconMMH3 != "" {
+		data["favicon_mmh3"] = w.FaviconMMH3
+		data["favicon_md5"] = w.FaviconMD5
+		data["favicon_url"] = w.FaviconURL
+	}
+	if len(w.CNAMEs) > 0 {
+		data["cnames"] = w.CNAMEs
+	}
+	if len(w.TLSNames) > 0 {
+		data["tls_names"] = w.TLSNames
+	}
+	if w.DiscoveryHop > 0 {
+		data["discovery_hop"] = w.DiscoveryHop
+	}
+	return Result{Type: ResultTypeWebService, Data: data}
+}
+
+func (w WebService) validate() error {
+	if strings.TrimSpace(w.URL) == "" {
+		return fmt.Errorf("url is required")
+	}
+	if w.StatusCode < 0 || w.StatusCode > 999 {
+		return fmt.Errorf("status_code %d out of range", w.StatusCode)
+	}
+	return nil
+}
+
+// OpenPort is an open port found on a host.
+type OpenPort struct {
+	Host         string `json:"host"`
+	Domain       string `json:"domain"`
+	IP           string `json:"ip"`
+	Port         int    `json:"port"`
+	Protocol     string `json:"protocol,omitempty"`
+	RootDomain   string `json:"root_domain,omitempty"`
+	DiscoveryHop int    `json:"discovery_hop,omitempty"`
+}
+
+// Result wraps o into an open_port result.
+func (o OpenPort) Result() Result {
+	data := map[string]interface{}{
+		"host":   o.Host,
+		"domain": o.Domain,
+		"ip":     o.IP,
+		"port":   o.Port,
+	}
+	if o.Protocol != "" {
+		data["protocol"] = o.Protocol
+	}
+	if o.RootDomain != "" {
+		data["root_domain"] = o.RootDomain
+	}
+	if o.DiscoveryHop > 0 {
+		data["discovery_hop"] = o.DiscoveryHop
+	}
+	return Result{Type: ResultTypeOpenPort, Data: data}
+}
+
+func (o OpenPort) validate() error {
+	return validatePort(o.IP, o.Host, o.Domain, o.Port)
+}
+
+// PortService is a fingerprinted service on an open port.
+type PortService struct {
+	Host         string `json:"host,omitempty"`
+	Domain       string `json:"domain"`
+	IP           string `json:"ip"`
+	Port         int    `json:"port"`
+	Protocol     string `json:"protocol"`
+	Service      string `json:"service"`
+	Version      string `json:"version"`
+	Banner       string `json:"banner,omitempty"`
+	RootDomain   string `json:"root_domain,omitempty"`
+	DiscoveryHop int    `json:"discovery_hop,omitempty"`
+}
+
+// Result wraps p into a port_service result.
+func (p PortService) Result() Result {
+	data := map[string]interface{}{
+		"domain":   p.Domain,
+		"ip":       p.IP,
+		"port":     p.Port,
+		"protocol": p.Protocol,
+		"service":  p.Service,
+		"version":  p.Version,
+	}
+	if p.Host != "" {
+		data["host"] = p.Host
+	}
+	if p.Banner != "" {
+		data["banner"] = p.Banner
+	}
+	if p.RootDomain != "" {
+		data["root_domain"] = p.RootDomain
+	}
+	if p.DiscoveryHop > 0 {
+		data["discovery_hop"] = p.DiscoveryHop
+	}
+	return Result{Type: ResultTypePortService, Data: data}
+}
+
+func (p PortService) validate() error {
+	return validatePort(p.IP, p.Host, p.Domain, p.Port)
+}
+
+func validatePort(ip, host, domain string, port int) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("port %d out of range", port)
+	}
+	if strings.TrimSpace(ip) == "" && strings.TrimSpace(host) == "" && strings.TrimSpace(domain) == "" {
+		return fmt.Errorf("one of ip, host or domain is required")
+	}
+	return nil
+}
+
+// Vulnerability is a finding reported by a vuln scanner.
+type Vulnerability struct {
+	TemplateID   string    `json:"template_id"`
+	TemplateName string    `json:"template_name"`
+	Severity     string    `json:"severity"`
+	MatchedAt    string    `json:"matched_at"`
+	Host         string    `json:"host"`
+	Domain       string    `json:"domain"`
+	RootDomain   string    `json:"root_domain"`
+	URL          string    `json:"url,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	MatcherName  string    `json:"matcher_name"`
+	Description  string    `json:"description"`
+	Reference    string    `json:"reference"`
+	TemplateURL  string    `json:"template_url"`
+	CVE          string    `json:"cve,omitempty"`
+	CNAME        string    `json:"cname,omitempty"`
+	Raw          string    `json:"raw"`
+	DiscoveryHop int       `json:"discovery_hop,omitempty"`
+	DiscoveredAt time.Time `json:"discovered_at"`
+}
+
+// Result wraps v into a vulnerability result.
+func (v Vulnerability) Result() Result {
+	data := map[string]interface{}{
+		"template_id":   v.TemplateID,
+		"template_name": v.TemplateName,
+		"severity":      v.Severity,
+		"matched_at":    v.MatchedAt,
+		"host":          v.Host,
+		"domain":        v.Domain,
+		"root_domain":   v.RootDomain,
+		"matcher_name":  v.MatcherName,
+		"description":   v.Description,
+		"reference":     v.Reference,
+		"template_url":  v.TemplateURL,
+		"raw":           v.Raw,
+		"discovered_at": v.DiscoveredAt,
+	}
+	if v.URL != "" {
+		data["url"] = v.URL
+	}
+	if v.IP != "" {
+		data["ip"] = v.IP
+	}
+	if v.CVE != "" {
+		data["cve"] = v.CVE
+	}
+	if v.CNAME != "" {
+		data["cname"] = v.CNAME
+	}
+	if v.DiscoveryHop > 0 {
+		data["discovery_hop"] = v.DiscoveryHop
+	}
+	return Result{Type: ResultTypeVulnerability, Data: data}
+}
+
+var vulnSeverities = map[string]bool{
+	"info": true, "low": true, "medium": true, "high": true, "critical": true, "unknown": true,
+}
+
+func (v Vulnerability) validate() error {
+	if strings.TrimSpace(v.TemplateID) == "" {
+		return fmt.Errorf("template_id is required")
+	}
+	if !vulnSeverities[v.Severity] {
+		return fmt.Errorf("severity %q is not one of info/low/medium/high/critical/unknown", v.Severity)
+	}
+	if strings.TrimSpace(v.MatchedAt) == "" && strings.TrimSpace(v.Host) == "" {
+		return fmt.Errorf("one of matched_at or host is required")
+	}
+	return nil
+}
+
+// Screenshot summarises the screenshots taken for one root domain.
+type Screenshot struct {
+	RootDomain      string `json:"root_domain"`
+	ScreenshotCount int    `json:"screenshot_count"`
+	ScreenshotDir   string `json:"screenshot_dir"`
+	Database        string `json:"database"`
+}
+
+// Result wraps s into a screenshot result.
+func (s Screenshot) Result() Result {
+	return Result{Type: ResultTypeScreenshot, Data: map[string]interface{}{
+		"root_domain":      s.RootDomain,
+		"screenshot_count": s.ScreenshotCount,
+		"screenshot_dir":   s.ScreenshotDir,
+		"database":         s.Database,
+	}}
+}
+
+func (s Screenshot) validate() error {
+	if strings.TrimSpace(s.RootDomain) == "" {
+		return fmt.Errorf("root_domain is required")
+	}
+	return nil
+}
+
+// PluginStatus reports how one scanner run went.
+type PluginStatus struct {
+	Scanner      string `json:"scanner"`
+	Status       string `json:"status"`
+	SuccessCount int    `json:"success_count"`
+	FailureCount int    `json:"failure_count"`
+	TimeoutCount int    `json:"timeout_count"`
+	DurationMS   int64  `json:"duration_ms"`
+	Error        string `json:"error"`
+}
+
+// Result wraps p into a plugin_status result.
+func (p PluginStatus) Result() Result {
+	return Result{Type: ResultTypePluginStatus, Data: map[string]interface{}{
+		"scanner":       p.Scanner,
+		"status":        p.Status,
+		"success_count": p.SuccessCount,
+		"failure_count": p.FailureCount,
+		"timeout_count": p.TimeoutCount,
+		"duration_ms":   p.DurationMS,
+		"error":         p.Error,
+	}}
+}
+
+func (p PluginStatus) validate() error {
+	if strings.TrimSpace(p.Scanner) == "" {
+		return fmt.Errorf("scanner is required")
+	}
+	if p.Status != "ok" && p.Status != "error" {
+		return fmt.Errorf("status %q is not one of ok/error", p.Status)
+	}
+	return nil
+}
+
+// CTCertificate is a certificate found in a Certificate Transparency log.
+// Names holds the SANs at or below RootDomain. NotBefore and NotAfter are
+// RFC 3339 timestamps.
+type CTCertificate struct {
+	Source            string   `json:"source"`
+	CertID            string   `json:"cert_id"`
+	RootDomain        string   `json:"root_domain"`
+	Names             []string `json:"names"`
+	Issuer            string   `json:"issuer"`
+	Serial            string   `json:"serial"`
+	FingerprintSHA256 string   `json:"fingerprint_sha256,omitempty"`
+	NotBefore         string   `json:"not_before"`
+	NotAfter          string   `json:"not_after"`
+}
+
+// Result wraps c into a ct_certificate result.
+func (c CTCertificate) Result() Result {
+	data := map[string]interface{}{
+		"source":      c.Source,
+		"cert_id":     c.CertID,
+		"root_domain": c.RootDomain,
+		"names":       c.Names,
+		"issuer":      c.Issuer,
+		"serial":      c.Serial,
+		"not_before":  c.NotBefore,
+		"not_after":   c.NotAfter,
+	}
+	if c.FingerprintSHA256 != "" {
+		data["fingerprint_sha256"] = c.FingerprintSHA256
+	}
+	return Result{Type: ResultTypeCTCertificate, Data: data}
+}
+
+func (c CTCertificate) validate() error {
+	if strings.TrimSpace(c.Source) == "" {
+		return fmt.Errorf("source is required")
+	}
+	if len(c.Names) == 0 {
+		return fmt.Errorf("names must not be empty")
+	}
+	return nil
+}
+
+// Zone exposure kinds.
+const (
+	ZoneExposureAXFR     = "axfr"
+	ZoneExposureNSECWalk = "nsec_walk"
+)
+
+// ZoneExposure records that a nameserver disclosed the full contents of a
+// zone, either through an open AXFR or a walkable NSEC chain. Sample holds
+// a few of the disclosed names.
+type ZoneExposure struct {
+	Kind         string   `json:"kind"`
+	RootDomain   string   `json:"root_domain"`
+	Nameserver   string   `json:"nameserver"`
+	NameserverIP string   `json:"nameserver_ip,omitempty"`
+	RecordCount  int      `json:"record_count"`
+	Sample       []string `json:"sample,omitempty"`
+}
+
+// Result wraps z into a dns_zone_exposure result.
+func (z ZoneExposure) Result() Result {
+	data := map[string]interface{}{
+		"kind":         z.Kind,
+		"root_domain":  z.RootDomain,
+		"nameserver":   z.Nameserver,
+		"record_count": z.RecordCount,
+	}
+	if z.NameserverIP != "" {
+		data["nameserver_ip"] = z.NameserverIP
+	}
+	if len(z.Sample) > 0 {
+		data["sample"] = z.Sample
+	}
+	return Result{Type: ResultTypeZoneExposure, Data: data}
+}
+
+// Vulnerability describes z as a vulnerability finding so it is tracked
+// with the rest of the project's vulnerabilities.
+func (z ZoneExposure) Vulnerability() Vulnerability {
+	v := Vulnerability{
+		Host:         z.RootDomain,
+		Domain:       z.RootDomain,
+		RootDomain:   z.RootDomain,
+		IP:           z.NameserverIP,
+		MatcherName:  z.Nameserver,
+		Raw:          strings.Join(z.Sample, "\n"),
+		DiscoveredAt: time.Now(),
+	}
+	switch z.Kind {
+	case ZoneExposureNSECWalk:
+		v.TemplateID = "dns-nsec-zone-walk"
+		v.TemplateName = "DNSSEC NSEC zone walking"
+		v.Severity = "low"
+		v.MatchedAt = fmt.Sprintf("%s NSEC %s", z.Nameserver, z.RootDomain)
+		v.Description = fmt.Sprintf("Zone %s is signed with NSEC (not NSEC3); walking the chain on %s listed %d names.", z.RootDomain, z.Nameserver, z.RecordCount)
+		v.Reference = "https://datatracker.ietf.org/doc/html/rfc5155"
+	default:
+		v.TemplateID = "dns-zone-transfer"
+		v.TemplateName = "DNS zone transfer (AXFR) allowed"
+		v.Severity = "medium"
+		v.MatchedAt = fmt.Sprintf("%s AXFR %s", z.Nameserver, z.RootDomain)
+		v.Description = fmt.Sprintf("Nameserver %s allows unauthenticated AXFR of %s (%d records).", z.Nameserver, z.RootDomain, z.RecordCount)
+		v.Reference = "https://datatracker.ietf.org/doc/html/rfc5936"
+	}
+	return v
+}
+
+var zoneExposureKinds = map[string]bool{ZoneExposureAXFR: true, ZoneExposureNSECWalk: true}
+
+func (z ZoneExposure) validate() error {
+	if !zoneExposureKinds[z.Kind] {
+		return fmt.Errorf("kind %q is not one of axfr/nsec_walk", z.Kind)
+	}
+	if strings.TrimSpace(z.RootDomain) == "" || strings.TrimSpace(z.Nameserver) == "" {
+		return fmt.Errorf("root_domain and nameserver are required")
+	}
+	return nil
+}
+
+// DNSRecords is the record inventory of one host. Record types without
+// answers are left empty; Failed lists the upper-case types whose lookup
+// failed, so their stored records must be kept as they are.
+type DNSRecords struct {
+	Host   string   `json:"host"`
+	A      []string `json:"a,omitempty"`
+	AAAA   []string `json:"aaaa,omitempty"`
+	CNAME  []string `json:"cname,omitempty"`
+	MX     []string `json:"mx,omitempty"`
+	NS     []string `json:"ns,omitempty"`
+	TXT    []string `json:"txt,omitempty"`
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Result wraps d into a dns_records result.
+func (d DNSRecords) Result() Result {
+	data := map[string]interface{}{"host": d.Host}
+	for key, values := range map[string][]string{
+		"a": d.A, "aaaa": d.AAAA, "cname": d.CNAME, "mx": d.MX, "ns": d.NS, "txt": d.TXT, "failed": d.Failed,
+	} {
+		if len(values) > 0 {
+			data[key] = values
+		}
+	}
+	return Result{Type: ResultTypeDNSRecords, Data: data}
+}
+
+// ByType returns the non-empty record sets keyed by upper-case record type.
+func (d DNSRecords) ByType() map[string][]string {
+	out := make(map[string][]string, 6)
+	for typ, values := range map[string][]string{
+		"A": d.A, "AAAA": d.AAAA, "CNAME": d.CNAME, "MX": d.MX, "NS": d.NS, "TXT": d.TXT,
+	} {
+		if len(values) > 0 {
+			out[typ] = values
+		}
+	}
+	return out
+}
+
+func (d DNSRecords) validate() error {
+	if strings.TrimSpace(d.Host) == "" {
+		return fmt.Errorf("host is required")
+	}
+	if len(d.ByType()) == 0 {
+		return fmt.Errorf("at least one record is required")
+	}
+	return nil
+}
+
+// TLSCertificate is the leaf certificate served by a TLS endpoint. Host is
+// the name sent as SNI, or the IP when the endpoint was reached by address.
+type TLSCertificate struct {
+	Host               string    `json:"host"`
+	IP                 string    `json:"ip,omitempty"`
+	Port               int       `json:"port"`
+	URL                string    `json:"url,omitempty"`
+	Source             string    `json:"source,omitempty"` // plugin that captured it
+	Subject            string    `json:"subject"`
+	SubjectCN          string    `json:"subject_cn,omitempty"`
+	Issuer             string    `json:"issuer"`
+	IssuerCN           string    `json:"issuer_cn,omitempty"`
+	SANs               []string  `json:"sans,omitempty"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	Serial             string    `json:"serial"`
+	KeyType            string    `json:"key_type,omitempty"` // e.g. RSA-2048, ECDSA-P256
+	SignatureAlgorithm string    `json:"signature_algorithm,omitempty"`
+	FingerprintSHA256  string    `json:"fingerprint_sha256"`
+	SelfSigned         bool      `json:"self_signed"`
+	DiscoveryHop       int       `json:"discovery_hop,omitempty"`
+}
+
+// Result wraps c into a tls_certificate result.
+func (c TLSCertificate) Result() Result {
+	data := map[string]interface{}{
+		"host":               c.Host,
+		"port":               c.Port,
+		"subject":            c.Subject,
+		"issuer":             c.Issuer,
+		"not_before":         c.NotBefore,
+		"not_after":          c.NotAfter,
+		"serial":             c.Serial,
+		"fingerprint_sha256": c.FingerprintSHA256,
+		"self_signed":        c.SelfSigned,
+	}
+	if c.IP != "" {
+		data["ip"] = c.IP
+	}
+	if c.URL != "" {
+		data["url"] = c.URL
+	}
+	if c.Source != "" {
+		data["source"] = c.Source
+	}
+	if c.SubjectCN != "" {
+		data["subject_cn"] = c.SubjectCN
+	}
+	if c.IssuerCN != "" {
+		data["issuer_cn"] = c.IssuerCN
+	}
+	if c.KeyType != "" {
+		data["key_type"] = c.KeyType
+	}
+	if c.SignatureAlgorithm != "" {
+		data["signature_algorithm"] = c.SignatureAlgorithm
+	}
+	if len(c.SANs) > 0 {
+		data["sans"] = c.SANs
+	}
+	if c.DiscoveryHop > 0 {
+		data["discovery_hop"] = c.DiscoveryHop
+	}
+	return Result{Type: ResultTypeTLSCertificate, Data: data}
+}
+
+func (c TLSCertificate) validate() error {
+	if strings.TrimSpace(c.Host) == "" {
+		return fmt.Errorf("host is required")
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("port %d out of range", c.Port)
+	}
+	if strings.TrimSpace(c.FingerprintSHA256) == "" {
+		return fmt.Errorf("fingerprint_sha256 is required")
+	}
+	return nil
+}
+
+// DecodeResult converts the payload of r into T, rejecting unknown fields
+// and mistyped values.
+func DecodeResult[T any](r Result) (T, error) {
+	var out T
+	raw, err := json.Marshal(r.Data)
+	if err != nil {
+		return out, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// ValidateResult checks r against the result schema. Unknown result types
+// are accepted so external plugins can introduce their own.
+func ValidateResult(r Result) error {
+	switch r.Type {
+	case ResultTypeDomain, ResultTypeDictWord:
+		s, ok := r.Data.(string)
+		if !ok {
+			return fmt.Errorf("%s payload must be a string, got %T", r.Type, r.Data)
+		}
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("%s payload is empty", r.Type)
+		}
+		return nil
+	case ResultTypeWebService:
+		return validateAs[WebService](r)
+	case ResultTypeOpenPort:
+		return validateAs[OpenPort](r)
+	case ResultTypePortService:
+		return validateAs[PortService](r)
+	case ResultTypeVulnerability:
+		return validateAs[Vulnerability](r)
+	case ResultTypeScreenshot:
+		return validateAs[Screenshot](r)
+	case ResultTypePluginStatus:
+		return validateAs[PluginStatus](r)
+	case ResultTypeCTCertificate:
+		return validateAs[CTCertificate](r)
+	case ResultTypeZoneExposure:
+		return validateAs[ZoneExposure](r)
+	case ResultTypeDNSRecords:
+		return validateAs[DNSRecords](r)
+	case ResultTypeTLSCertificate:
+		return validateAs[TLSCertificate](r)
+	case "":
+		return fmt.Errorf("result type is empty")
+	default:
+		return nil
+	}
+}
+
+func validateAs[T interface{ validate() error }](r Result) error {
+	if _, ok := r.Data.(map[string]interface{}); !ok {
+		return fmt.Errorf("%s payload must be an object, got %T", r.Type, r.Data)
+	}
+	v, err := DecodeResult[T](r)
+	if err != nil {
+		return fmt.Errorf("%s: %v", r.Type, err)
+	}
+	if err := v.validate(); err != nil {
+		return fmt.Errorf("%s: %v", r.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  Result
+		wantErr bool
+	}{
+		{"domain", Domain("www.example.com").Result(), false},
+		{"empty domain", Result{Type: ResultTypeDomain, Data: " "}, true},
+		{"domain not a string", Result{Type: ResultTypeDomain, Data: 42}, true},
+		{"empty type", Result{Data: "x"}, true},
+		{"unknown type passes", Result{Type: "custom_finding", Data: map[string]interface{}{"any": 1}}, false},
+		{"web service", WebService{URL: "https://example.com", StatusCode: 200, DiscoveredAt: time.Now()}.Result(), false},
+		{"web service without url", WebService{StatusCode: 200}.Result(), true},
+		{"web service status out of range", WebService{URL: "https://example.com", StatusCode: 1000}.Result(), true},
+		{"open port", OpenPort{IP: "192.0.2.1", Port: 443}.Result(), false},
+		{"open port out of range", OpenPort{IP: "192.0.2.1", Port: 70000}.Result(), true},
+		{"open port without target", OpenPort{Port: 22}.Result(), true},
+		{"port service", PortService{Domain: "example.com", Port: 22, Service: "ssh"}.Result(), false},
+		{"vulnerability", Vulnerability{TemplateID: "t", Severity: "high", Host: "example.com"}.Result(), false},
+		{"vulnerability bad severity", Vulnerability{TemplateID: "t", Severity: "urgent", Host: "example.com"}.Result(), true},
+		{"plugin status", PluginStatus{Scanner: "Subfinder", Status: "ok"}.Result(), false},
+		{"plugin status bad status", PluginStatus{Scanner: "Subfinder", Status: "done"}.Result(), true},
+		{"dns records", DNSRecords{Host: "example.com", A: []string{"192.0.2.1"}, Failed: []string{"MX"}}.Result(), false},
+		{"dns records without records", DNSRecords{Host: "example.com"}.Result(), true},
+		{"mistyped field", Result{Type: ResultTypeOpenPort, Data: map[string]interface{}{"ip": "192.0.2.1", "port": "443"}}, true},
+		{"unknown field", Result{Type: ResultTypeOpenPort, Data: map[string]interface{}{"host_ip": "192.0.2.1", "port": 443}}, true},
+		{"payload not an object", Result{Type: ResultTypeWebService, Data: "https://example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResult(tt.result)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	in := PortService{Host: "www.example.com", Domain: "example.com", IP: "192.0.2.1", Port: 8443, Protocol: "tcp", Service: "https", Version: "1.2"}
+	got, err := DecodeResult[PortService](in.Result())
+	if err != nil {
+		t.Fatalf("DecodeResult: %v", err)
+	}
+	if got != in {
+		t.Errorf("DecodeResult() = %+v, want %+v", got, in)
+	}
+
+	records := DNSRecords{Host: "example.com", MX: []string{"mx.example.com"}, Failed: []string{"A", "AAAA"}}
+	gotRecords, err := DecodeResult[DNSRecords](records.Result())
+	if err != nil {
+		t.Fatalf("DecodeResult: %v", err)
+	}
+	if !reflect.DeepEqual(gotRecords, records) {
+		t.Errorf("DecodeResult() = %+v, want %+v", gotRecords, records)
+	}
+	if _, ok := gotRecords.ByType()["A"]; ok {
+		t.Error("ByType() includes a failed type without records")
+	}
+
+	// Results round-tripped through JSON carry float64 numbers.
+	var decoded Result
+	raw, _ := json.Marshal(OpenPort{IP: "192.0.2.1", Port: 443}.Result())
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	port, err := DecodeResult[OpenPort](decoded)
+	if err != nil || port.Port != 443 {
+		t.Errorf("DecodeResult() = %+v, %v, want port 443", port, err)
+	}
+
+	if _, err := DecodeResult[OpenPort](Result{Type: ResultTypeOpenPort, Data: map[string]interface{}{"ipaddr": "192.0.2.1"}}); err == nil {
+		t.Error("DecodeResult() accepted an unknown field")
+	}
+}
+
+func TestWithoutDomains(t *testing.T) {
+	results := []Result{
+		Domain("a.example.com").Result(),
+		Domain("B.example.com").Result(),
+		OpenPort{Domain: "a.example.com", IP: "192.0.2.1", Port: 80}.Result(),
+	}
+	got := WithoutDomains(results, []string{"A.example.com", "b.example.com"})
+	if len(got) != 1 || got[0].Type != ResultTypeOpenPort {
+		t.Errorf("WithoutDomains() = %+v, want only the open_port result", got)
+	}
+}
@@ -14,6 +14,9 @@ import (
 type Result struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+	// Source names the scanner(s) that reported the result, comma-separated
+	// when several domain scanners found the same host.
+	Source string `json:"source,omitempty"`
 }
 
 // Scanner defines scanner plugin behavior.
@@ -28,8 +31,14 @@ type Pipeline struct {
 	nextScanners      []Scanner
 	httpxScanner      Scanner
 	portScanners      []Scanner
+	networkScanners   []Scanner
 	vulnScanners      []Scanner
 	screenshotScanner Scanner
+	tlsScanner        Scanner
+	resultHandler     ResultHandler
+	checkpoints       CheckpointStore
+	recursion         Recursion
+	scope             *Scope
 }
 
 // NewPipeline creates a new pipeline.
@@ -47,6 +56,39 @@ func (p *Pipeline) SetScreenshotScanner(scanner Scanner) {
 	p.screenshotScanner = scanner
 }
 
+// SetTLSScanner sets the scanner that collects certificates from the open
+// ports found by the port scan chain.
+func (p *Pipeline) SetTLSScanner(scanner Scanner) {
+	p.tlsScanner = scanner
+}
+
+// SetResultHandler registers a callback that receives every result, including
+// plugin status rows, as soon as it is produced.
+func (p *Pipeline) SetResultHandler(handler ResultHandler) {
+	p.resultHandler = handler
+}
+
+// SetCheckpointStore enables per-stage checkpoints for the network stage.
+// Stages with a stored checkpoint are not executed again.
+func (p *Pipeline) SetCheckpointStore(store CheckpointStore) {
+	p.checkpoints = store
+}
+
+func (p *Pipeline) emit(results ...Result) {
+	if p.resultHandler == nil {
+		return
+	}
+	for _, result := range results {
+		p.resultHandler(result)
+	}
+}
+
+// SetScope restricts the network stage to in-scope targets. Out-of-scope
+// hosts are logged and never reach httpx, port or vulnerability scanners.
+func (p *Pipeline) SetScope(scope *Scope) {
+	p.scope = scope
+}
+
 // AddDomainScanner adds a domain discovery scanner (parallel).
 func (p *Pipeline) AddDomainScanner(scanner Scanner) {
 	p.domainScanners = append(p.domainScanners, scanner)
@@ -67,6 +109,16 @@ func (p *Pipeline) AddPortScanner(scanner Scanner) {
 	p.portScanners = append(p.portScanners, scanner)
 }
 
+// AddNetworkScanner adds a scanner that runs on the network stage input next
+// to httpx and the port chain, such as an exec plugin. Web services it
+// reports feed the vulnerability and screenshot stages like httpx results.
+func (p *Pipeline) AddNetworkScanner(scanner Scanner) {
+	if scanner == nil {
+		return
+	}
+	p.networkScanners = append(p.networkScanners, scanner)
+}
+
 // SetVulnScanner sets vulnerability scanner (runs after httpx).
 func (p *Pipeline) SetVulnScanner(scanner Scanner) {
 	p.vulnScanners = []Scanner{}
@@ -88,6 +140,8 @@ type scannerResult struct {
 	results  []Result
 	err      error
 	statuses []Result
+	// web marks results whose web services feed the vuln and screenshot stages.
+	web bool
 }
 
 // Execute runs the full pipeline from root domains.
@@ -104,8 +158,9 @@ func (p *Pipeline) Execute(ctx context.Context, input []string) ([]Result, error
 			go func(s Scanner) {
 				defer wg.Done()
 				start := time.Now()
-				results, err := s.Execute(ctx, input)
+				results, err := ExecuteScanner(ctx, s, input, p.resultHandler)
 				status := buildPluginStatusResult(s.Name(), len(results), err, time.Since(start))
+				p.emit(status)
 				resultChan <- scannerResult{
 					name:     s.Name(),
 					results:  results,
@@ -120,7 +175,9 @@ func (p *Pipeline) Execute(ctx context.Context, input []string) ([]Result, error
 			close(resultChan)
 		}()
 
-		domainMap := make(map[string]bool)
+		// domainIndex maps each host to its result in allResults so later
+		// reports from other scanners only add their source.
+		domainIndex := make(map[string]int)
 		for sr := range resultChan {
 			allResults = append(allResults, sr.statuses...)
 
@@ -134,13 +191,19 @@ func (p *Pipeline) Execute(ctx context.Context, input []string) ([]Result, error
 
 			for _, result := range sr.results {
 				if result.Type != "domain" {
+					// Keep metadata such as ct_certificate alongside the domains.
+					allResults = append(allResults, result)
 					continue
 				}
 				domain, ok := result.Data.(string)
-				if !ok || domain == "" || domainMap[domain] {
+				if !ok || domain == "" {
+					continue
+				}
+				if idx, seen := domainIndex[domain]; seen {
+					allResults[idx].Source = JoinSources(allResults[idx].Source, result.Source)
 					continue
 				}
-				domainMap[domain] = true
+				domainIndex[domain] = len(allResults)
 				currentInput = append(currentInput, domain)
 				allResults = append(allResults, result)
 			}
@@ -149,7 +212,7 @@ func (p *Pipeline) Execute(ctx context.Context, input []string) ([]Result, error
 		currentInput = input
 	}
 
-	networkResults, err := p.runNetworkStage(ctx, currentInput)
+	networkResults, err := p.runRecursiveNetworkStage(ctx, currentInput)
 	if err != nil {
 		return nil, err
 	}
@@ -157,8 +220,10 @@ func (p *Pipeline) Execute(ctx context.Context, input []string) ([]Result, error
 
 	for _, scanner := range p.nextScanners {
 		start := time.Now()
-		results, err := scanner.Execute(ctx, currentInput)
-		allResults = append(allResults, buildPluginStatusResult(scanner.Name(), len(results), err, time.Since(start)))
+		results, err := ExecuteScanner(ctx, scanner, currentInput, p.resultHandler)
+		status := buildPluginStatusResult(scanner.Name(), len(results), err, time.Since(start))
+		p.emit(status)
+		allResults = append(allResults, status)
 		if err != nil {
 			return nil, err
 		}
@@ -178,46 +243,93 @@ func (p *Pipeline) Execute(ctx context.Context, input []string) ([]Result, error
 
 // ExecuteFromSubdomains starts from known subdomains.
 func (p *Pipeline) ExecuteFromSubdomains(ctx context.Context, subdomains []string) ([]Result, error) {
-	return p.runNetworkStage(ctx, subdomains)
+	return p.runRecursiveNetworkStage(ctx, subdomains)
 }
 
-func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Result, error) {
+func (p *Pipeline) runNetworkHop(ctx context.Context, input []string) ([]Result, error) {
 	var allResults []Result
 
-	if p.httpxScanner == nil && len(p.portScanners) == 0 && len(p.vulnScanners) == 0 && p.screenshotScanner == nil {
+	if p.httpxScanner == nil && len(p.portScanners) == 0 && len(p.networkScanners) == 0 && len(p.vulnScanners) == 0 && p.screenshotScanner == nil {
 		return allResults, nil
 	}
 
+	input, rejected := p.scope.Filter(input)
+	logOutOfScope("network", rejected)
+
 	var wg sync.WaitGroup
-	resultChan := make(chan scannerResult, 2)
+	resultChan := make(chan scannerResult, 2+len(p.networkScanners))
 
 	if p.httpxScanner != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if results, ok := loadCheckpoint(p.checkpoints, "httpx"); ok {
+				fmt.Printf("[Checkpoint] %s restored %d results\n", p.httpxScanner.Name(), len(results))
+				resultChan <- scannerResult{name: p.httpxScanner.Name(), results: results, web: true}
+				return
+			}
 			start := time.Now()
-			results, err := p.httpxScanner.Execute(ctx, input)
+			results, err := ExecuteScanner(ctx, p.httpxScanner, input, p.resultHandler)
 			status := buildPluginStatusResult(p.httpxScanner.Name(), len(results), err, time.Since(start))
-			resultChan <- scannerResult{name: p.httpxScanner.Name(), results: results, err: err, statuses: []Result{status}}
+			p.emit(status)
+			if err == nil {
+				saveCheckpoint(p.checkpoints, "httpx", len(input), results)
+			}
+			resultChan <- scannerResult{name: p.httpxScanner.Name(), results: results, err: err, statuses: []Result{status}, web: true}
 		}()
 	}
 
+	for _, scanner := range p.networkScanners {
+		wg.Add(1)
+		go func(s Scanner) {
+			defer wg.Done()
+			stage := "network:" + strings.ToLower(s.Name())
+			if results, ok := loadCheckpoint(p.checkpoints, stage); ok {
+				fmt.Printf("[Checkpoint] %s restored %d results\n", s.Name(), len(results))
+				resultChan <- scannerResult{name: s.Name(), results: results, web: true}
+				return
+			}
+			start := time.Now()
+			results, err := ExecuteScanner(ctx, s, input, p.resultHandler)
+			status := buildPluginStatusResult(s.Name(), len(results), err, time.Since(start))
+			p.emit(status)
+			// A failing add-on scanner must not abort the built-in stages.
+			if err != nil {
+				fmt.Printf("[WARN] [%s] network scan failed: %v\n", s.Name(), err)
+			} else {
+				saveCheckpoint(p.checkpoints, stage, len(input), results)
+			}
+			resultChan <- scannerResult{name: s.Name(), results: results, statuses: []Result{status}, web: true}
+		}(scanner)
+	}
+
 	if len(p.portScanners) > 0 {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if results, ok := loadCheckpoint(p.checkpoints, "ports"); ok {
+				fmt.Printf("[Checkpoint] port scan restored %d results\n", len(results))
+				resultChan <- scannerResult{name: "PortScan", results: results}
+				return
+			}
 			var portResults []Result
 			var statusResults []Result
 			portInput := input
+			chainComplete := true
+			var openPorts []string
+			seenPorts := make(map[string]bool)
 
 			for _, scanner := range p.portScanners {
 				start := time.Now()
-				results, err := scanner.Execute(ctx, portInput)
-				statusResults = append(statusResults, buildPluginStatusResult(scanner.Name(), len(results), err, time.Since(start)))
+				results, err := ExecuteScanner(ctx, scanner, portInput, p.resultHandler)
+				status := buildPluginStatusResult(scanner.Name(), len(results), err, time.Since(start))
+				p.emit(status)
+				statusResults = append(statusResults, status)
 
 				if err != nil {
 					if strings.Contains(err.Error(), "not found in PATH") {
 						fmt.Printf("[WARN] [%s] tool not found in PATH, port scan skipped\n", scanner.Name())
+						chainComplete = false
 						break
 					}
 					resultChan <- scannerResult{name: "PortScan", err: err, statuses: statusResults, results: portResults}
@@ -241,11 +353,31 @@ func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Resul
 					if ip == "" || port <= 0 {
 						continue
 					}
-					nextInput = append(nextInput, fmt.Sprintf("%s:%d:%s", ip, port, host))
+					target := fmt.Sprintf("%s:%d:%s", ip, port, host)
+					nextInput = append(nextInput, target)
+					if !seenPorts[target] {
+						seenPorts[target] = true
+						openPorts = append(openPorts, target)
+					}
 				}
 				portInput = nextInput
 			}
 
+			if p.tlsScanner != nil && chainComplete && len(openPorts) > 0 {
+				start := time.Now()
+				results, err := ExecuteScanner(ctx, p.tlsScanner, openPorts, p.resultHandler)
+				status := buildPluginStatusResult(p.tlsScanner.Name(), len(results), err, time.Since(start))
+				p.emit(status)
+				statusResults = append(statusResults, status)
+				if err != nil {
+					fmt.Printf("[WARN] [%s] certificate collection failed: %v\n", p.tlsScanner.Name(), err)
+				}
+				portResults = append(portResults, results...)
+			}
+
+			if chainComplete {
+				saveCheckpoint(p.checkpoints, "ports", len(input), portResults)
+			}
 			resultChan <- scannerResult{name: "PortScan", results: portResults, statuses: statusResults}
 		}()
 	}
@@ -271,7 +403,7 @@ func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Resul
 
 		allResults = append(allResults, sr.results...)
 
-		if sr.name != "Httpx" {
+		if !sr.web {
 			continue
 		}
 
@@ -297,6 +429,8 @@ func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Resul
 	}
 
 	if len(p.vulnScanners) > 0 {
+		vulnInputs, rejected = p.scope.Filter(vulnInputs)
+		logOutOfScope("vuln", rejected)
 		for _, vulnScanner := range p.vulnScanners {
 			scanInput := vulnInputs
 			// Subdomain takeover checks work on hostnames and should not be
@@ -304,14 +438,24 @@ func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Resul
 			if isSubTakeoverScanner(vulnScanner) {
 				scanInput = input
 			}
+			stage := "vuln:" + strings.ToLower(vulnScanner.Name())
+			if restored, ok := loadCheckpoint(p.checkpoints, stage); ok {
+				fmt.Printf("[Checkpoint] %s restored %d results\n", vulnScanner.Name(), len(restored))
+				allResults = append(allResults, restored...)
+				continue
+			}
 			if len(scanInput) == 0 {
-				allResults = append(allResults, buildPluginStatusResult(vulnScanner.Name(), 0, nil, 0))
+				status := buildPluginStatusResult(vulnScanner.Name(), 0, nil, 0)
+				p.emit(status)
+				allResults = append(allResults, status)
 				continue
 			}
 			fmt.Printf("[Vuln] %s scanning %d targets...\n", vulnScanner.Name(), len(scanInput))
 			start := time.Now()
-			vulnResults, err := vulnScanner.Execute(ctx, scanInput)
-			allResults = append(allResults, buildPluginStatusResult(vulnScanner.Name(), len(vulnResults), err, time.Since(start)))
+			vulnResults, err := ExecuteScanner(ctx, vulnScanner, scanInput, p.resultHandler)
+			status := buildPluginStatusResult(vulnScanner.Name(), len(vulnResults), err, time.Since(start))
+			p.emit(status)
+			allResults = append(allResults, status)
 			if err != nil {
 				if strings.Contains(err.Error(), "not found in PATH") {
 					fmt.Printf("[WARN] [%s] tool not found in PATH, vulnerability scan skipped\n", vulnScanner.Name())
@@ -320,6 +464,7 @@ func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Resul
 				}
 				continue
 			}
+			saveCheckpoint(p.checkpoints, stage, len(scanInput), vulnResults)
 			allResults = append(allResults, vulnResults...)
 		}
 	}
@@ -327,8 +472,10 @@ func (p *Pipeline) runNetworkStage(ctx context.Context, input []string) ([]Resul
 	if p.screenshotScanner != nil && len(screenshotInputs) > 0 {
 		fmt.Printf("[Screenshot] capturing %d live URLs...\n", len(screenshotInputs))
 		start := time.Now()
-		screenshotResults, err := p.screenshotScanner.Execute(ctx, screenshotInputs)
-		allResults = append(allResults, buildPluginStatusResult(p.screenshotScanner.Name(), len(screenshotResults), err, time.Since(start)))
+		screenshotResults, err := ExecuteScanner(ctx, p.screenshotScanner, screenshotInputs, p.resultHandler)
+		status := buildPluginStatusResult(p.screenshotScanner.Name(), len(screenshotResults), err, time.Since(start))
+		p.emit(status)
+		allResults = append(allResults, status)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found in PATH") {
 				fmt.Printf("[WARN] [%s] tool not found in PATH, screenshot skipped\n", p.screenshotScanner.Name())
@@ -371,18 +518,15 @@ func buildPluginStatusResult(scannerName string, successCount int, err error, du
 		}
 	}
 
-	return Result{
-		Type: "plugin_status",
-		Data: map[string]interface{}{
-			"scanner":       scannerName,
-			"status":        status,
-			"success_count": successCount,
-			"failure_count": failureCount,
-			"timeout_count": timeoutCount,
-			"duration_ms":   duration.Milliseconds(),
-			"error":         errMsg,
-		},
-	}
+	return PluginStatus{
+		Scanner:      scannerName,
+		Status:       status,
+		SuccessCount: successCount,
+		FailureCount: failureCount,
+		TimeoutCount: timeoutCount,
+		DurationMS:   duration.Milliseconds(),
+		Error:        errMsg,
+	}.Result()
 }
 
 func mapInt(data map[string]interface{}, key string) int {
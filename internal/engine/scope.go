@@ -0,0 +1,335 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Scope rule actions.
+const (
+	ScopeInclude = "include"
+	ScopeExclude = "exclude"
+)
+
+// Scope rule kinds. A domain rule matches the domain and everything below
+// it; a wildcard rule uses * for any run of characters, so "*.example.com"
+// matches subdomains but not the apex.
+const (
+	ScopeKindDomain   = "domain"
+	ScopeKindWildcard = "wildcard"
+	ScopeKindRegex    = "regex"
+	ScopeKindCIDR     = "cidr"
+)
+
+// ScopeRule is one include or exclude entry of a Scope.
+type ScopeRule struct {
+	Action  string `json:"action"`
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+}
+
+type compiledScopeRule struct {
+	rule   ScopeRule
+	domain string
+	re     *regexp.Regexp
+	ipNet  *net.IPNet
+}
+
+// Scope decides which targets may be scanned or stored. A target is in scope
+// when it matches no exclude rule and either matches an include rule or the
+// scope has no include rules that apply to it. Hostnames are restricted by
+// domain, wildcard and regex includes only, so a CIDR include adds the
+// addresses it covers without narrowing the hostnames in scope. A nil Scope
+// accepts everything.
+type Scope struct {
+	includes     []compiledScopeRule
+	cidrIncludes []compiledScopeRule
+	excludes     []compiledScopeRule
+}
+
+// NewScope compiles rules. Rules with an empty kind are classified by
+// NormalizeScopeRule.
+func NewScope(rules []ScopeRule) (*Scope, error) {
+	scope := &Scope{}
+	for _, rule := range rules {
+		compiled, err := compileScopeRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case compiled.rule.Action == ScopeExclude:
+			scope.excludes = append(scope.excludes, compiled)
+		case compiled.ipNet != nil:
+			scope.cidrIncludes = append(scope.cidrIncludes, compiled)
+		default:
+			scope.includes = append(scope.includes, compiled)
+		}
+	}
+	return scope, nil
+}
+
+// NormalizeScopeRule lower-cases and validates rule. An empty action means
+// include; an empty kind is inferred from the pattern (CIDR or IP, wildcard,
+// otherwise domain).
+func NormalizeScopeRule(rule ScopeRule) (ScopeRule, error) {
+	compiled, err := compileScopeRule(rule)
+	if err != nil {
+		return ScopeRule{}, err
+	}
+	return compiled.rule, nil
+}
+
+func compileScopeRule(rule ScopeRule) (compiledScopeRule, error) {
+	rule.Action = strings.ToLower(strings.TrimSpace(rule.Action))
+	rule.Kind = strings.ToLower(strings.TrimSpace(rule.Kind))
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	if rule.Action == "" {
+		rule.Action = ScopeInclude
+	}
+	if rule.Action != ScopeInclude && rule.Action != ScopeExclude {
+		return compiledScopeRule{}, fmt.Errorf("invalid scope action %q", rule.Action)
+	}
+	if rule.Pattern == "" {
+		return compiledScopeRule{}, fmt.Errorf("scope pattern is required")
+	}
+	if rule.Kind == "" {
+		switch {
+		case net.ParseIP(rule.Pattern) != nil || strings.Contains(rule.Pattern, "/"):
+			rule.Kind = ScopeKindCIDR
+		case strings.Contains(rule.Pattern, "*"):
+			rule.Kind = ScopeKindWildcard
+		default:
+			rule.Kind = ScopeKindDomain
+		}
+	}
+
+	out := compiledScopeRule{}
+	switch rule.Kind {
+	case ScopeKindDomain:
+		rule.Pattern = strings.TrimSuffix(strings.ToLower(rule.Pattern), ".")
+		out.domain = rule.Pattern
+	case ScopeKindWildcard:
+		rule.Pattern = strings.TrimSuffix(strings.ToLower(rule.Pattern), ".")
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(rule.Pattern), `\*`, `.+`) + "$"
+		out.re = regexp.MustCompile(expr)
+	case ScopeKindRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return compiledScopeRule{}, fmt.Errorf("invalid scope regex %q: %v", rule.Pattern, err)
+		}
+		out.re = re
+	case ScopeKindCIDR:
+		cidr := rule.Pattern
+		if ip := net.ParseIP(cidr); ip != nil {
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return compiledScopeRule{}, fmt.Errorf("invalid scope CIDR %q: %v", rule.Pattern, err)
+		}
+		rule.Pattern = ipNet.String()
+		out.ipNet = ipNet
+	default:
+		return compiledScopeRule{}, fmt.Errorf("invalid scope kind %q", rule.Kind)
+	}
+	out.rule = rule
+	return out, nil
+}
+
+func (r compiledScopeRule) matches(name string, ips []net.IP) bool {
+	if r.ipNet != nil {
+		for _, ip := range ips {
+			if r.ipNet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	if name == "" {
+		return false
+	}
+	if r.re != nil {
+		return r.re.MatchString(name)
+	}
+	return name == r.domain || strings.HasSuffix(name, "."+r.domain)
+}
+
+// Check reports whether target is in scope and, when it is not, why. target
+// may be a host, URL, host:port, "ip:port:host" or "target|rootDomain".
+// extraIPs are resolved addresses of the target and are matched against
+// CIDR rules as well.
+func (s *Scope) Check(target string, extraIPs ...string) (bool, string) {
+	if s == nil {
+		return true, ""
+	}
+	name, ips := scopeTargetParts(target)
+	return s.check(name, append(ips, parseIPs(extraIPs)...), true)
+}
+
+// CheckResult applies the scope to the host a result is about. Port and
+// certificate results that only carry an IP came from scanning an in-scope
+// host, so only exclude rules apply to them. Result types without a host are always in scope.
+func (s *Scope) CheckResult(r Result) (bool, string) {
+	if s == nil {
+		return true, ""
+	}
+	if r.Type == ResultTypeDomain {
+		domain, _ := r.Data.(string)
+		return s.Check(domain)
+	}
+	data, ok := r.Data.(map[string]interface{})
+	if !ok {
+		return true, ""
+	}
+	ip := mapStringValue(data, "ip")
+	switch r.Type {
+	case ResultTypeWebService:
+		return s.Check(firstNonEmpty(mapStringValue(data, "url"), mapStringValue(data, "domain")), ip)
+	case ResultTypeVulnerability:
+		return s.Check(firstNonEmpty(mapStringValue(data, "host"), mapStringValue(data, "url"), mapStringValue(data, "domain")), ip)
+	case ResultTypeOpenPort, ResultTypePortService:
+		target := firstNonEmpty(mapStringValue(data, "domain"), mapStringValue(data, "host"))
+		if target == "" {
+			return s.check("", parseIPs([]string{ip}), false)
+		}
+		return s.Check(target, ip)
+	case ResultTypeTLSCertificate:
+		host := mapStringValue(data, "host")
+		if net.ParseIP(HostKey(host)) != nil {
+			return s.check("", parseIPs([]string{host, ip}), false)
+		}
+		return s.Check(host, ip)
+	}
+	return true, ""
+}
+
+// HasIncludes reports whether the scope restricts hostnames to include rules.
+// CIDR includes do not count: they never narrow the hostnames in scope.
+func (s *Scope) HasIncludes() bool {
+	return s != nil && len(s.includes) > 0
+}
+
+func (s *Scope) check(name string, ips []net.IP, requireInclude bool) (bool, string) {
+	if name == "" && len(ips) == 0 {
+		return false, "empty target"
+	}
+	for _, rule := range s.excludes {
+		if rule.matches(name, ips) {
+			return false, "excluded by " + rule.rule.Kind + " " + rule.rule.Pattern
+		}
+	}
+	if !requireInclude || len(s.includes)+len(s.cidrIncludes) == 0 {
+		return true, ""
+	}
+	for _, rule := range s.includes {
+		if rule.matches(name, ips) {
+			return true, ""
+		}
+	}
+	for _, rule := range s.cidrIncludes {
+		if rule.matches(name, ips) {
+			return true, ""
+		}
+	}
+	if name != "" && len(s.includes) == 0 {
+		return true, ""
+	}
+	return false, "no include rule matched"
+}
+
+// Allows reports whether target is in scope.
+func (s *Scope) Allows(target string) bool {
+	ok, _ := s.Check(target)
+	return ok
+}
+
+// Filter splits targets into in-scope and out-of-scope lists.
+func (s *Scope) Filter(targets []string) (allowed, rejected []string) {
+	if s == nil {
+		return targets, nil
+	}
+	allowed = make([]string, 0, len(targets))
+	for _, target := range targets {
+		if s.Allows(target) {
+			allowed = append(allowed, target)
+		} else {
+			rejected = append(rejected, target)
+		}
+	}
+	return allowed, rejected
+}
+
+// scopeTargetParts returns the hostname and IP addresses named by target.
+// Port scan chain inputs use the "ip:port:host" form.
+func scopeTargetParts(target string) (string, []net.IP) {
+	target = strings.TrimSpace(target)
+	if idx := strings.Index(target, "|"); idx >= 0 {
+		target = target[:idx]
+	}
+	if !strings.Contains(target, "://") && strings.Count(target, ":") == 2 {
+		parts := strings.SplitN(target, ":", 3)
+		var ips []net.IP
+		if ip := net.ParseIP(parts[0]); ip != nil {
+			ips = append(ips, ip)
+		}
+		name := HostKey(parts[2])
+		if ip := net.ParseIP(name); ip != nil {
+			return "", append(ips, ip)
+		}
+		return name, ips
+	}
+	host := HostKey(target)
+	if ip := net.ParseIP(host); ip != nil {
+		return "", []net.IP{ip}
+	}
+	return strings.TrimPrefix(host, "*."), nil
+}
+
+func parseIPs(raw []string) []net.IP {
+	var out []net.IP
+	for _, item := range raw {
+		if ip := net.ParseIP(strings.TrimSpace(item)); ip != nil {
+			out = append(out, ip)
+		}
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// logOutOfScope prints skipped targets for stage, capped to keep logs short.
+func logOutOfScope(stage string, rejected []string) {
+	if len(rejected) == 0 {
+		return
+	}
+	const maxListed = 10
+	for i, target := range rejected {
+		if i == maxListed {
+			fmt.Printf("[Scope] %s: ... and %d more out-of-scope targets\n", stage, len(rejected)-maxListed)
+			break
+		}
+		fmt.Printf("[Scope] %s: skipping out-of-scope target %s\n", stage, target)
+	}
+}
+
+// resultTarget names the host a result is about, for log lines.
+func resultTarget(r Result) string {
+	if s, ok := r.Data.(string); ok {
+		return s
+	}
+	data, _ := r.Data.(map[string]interface{})
+	return firstNonEmpty(mapStringValue(data, "url"), mapStringValue(data, "host"), mapStringValue(data, "domain"), mapStringValue(data, "ip"))
+}
@@ -0,0 +1,167 @@
+package engine
+
+import "testing"
+
+func TestScopeCheck(t *testing.T) {
+	scope, err := NewScope([]ScopeRule{
+		{Pattern: "example.com"},
+		{Pattern: "*.corp.test"},
+		{Kind: ScopeKindRegex, Pattern: `^api-\d+\.svc\.test$`},
+		{Pattern: "10.0.0.0/24"},
+		{Action: ScopeExclude, Pattern: "legacy.example.com"},
+		{Action: ScopeExclude, Pattern: "10.0.0.99"},
+	})
+	if err != nil {
+		t.Fatalf("NewScope: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		extraIPs []string
+		want     bool
+	}{
+		{"apex", "example.com", nil, true},
+		{"subdomain", "www.example.com", nil, true},
+		{"case and trailing dot", "WWW.Example.COM.", nil, true},
+		{"suffix is not a subdomain", "notexample.com", nil, false},
+		{"url", "https://app.example.com:8443/login", nil, true},
+		{"host port", "app.example.com:443", nil, true},
+		{"root suffix", "app.example.com|example.com", nil, true},
+		{"ip port host", "10.0.0.5:443:app.example.com", nil, true},
+		{"excluded subtree", "a.legacy.example.com", nil, false},
+		{"wildcard subdomain", "vpn.corp.test", nil, true},
+		{"wildcard skips apex", "corp.test", nil, false},
+		{"regex", "api-12.svc.test", nil, true},
+		{"regex anchored", "api-x.svc.test", nil, false},
+		{"ip in cidr", "10.0.0.7", nil, true},
+		{"ip outside cidr", "10.0.1.7", nil, false},
+		{"excluded ip", "10.0.0.99", nil, false},
+		{"resolved ip in cidr", "unrelated.test", []string{"10.0.0.8"}, true},
+		{"resolved ip excluded", "www.example.com", []string{"10.0.0.99"}, false},
+		{"empty", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := scope.Check(tt.target, tt.extraIPs...)
+			if got != tt.want {
+				t.Errorf("Check(%q, %v) = %v (%s), want %v", tt.target, tt.extraIPs, got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeWithoutIncludes(t *testing.T) {
+	scope, err := NewScope([]ScopeRule{{Action: ScopeExclude, Pattern: "*.internal.test"}})
+	if err != nil {
+		t.Fatalf("NewScope: %v", err)
+	}
+	if scope.HasIncludes() {
+		t.Fatal("HasIncludes() = true for an exclude-only scope")
+	}
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"anything.test", true},
+		{"db.internal.test", false},
+		{"internal.test", true},
+	}
+	for _, tt := range tests {
+		if got := scope.Allows(tt.target); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+
+	var nilScope *Scope
+	if !nilScope.Allows("anything.test") {
+		t.Error("nil scope rejected a target")
+	}
+}
+
+func TestScopeCIDRIncludesOnly(t *testing.T) {
+	scope, err := NewScope([]ScopeRule{{Pattern: "192.0.2.0/24"}})
+	if err != nil {
+		t.Fatalf("NewScope: %v", err)
+	}
+	if scope.HasIncludes() {
+		t.Fatal("HasIncludes() = true for a CIDR-only scope")
+	}
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"www.example.com", true},
+		{"192.0.2.10", true},
+		{"198.51.100.1", false},
+		{"198.51.100.1:443:www.example.com", true},
+	}
+	for _, tt := range tests {
+		if got := scope.Allows(tt.target); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestScopeCheckResult(t *testing.T) {
+	scope, err := NewScope([]ScopeRule{
+		{Pattern: "example.com"},
+		{Action: ScopeExclude, Pattern: "192.0.2.66"},
+	})
+	if err != nil {
+		t.Fatalf("NewScope: %v", err)
+	}
+	tests := []struct {
+		name   string
+		result Result
+		want   bool
+	}{
+		{"domain in scope", Domain("www.example.com").Result(), true},
+		{"domain out of scope", Domain("www.other.test").Result(), false},
+		{"web service url", WebService{URL: "https://www.example.com/"}.Result(), true},
+		{"ip-only port from in-scope host", OpenPort{IP: "192.0.2.10", Port: 443}.Result(), true},
+		{"ip-only port excluded", OpenPort{IP: "192.0.2.66", Port: 443}.Result(), false},
+		{"port on out-of-scope domain", OpenPort{Domain: "other.test", IP: "192.0.2.10", Port: 80}.Result(), false},
+		{"type without host", PluginStatus{Scanner: "x", Status: "ok"}.Result(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, reason := scope.CheckResult(tt.result); got != tt.want {
+				t.Errorf("CheckResult() = %v (%s), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeScopeRule(t *testing.T) {
+	tests := []struct {
+		in      ScopeRule
+		want    ScopeRule
+		wantErr bool
+	}{
+		{in: ScopeRule{Pattern: " Example.COM. "}, want: ScopeRule{Action: ScopeInclude, Kind: ScopeKindDomain, Pattern: "example.com"}},
+		{in: ScopeRule{Pattern: "*.Example.com"}, want: ScopeRule{Action: ScopeInclude, Kind: ScopeKindWildcard, Pattern: "*.example.com"}},
+		{in: ScopeRule{Action: "EXCLUDE", Pattern: "192.0.2.1"}, want: ScopeRule{Action: ScopeExclude, Kind: ScopeKindCIDR, Pattern: "192.0.2.1/32"}},
+		{in: ScopeRule{Pattern: "192.0.2.9/24"}, want: ScopeRule{Action: ScopeInclude, Kind: ScopeKindCIDR, Pattern: "192.0.2.0/24"}},
+		{in: ScopeRule{Action: "allow", Pattern: "example.com"}, wantErr: true},
+		{in: ScopeRule{Pattern: "  "}, wantErr: true},
+		{in: ScopeRule{Kind: ScopeKindRegex, Pattern: "("}, wantErr: true},
+		{in: ScopeRule{Kind: "prefix", Pattern: "example"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeScopeRule(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeScopeRule(%+v) = %+v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NormalizeScopeRule(%+v): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeScopeRule(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// ResultHandler receives results as soon as a scanner produces them.
+// It may be invoked concurrently from different scanner goroutines.
+type ResultHandler func(Result)
+
+// StreamingScanner is implemented by scanners that can emit results while the
+// underlying tool is still running. The returned slice still carries the full
+// result set so batch callers keep working unchanged.
+type StreamingScanner interface {
+	Scanner
+	ExecuteStream(ctx context.Context, input []string, emit ResultHandler) ([]Result, error)
+}
+
+// InvalidResultHandler receives results that fail ValidateResult. The result
+// is still passed on to the pipeline; the handler only reports it.
+type InvalidResultHandler func(scanner string, result Result, err error)
+
+type invalidResultHandlerKey struct{}
+
+// WithInvalidResultHandler returns a context whose scanner runs report
+// malformed results to handler.
+func WithInvalidResultHandler(ctx context.Context, handler InvalidResultHandler) context.Context {
+	return context.WithValue(ctx, invalidResultHandlerKey{}, handler)
+}
+
+func reportInvalidResult(ctx context.Context, scanner string, result Result) {
+	err := ValidateResult(result)
+	if err == nil {
+		return
+	}
+	if handler, ok := ctx.Value(invalidResultHandlerKey{}).(InvalidResultHandler); ok && handler != nil {
+		handler(scanner, result, err)
+		return
+	}
+	log.Printf("[Engine] %s emitted malformed result: %v", scanner, err)
+}
+
+// ExecuteScanner runs scanner and forwards every result to emit. Streaming
+// scanners emit incrementally; plain scanners emit once Execute returns.
+// Every result is checked against the result schema on the way out.
+func ExecuteScanner(ctx context.Context, scanner Scanner, input []string, emit ResultHandler) ([]Result, error) {
+	name := scanner.Name()
+	if emit == nil {
+		results, err := scanner.Execute(ctx, input)
+		for i := range results {
+			stampSource(&results[i], name)
+			reportInvalidResult(ctx, name, results[i])
+		}
+		return results, err
+	}
+	if streaming, ok := scanner.(StreamingScanner); ok {
+		results, err := streaming.ExecuteStream(ctx, input, func(result Result) {
+			stampSource(&result, name)
+			reportInvalidResult(ctx, name, result)
+			emit(result)
+		})
+		for i := range results {
+			stampSource(&results[i], name)
+		}
+		return results, err
+	}
+	results, err := scanner.Execute(ctx, input)
+	for i := range results {
+		stampSource(&results[i], name)
+		reportInvalidResult(ctx, name, results[i])
+		emit(results[i])
+	}
+	return results, err
+}
+
+// stampSource attributes r to scanner unless the scanner set a source itself.
+func stampSource(r *Result, scanner string) {
+	if r.Source == "" {
+		r.Source = scanner
+	}
+}
+
+// JoinSources merges two comma-separated source lists, keeping order and
+// dropping duplicates.
+func JoinSources(a, b string) string {
+	out := SplitSources(a)
+	for _, source := range SplitSources(b) {
+		if !containsSource(out, source) {
+			out = append(out, source)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// SplitSources splits a comma-separated source list.
+func SplitSources(sources string) []string {
+	var out []string
+	for _, source := range strings.Split(sources, ",") {
+		if source = strings.TrimSpace(source); source != "" && !containsSource(out, source) {
+			out = append(out, source)
+		}
+	}
+	return out
+}
+
+func containsSource(list []string, source string) bool {
+	for _, item := range list {
+		if item == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// CertificateFromX509 fills the certificate fields of a TLSCertificate from
+// cert. The endpoint fields (host, IP, port, URL, source) are left to the
+// caller.
+func CertificateFromX509(cert *x509.Certificate) TLSCertificate {
+	sum := sha256.Sum256(cert.Raw)
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	for _, name := range cert.DNSNames {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			sans = append(sans, name)
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return TLSCertificate{
+		Subject:            cert.Subject.String(),
+		SubjectCN:          cert.Subject.CommonName,
+		Issuer:             cert.Issuer.String(),
+		IssuerCN:           cert.Issuer.CommonName,
+		SANs:               sans,
+		NotBefore:          cert.NotBefore.UTC(),
+		NotAfter:           cert.NotAfter.UTC(),
+		Serial:             strings.ToUpper(cert.SerialNumber.Text(16)),
+		KeyType:            certificateKeyType(cert),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		FingerprintSHA256:  hex.EncodeToString(sum[:]),
+		SelfSigned:         isSelfSigned(cert),
+	}
+}
+
+func certificateKeyType(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA-" + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return cert.PublicKeyAlgorithm.String()
+}
+
+// isSelfSigned reports whether cert is signed by its own key. Issuer and
+// subject must match first, so ordinary CA-issued leaves are not checked.
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	return cert.CheckSignatureFrom(cert) == nil
+}
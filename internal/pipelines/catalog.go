@@ -0,0 +1,152 @@
+package pipelines
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"hunter/internal/engine"
+	"hunter/internal/plugins"
+)
+
+//go:embed builtin/*.json
+var builtinFS embed.FS
+
+// definitionCache holds the parsed definitions until the set of files in
+// PIPELINE_DEFS_DIR, or the modification time of one of them, changes.
+var definitionCache struct {
+	mu    sync.Mutex
+	stamp string
+	defs  map[string]engine.PipelineDefinition
+}
+
+// Load returns the built-in pipeline definitions merged with any *.json
+// definitions found in PIPELINE_DEFS_DIR. Definitions from disk override
+// built-ins with the same name.
+func Load() (map[string]engine.PipelineDefinition, error) {
+	files, stamp, err := definitionFiles(strings.TrimSpace(os.Getenv("PIPELINE_DEFS_DIR")))
+	if err != nil {
+		return nil, err
+	}
+
+	definitionCache.mu.Lock()
+	defer definitionCache.mu.Unlock()
+	if definitionCache.defs == nil || definitionCache.stamp != stamp {
+		defs, err := load(files)
+		if err != nil {
+			return nil, err
+		}
+		definitionCache.defs, definitionCache.stamp = defs, stamp
+	}
+	out := make(map[string]engine.PipelineDefinition, len(definitionCache.defs))
+	for name, def := range definitionCache.defs {
+		out[name] = def
+	}
+	return out, nil
+}
+
+// definitionFiles lists the definition files in dir and a stamp that changes
+// whenever one of them is added, removed or modified.
+func definitionFiles(dir string) ([]string, string, error) {
+	if dir == "" {
+		return nil, "", nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, "", err
+	}
+	var stamp strings.Builder
+	stamp.WriteString(dir)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, "", err
+		}
+		fmt.Fprintf(&stamp, "|%s:%d:%d", file, info.ModTime().UnixNano(), info.Size())
+	}
+	return files, stamp.String(), nil
+}
+
+func load(files []string) (map[string]engine.PipelineDefinition, error) {
+	defs := make(map[string]engine.PipelineDefinition)
+
+	entries, err := builtinFS.ReadDir("builtin")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		raw, err := builtinFS.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		def, err := Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("builtin %s: %v", entry.Name(), err)
+		}
+		defs[def.Name] = def
+	}
+
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		def, err := Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		defs[def.Name] = def
+	}
+	return defs, nil
+}
+
+// Get returns the named pipeline definition.
+func Get(name string) (engine.PipelineDefinition, error) {
+	defs, err := Load()
+	if err != nil {
+		return engine.PipelineDefinition{}, err
+	}
+	def, ok := defs[strings.TrimSpace(name)]
+	if !ok {
+		return engine.PipelineDefinition{}, fmt.Errorf("pipeline not found: %s", name)
+	}
+	return def, nil
+}
+
+// List returns all pipeline definitions sorted by name.
+func List() ([]engine.PipelineDefinition, error) {
+	defs, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]engine.PipelineDefinition, 0, len(defs))
+	for _, def := range defs {
+		out = append(out, def)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Parse decodes and validates one JSON pipeline definition. Every node must
+// reference a registered plugin.
+func Parse(raw []byte) (engine.PipelineDefinition, error) {
+	var def engine.PipelineDefinition
+	if err := json.Unmarshal(raw, &def); err != nil {
+		return def, err
+	}
+	def.Name = strings.TrimSpace(def.Name)
+	if err := def.Validate(); err != nil {
+		return def, err
+	}
+	for _, node := range def.Nodes {
+		if !plugins.IsPluginName(node.Scanner) {
+			return def, fmt.Errorf("node %s: unknown scanner %s", node.ID, node.Scanner)
+		}
+	}
+	return def, nil
+}
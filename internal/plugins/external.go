@@ -0,0 +1,85 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"hunter/internal/engine"
+	"hunter/internal/plugins/external"
+)
+
+var externalOnce sync.Once
+
+// LoadExternalPluginsFromEnv registers the exec plugins found in
+// EXEC_PLUGIN_DIR. It is safe to call more than once; only the first call
+// reads the directory.
+func LoadExternalPluginsFromEnv() {
+	externalOnce.Do(func() {
+		dir := strings.TrimSpace(os.Getenv("EXEC_PLUGIN_DIR"))
+		if dir == "" {
+			return
+		}
+		n, err := LoadExternalPlugins(dir)
+		if err != nil {
+			log.Printf("[Plugins] load external plugins from %s failed: %v", dir, err)
+		}
+		if n > 0 {
+			log.Printf("[Plugins] registered %d external plugins from %s", n, dir)
+		}
+	})
+}
+
+// LoadExternalPlugins registers every plugin manifest in dir and returns how
+// many were registered. Manifests that clash with an existing plugin name are
+// skipped.
+func LoadExternalPlugins(dir string) (int, error) {
+	manifests, err := external.LoadManifests(dir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	var errs []string
+	for _, manifest := range manifests {
+		manifest := manifest
+		info := PluginInfo{
+			Name:        manifest.Name,
+			Category:    strings.ToLower(strings.TrimSpace(manifest.Category)),
+			Description: manifest.Description,
+			Inputs:      manifest.Inputs,
+			Outputs:     manifest.Outputs,
+			Binary:      manifest.Command,
+			External:    true,
+			Probe:       external.NewExecPlugin(manifest).Available,
+		}
+		err := register(info, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+			return external.NewExecPlugin(manifest)
+		})
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		count++
+	}
+	if len(errs) > 0 {
+		return count, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return count, nil
+}
+
+// ExternalScanners builds every registered external plugin of category so
+// fixed scan and monitor stages can run them next to the built-in scanners.
+func ExternalScanners(category string, cfg ScannerConfig) []engine.Scanner {
+	var out []engine.Scanner
+	for _, info := range ListPlugins() {
+		if !info.External || info.Category != category {
+			continue
+		}
+		if scanner, err := NewScannerByName(info.Name, cfg, nil); err == nil {
+			out = append(out, scanner)
+		}
+	}
+	return out
+}
@@ -0,0 +1,239 @@
+package external
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hunter/internal/engine"
+)
+
+// Manifest describes an external plugin. It is read from a *.json file in the
+// plugin directory.
+type Manifest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Category    string            `json:"category"`
+	Command     string            `json:"command"`
+	Args        []string          `json:"args"`
+	Env         map[string]string `json:"env"`
+	Inputs      []string          `json:"inputs"`
+	Outputs     []string          `json:"outputs"`
+	TimeoutSec  int               `json:"timeoutSec"`
+
+	// Dir is the directory the manifest was loaded from. Relative command
+	// paths are resolved against it.
+	Dir string `json:"-"`
+}
+
+// Input is one JSONL line written to the plugin's stdin.
+type Input struct {
+	Target     string `json:"target"`
+	RootDomain string `json:"root_domain,omitempty"`
+}
+
+// Envelope is one JSONL line read from the plugin's stdout.
+type Envelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// ExecPlugin runs an external command that speaks the JSONL stdio protocol:
+// targets go in on stdin, result envelopes come back on stdout, and stderr is
+// passed through as log output.
+type ExecPlugin struct {
+	manifest Manifest
+}
+
+// NewExecPlugin creates an exec plugin for manifest.
+func NewExecPlugin(manifest Manifest) *ExecPlugin {
+	return &ExecPlugin{manifest: manifest}
+}
+
+// Name returns plugin name.
+func (e *ExecPlugin) Name() string {
+	return e.manifest.Name
+}
+
+// Execute runs the external command and returns all parsed results.
+func (e *ExecPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	return e.ExecuteStream(ctx, input, nil)
+}
+
+// ExecuteStream runs the external command and hands each result to emit as
+// soon as its line is parsed.
+func (e *ExecPlugin) ExecuteStream(ctx context.Context, input []string, emit engine.ResultHandler) ([]engine.Result, error) {
+	command, err := e.resolveCommand()
+	if err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return []engine.Result{}, nil
+	}
+
+	tag := e.manifest.Name
+	fmt.Printf("[%s] Running external plugin on %d targets...\n", tag, len(input))
+
+	if e.manifest.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(e.manifest.TimeoutSec)*time.Second)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command, e.manifest.Args...)
+	cmd.Dir = e.manifest.Dir
+	cmd.Env = os.Environ()
+	for key, value := range e.manifest.Env {
+		cmd.Env = append(cmd.Env, key+"="+os.ExpandEnv(value))
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %v", tag, err)
+	}
+
+	go func() {
+		defer stdin.Close()
+		encoder := json.NewEncoder(stdin)
+		for _, item := range input {
+			if err := encoder.Encode(splitTarget(item)); err != nil {
+				return
+			}
+		}
+	}()
+
+	allowed := make(map[string]bool, len(e.manifest.Outputs))
+	for _, t := range e.manifest.Outputs {
+		allowed[strings.TrimSpace(t)] = true
+	}
+
+	var results []engine.Result
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		result, err := decodeEnvelope([]byte(line))
+		if err != nil {
+			fmt.Printf("[%s] Skipping invalid output line: %v\n", tag, err)
+			continue
+		}
+		if len(allowed) > 0 && !allowed[result.Type] {
+			fmt.Printf("[%s] Skipping undeclared result type %q\n", tag, result.Type)
+			continue
+		}
+		results = append(results, result)
+		if emit != nil {
+			emit(result)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			fmt.Printf("[%s] Timed out after %ds, keeping partial results\n", tag, e.manifest.TimeoutSec)
+		} else {
+			// Keep behavior tolerant: partial results are still useful.
+			fmt.Printf("[%s] Command finished with warning: %v\n", tag, err)
+		}
+	}
+
+	fmt.Printf("[%s] Completed, emitted %d results\n", tag, len(results))
+	return results, nil
+}
+
+// Available reports whether the manifest command can be found.
+func (e *ExecPlugin) Available() bool {
+	_, err := e.resolveCommand()
+	return err == nil
+}
+
+func (e *ExecPlugin) resolveCommand() (string, error) {
+	command := strings.TrimSpace(e.manifest.Command)
+	if strings.ContainsRune(command, filepath.Separator) && !filepath.IsAbs(command) {
+		command = filepath.Join(e.manifest.Dir, command)
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", fmt.Errorf("%s not found in PATH. Please install it or fix the command in the %s plugin manifest", e.manifest.Command, e.manifest.Name)
+	}
+	return path, nil
+}
+
+// splitTarget turns "target|rootDomain" inputs used by vuln scanners into
+// their JSON form.
+func splitTarget(item string) Input {
+	target, root, _ := strings.Cut(item, "|")
+	return Input{Target: strings.TrimSpace(target), RootDomain: strings.TrimSpace(root)}
+}
+
+func decodeEnvelope(line []byte) (engine.Result, error) {
+	var env Envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return engine.Result{}, err
+	}
+	env.Type = strings.TrimSpace(env.Type)
+	if env.Type == "" {
+		return engine.Result{}, fmt.Errorf("missing type")
+	}
+	if len(env.Data) == 0 {
+		return engine.Result{}, fmt.Errorf("missing data")
+	}
+	var data interface{}
+	if err := json.Unmarshal(env.Data, &data); err != nil {
+		return engine.Result{}, err
+	}
+	return engine.Result{Type: env.Type, Data: data}, nil
+}
+
+// LoadManifests reads every *.json manifest in dir. Invalid manifests are
+// logged and skipped so one bad file does not disable the other plugins.
+func LoadManifests(dir string) ([]Manifest, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]Manifest, 0, len(files))
+	for _, file := range files {
+		manifest, err := loadManifest(file)
+		if err != nil {
+			log.Printf("[Plugins] skip manifest %s: %v", file, err)
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+func loadManifest(file string) (Manifest, error) {
+	var manifest Manifest
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return manifest, err
+	}
+	manifest.Name = strings.ToLower(strings.TrimSpace(manifest.Name))
+	if manifest.Name == "" || strings.TrimSpace(manifest.Command) == "" {
+		return manifest, fmt.Errorf("name and command are required")
+	}
+	manifest.Dir = filepath.Dir(file)
+	return manifest, nil
+}
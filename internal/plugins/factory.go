@@ -0,0 +1,280 @@
+package plugins
+
+import (
+	"hunter/internal/engine"
+)
+
+// ScannerConfig carries job-level settings that some scanners need at
+// construction time.
+type ScannerConfig struct {
+	RootDomains   []string
+	ScreenshotDir string
+	DNSResolvers  string
+	DictSize      int
+}
+
+var batchOption = PluginOption{
+	Name:        "batch",
+	Type:        "bool",
+	Description: "Query all root domains in one run (defaults to true for multi-root jobs)",
+}
+
+func batchMode(cfg ScannerConfig, options map[string]string) bool {
+	return optionBool(options, "batch", len(cfg.RootDomains) > 1)
+}
+
+func init() {
+	Register(PluginInfo{
+		Name:        "subfinder",
+		Category:    CategorySubdomain,
+		Description: "Passive subdomain enumeration",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain"},
+		Binary:      "subfinder",
+		Options:     []PluginOption{batchOption},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewSubfinderPlugin(batchMode(cfg, options))
+	})
+	Register(PluginInfo{
+		Name:        "chaos",
+		Category:    CategorySubdomain,
+		Description: "ProjectDiscovery Chaos dataset lookup",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain"},
+		Binary:      "chaos",
+		Options:     []PluginOption{batchOption},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewChaosPlugin(batchMode(cfg, options))
+	})
+	Register(PluginInfo{
+		Name:        "findomain",
+		Category:    CategorySubdomain,
+		Description: "Passive subdomain enumeration",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain"},
+		Binary:      "findomain",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewFindomainPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "bbot",
+		Category:    CategorySubdomain,
+		Description: "BBOT passive subdomain enumeration",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain"},
+		Binary:      "bbot",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewBBOTPlugin(true)
+	})
+	Register(PluginInfo{
+		Name:        "bbot_active",
+		Category:    CategorySubdomain,
+		Description: "BBOT active subdomain enumeration",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain"},
+		Binary:      "bbot",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewBBOTPlugin(false)
+	})
+	Register(PluginInfo{
+		Name:        "shosubgo",
+		Category:    CategorySubdomain,
+		Description: "Shodan subdomain lookup",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain"},
+		Binary:      "shosubgo",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewShosubgoPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "ctlogs",
+		Aliases:     []string{"crtsh", "certspotter"},
+		Category:    CategorySubdomain,
+		Description: "Certificate Transparency log search (crt.sh, Certspotter)",
+		Inputs:      []string{InputRootDomain},
+		Outputs:     []string{"domain", "ct_certificate"},
+		Options: []PluginOption{
+			{Name: "crtsh_url", Type: "string", Default: "https://crt.sh", Description: "crt.sh base URL, or off"},
+			{Name: "certspotter_url", Type: "string", Default: "https://api.certspotter.com", Description: "Certspotter API base URL, or off"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewCTLogsPlugin(optionString(options, "crtsh_url", ""), optionString(options, "certspotter_url", ""))
+	})
+	Register(PluginInfo{
+		Name:        "zonetransfer",
+		Aliases:     []string{"axfr", "nsec_walk"},
+		Category:    CategorySubdomain,
+		Description: "Attempt AXFR against each authoritative nameserver, falling back to NSEC zone walking",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"domain", "dns_zone_exposure"},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewZoneTransferPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "dictgen",
+		Category:    CategorySubdomain,
+		Description: "Build a brute-force wordlist from known subdomains",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"dict_word"},
+		Options: []PluginOption{
+			{Name: "dict_size", Type: "int", Default: "5000", Description: "Maximum number of generated words"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewDictgenPlugin(optionInt(options, "dict_size", cfg.DictSize))
+	})
+	Register(PluginInfo{
+		Name:        "permutations",
+		Aliases:     []string{"alterations"},
+		Category:    CategorySubdomain,
+		Description: "Resolve altdns-style permutations (number increments, env swaps, label insertion) of known subdomains",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"domain"},
+		Options: []PluginOption{
+			{Name: "max_candidates", Type: "int", Default: "5000", Description: "Maximum number of generated candidates"},
+			{Name: "resolvers", Type: "string", Description: "Resolver list file for the built-in resolver"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewPermutationsPlugin(cfg.RootDomains, optionString(options, "resolvers", cfg.DNSResolvers), optionInt(options, "max_candidates", 0))
+	})
+	Register(PluginInfo{
+		Name:        "dnsx_bruteforce",
+		Category:    CategorySubdomain,
+		Description: "Resolve brute-force candidates with dnsx",
+		Inputs:      []string{"dict_word"},
+		Outputs:     []string{"domain"},
+		Binary:      "dnsx",
+		Options: []PluginOption{
+			{Name: "resolvers", Type: "string", Description: "Resolver list file passed to dnsx"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewDNSXBruteforcePlugin(cfg.RootDomains, optionString(options, "resolvers", cfg.DNSResolvers))
+	})
+	Register(PluginInfo{
+		Name:        "dns_bruteforce",
+		Category:    CategorySubdomain,
+		Description: "Resolve brute-force candidates with the built-in resolver and drop wildcard hits",
+		Inputs:      []string{"dict_word"},
+		Outputs:     []string{"domain"},
+		Options: []PluginOption{
+			{Name: "resolvers", Type: "string", Description: "Resolver list file, one IP per line (defaults to the system resolver)"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewDNSBruteforcePlugin(cfg.RootDomains, optionString(options, "resolvers", cfg.DNSResolvers))
+	})
+	Register(PluginInfo{
+		Name:        "dns_records",
+		Aliases:     []string{"dns"},
+		Category:    CategorySubdomain,
+		Description: "Collect A, AAAA, CNAME, MX, NS and TXT records of each host with the built-in resolver",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"dns_records"},
+		Options: []PluginOption{
+			{Name: "resolvers", Type: "string", Description: "Resolver list file, one IP per line (defaults to the system resolver)"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewDNSRecordsPlugin(optionString(options, "resolvers", cfg.DNSResolvers))
+	})
+	Register(PluginInfo{
+		Name:        "httpx",
+		Category:    CategoryWeb,
+		Description: "HTTP probing and fingerprinting",
+		Inputs:      []string{"domain", "open_port"},
+		Outputs:     []string{"web_service", "tls_certificate"},
+		Binary:      "httpx",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewHttpxPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "http_probe",
+		Category:    CategoryWeb,
+		Description: "Built-in HTTP(S) prober on standard and alternate web ports: status, title, IP, server, redirect chain and technologies",
+		Inputs:      []string{"domain", "open_port"},
+		Outputs:     []string{"web_service", "tls_certificate"},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewHTTPProbePlugin()
+	})
+	Register(PluginInfo{
+		Name:        "gowitness",
+		Aliases:     []string{"witness"},
+		Category:    CategoryWeb,
+		Description: "Web page screenshots",
+		Inputs:      []string{"web_service"},
+		Outputs:     []string{"screenshot"},
+		Binary:      "gowitness",
+		Options: []PluginOption{
+			{Name: "screenshot_dir", Type: "string", Description: "Directory screenshots are written to"},
+		},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewGowitnessPlugin(optionString(options, "screenshot_dir", cfg.ScreenshotDir))
+	})
+	Register(PluginInfo{
+		Name:        "naabu",
+		Category:    CategoryPort,
+		Description: "Fast TCP port scanning",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"open_port"},
+		Binary:      "naabu",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewNaabuPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "nmap",
+		Category:    CategoryPort,
+		Description: "Service detection on open ports",
+		Inputs:      []string{"open_port"},
+		Outputs:     []string{"port_service"},
+		Binary:      "nmap",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewNmapPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "tscan",
+		Aliases:     []string{"tscanclient"},
+		Category:    CategoryPort,
+		Description: "Port scanning and service identification with tscanclient",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"open_port", "port_service"},
+		Binary:      "tscanclient",
+		Probe:       TscanAvailable,
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewTscanPortPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "tls_grab",
+		Category:    CategoryPort,
+		Description: "TLS handshake with open ports to record the served certificate",
+		Inputs:      []string{"open_port"},
+		Outputs:     []string{"tls_certificate"},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewTLSGrabPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "nuclei",
+		Category:    CategoryVuln,
+		Description: "Template-based vulnerability scanning",
+		Inputs:      []string{"web_service"},
+		Outputs:     []string{"vulnerability"},
+		Binary:      "nuclei",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewNucleiPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "cors",
+		Category:    CategoryVuln,
+		Description: "CORS misconfiguration checks",
+		Inputs:      []string{"web_service"},
+		Outputs:     []string{"vulnerability"},
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewCorsPlugin()
+	})
+	Register(PluginInfo{
+		Name:        "subtakeover",
+		Category:    CategoryVuln,
+		Description: "Subdomain takeover detection",
+		Inputs:      []string{"domain"},
+		Outputs:     []string{"vulnerability"},
+		Binary:      "subjack",
+	}, func(cfg ScannerConfig, options map[string]string) engine.Scanner {
+		return NewSubTakeoverPlugin()
+	})
+}
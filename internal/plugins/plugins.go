@@ -35,18 +35,82 @@ func NewShosubgoPlugin() engine.Scanner {
 	return subdomain.NewShosubgoPlugin()
 }
 
+func NewCTLogsPlugin(crtshURL, certspotterURL string) engine.Scanner {
+	return subdomain.NewCTLogsPlugin(crtshURL, certspotterURL)
+}
+
 func NewDictgenPlugin(maxWords int) engine.Scanner {
 	return subdomain.NewDictgenPlugin(maxWords)
 }
 
+func NewZoneTransferPlugin() engine.Scanner {
+	return subdomain.NewZoneTransferPlugin()
+}
+
+func NewPermutationsPlugin(rootDomains []string, resolversFile string, maxCandidates int) engine.Scanner {
+	return subdomain.NewPermutationsPlugin(rootDomains, resolversFile, maxCandidates)
+}
+
 func NewDNSXBruteforcePlugin(rootDomains []string, resolversFile string) engine.Scanner {
 	return subdomain.NewDNSXBruteforcePlugin(rootDomains, resolversFile)
 }
 
+func NewDNSBruteforcePlugin(rootDomains []string, resolversFile string) engine.Scanner {
+	return subdomain.NewDNSBruteforcePlugin(rootDomains, resolversFile)
+}
+
+func NewDNSRecordsPlugin(resolversFile string) engine.Scanner {
+	return subdomain.NewDNSRecordsPlugin(resolversFile)
+}
+
+// ActiveBruteforceEngine returns the registry name of the brute-force plugin
+// that active subdomain expansion uses (see DNS_BRUTEFORCE_ENGINE).
+func ActiveBruteforceEngine() string {
+	return subdomain.ActiveBruteforceEngine()
+}
+
+// PinnedActiveBruteforceEngine returns the brute-force plugin pinned by
+// DNS_BRUTEFORCE_ENGINE, or "" when it is chosen at run time.
+func PinnedActiveBruteforceEngine() string {
+	return subdomain.PinnedActiveBruteforceEngine()
+}
+
+// NewActiveBruteforcePlugin returns the plugin named by ActiveBruteforceEngine.
+func NewActiveBruteforcePlugin(rootDomains []string, resolversFile string) engine.Scanner {
+	if ActiveBruteforceEngine() == "dns_bruteforce" {
+		return NewDNSBruteforcePlugin(rootDomains, resolversFile)
+	}
+	return NewDNSXBruteforcePlugin(rootDomains, resolversFile)
+}
+
 func NewHttpxPlugin() engine.Scanner {
 	return web.NewHttpxPlugin()
 }
 
+func NewHTTPProbePlugin() engine.Scanner {
+	return web.NewHTTPProbePlugin()
+}
+
+// HTTPProbeEngine returns the registry name of the web prober the network
+// stage uses (see HTTP_PROBE_ENGINE).
+func HTTPProbeEngine() string {
+	return web.HTTPProbeEngine()
+}
+
+// PinnedHTTPProbeEngine returns the web prober pinned by HTTP_PROBE_ENGINE,
+// or "" when it is chosen at run time.
+func PinnedHTTPProbeEngine() string {
+	return web.PinnedHTTPProbeEngine()
+}
+
+// NewWebProbePlugin returns the plugin named by HTTPProbeEngine.
+func NewWebProbePlugin() engine.Scanner {
+	if HTTPProbeEngine() == "http_probe" {
+		return NewHTTPProbePlugin()
+	}
+	return NewHttpxPlugin()
+}
+
 func NewGowitnessPlugin(baseDir string) engine.Scanner {
 	return web.NewGowitnessPlugin(baseDir)
 }
@@ -63,6 +127,15 @@ func NewTscanPortPlugin() engine.Scanner {
 	return pluginport.NewTscanPortPlugin()
 }
 
+func NewTLSGrabPlugin() engine.Scanner {
+	return pluginport.NewTLSGrabPlugin()
+}
+
+// TscanAvailable reports whether a tscanclient binary can be found.
+func TscanAvailable() bool {
+	return pluginport.TscanAvailable()
+}
+
 func NewNucleiPlugin() engine.Scanner {
 	return vuln.NewNucleiPlugin()
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"hunter/internal/engine"
@@ -34,6 +35,11 @@ func (n *NaabuPlugin) Name() string {
 
 // Execute runs naabu and excludes 80/443 since httpx already covers web services.
 func (n *NaabuPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	return n.ExecuteStream(ctx, input, nil)
+}
+
+// ExecuteStream runs naabu and hands each open port to emit as it is reported.
+func (n *NaabuPlugin) ExecuteStream(ctx context.Context, input []string, emit engine.ResultHandler) ([]engine.Result, error) {
 	if _, err := exec.LookPath("naabu"); err != nil {
 		return nil, fmt.Errorf("naabu not found in PATH. Please install naabu and ensure it's in your PATH")
 	}
@@ -57,13 +63,22 @@ func (n *NaabuPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 	}
 	_ = tmpFile.Close()
 
-	cmd := exec.CommandContext(ctx, "naabu",
+	args := []string{
 		"-list", tmpFile.Name(),
 		"-top-ports", "1000",
 		"-exclude-ports", "80,443",
 		"-json",
 		"-silent",
-	)
+	}
+	// naabu defaults: -rate 1000 packets/s, -c 25 workers.
+	rate, workers := engine.HostLimiterFromContext(ctx).Budget().Scale(engine.CountHosts(input), 1000, 25)
+	if rate > 0 {
+		args = append(args, "-rate", strconv.Itoa(rate))
+	}
+	if workers > 0 {
+		args = append(args, "-c", strconv.Itoa(workers))
+	}
+	cmd := exec.CommandContext(ctx, "naabu", args...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -100,15 +115,16 @@ func (n *NaabuPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 
 		portCount++
 
-		results = append(results, engine.Result{
-			Type: "open_port",
-			Data: map[string]interface{}{
-				"host":   naabuResult.Host,
-				"domain": naabuResult.Host,
-				"ip":     naabuResult.IP,
-				"port":   naabuResult.Port,
-			},
-		})
+		result := engine.OpenPort{
+			Host:   naabuResult.Host,
+			Domain: naabuResult.Host,
+			IP:     naabuResult.IP,
+			Port:   naabuResult.Port,
+		}.Result()
+		results = append(results, result)
+		if emit != nil {
+			emit(result)
+		}
 	}
 
 	if err := cmd.Wait(); err != nil {
@@ -225,17 +225,14 @@ func parseNmapXMLOutput(output []byte, fallbackIP string, host string) ([]engine
 				versionParts = append(versionParts, "("+s+")")
 			}
 
-			results = append(results, engine.Result{
-				Type: "port_service",
-				Data: map[string]interface{}{
-					"ip":       ip,
-					"port":     p.PortID,
-					"protocol": strings.TrimSpace(p.Protocol),
-					"service":  service,
-					"version":  strings.Join(versionParts, " "),
-					"domain":   host,
-				},
-			})
+			results = append(results, engine.PortService{
+				IP:       ip,
+				Port:     p.PortID,
+				Protocol: strings.TrimSpace(p.Protocol),
+				Service:  service,
+				Version:  strings.Join(versionParts, " "),
+				Domain:   host,
+			}.Result())
 		}
 	}
 
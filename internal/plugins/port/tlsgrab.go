@@ -0,0 +1,158 @@
+package port
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hunter/internal/engine"
+)
+
+// TLSGrabPlugin completes a TLS handshake with every open port found by the
+// port scan chain and records the leaf certificate. Ports that do not speak
+// TLS fail the handshake and are skipped.
+type TLSGrabPlugin struct {
+	timeout     time.Duration
+	concurrency int
+}
+
+// tlsGrabTarget is one "ip:port:host" input of the port chain.
+type tlsGrabTarget struct {
+	ip   string
+	port int
+	host string
+}
+
+// NewTLSGrabPlugin creates a certificate grabber configured from
+// TLS_GRAB_TIMEOUT_MS and TLS_GRAB_CONCURRENCY.
+func NewTLSGrabPlugin() *TLSGrabPlugin {
+	return &TLSGrabPlugin{
+		timeout:     time.Duration(envIntWithBounds("TLS_GRAB_TIMEOUT_MS", 5000, 200, 60000)) * time.Millisecond,
+		concurrency: envIntWithBounds("TLS_GRAB_CONCURRENCY", 50, 1, 1000),
+	}
+}
+
+// Name returns plugin name.
+func (t *TLSGrabPlugin) Name() string {
+	return "TLSGrab"
+}
+
+// Execute grabs the certificates served on "ip:port:host" inputs. The host,
+// when present, is sent as SNI so virtual hosts return their own certificate.
+func (t *TLSGrabPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	targets := parseTLSGrabTargets(input)
+	if len(targets) == 0 {
+		return []engine.Result{}, nil
+	}
+	fmt.Printf("[TLSGrab] Collecting certificates from %d open ports...\n", len(targets))
+
+	jobs := make(chan tlsGrabTarget)
+	var (
+		mu      sync.Mutex
+		results []engine.Result
+		wg      sync.WaitGroup
+	)
+	workers := min(t.concurrency, len(targets))
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				cert, ok := t.grab(ctx, target)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				results = append(results, cert.Result())
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, target := range targets {
+		select {
+		case jobs <- target:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	fmt.Printf("[TLSGrab] Completed, %d TLS endpoints\n", len(results))
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func (t *TLSGrabPlugin) grab(ctx context.Context, target tlsGrabTarget) (engine.TLSCertificate, bool) {
+	name := target.host
+	if name == "" {
+		name = target.ip
+	}
+	addr := net.JoinHostPort(target.ip, strconv.Itoa(target.port))
+	release, err := engine.HostLimiterFromContext(ctx).Acquire(ctx, name, target.ip)
+	if err != nil {
+		return engine.TLSCertificate{}, false
+	}
+	defer release()
+
+	config := &tls.Config{
+		// The certificate is recorded, not trusted: expired, self-signed and
+		// mismatched ones are exactly what the inventory is for.
+		InsecureSkipVerify: true,
+	}
+	if net.ParseIP(name) == nil {
+		config.ServerName = name
+	}
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: t.timeout}, Config: config}
+	dialCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	conn, err := dialer.DialContext(dialCtx, "tcp", addr)
+	if err != nil {
+		return engine.TLSCertificate{}, false
+	}
+	defer conn.Close()
+
+	peers := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return engine.TLSCertificate{}, false
+	}
+	cert := engine.CertificateFromX509(peers[0])
+	cert.Host = name
+	cert.IP = target.ip
+	cert.Port = target.port
+	cert.Source = "tls_grab"
+	return cert, true
+}
+
+// parseTLSGrabTargets parses and de-duplicates "ip:port:host" inputs.
+func parseTLSGrabTargets(input []string) []tlsGrabTarget {
+	var out []tlsGrabTarget
+	seen := make(map[tlsGrabTarget]bool)
+	for _, item := range input {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 3)
+		if len(parts) < 2 || net.ParseIP(parts[0]) == nil {
+			continue
+		}
+		port, err := strconv.Atoi(parts[1])
+		if err != nil || port <= 0 || port > 65535 {
+			continue
+		}
+		target := tlsGrabTarget{ip: parts[0], port: port}
+		if len(parts) == 3 {
+			target.host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(parts[2])), ".")
+		}
+		if !seen[target] {
+			seen[target] = true
+			out = append(out, target)
+		}
+	}
+	return out
+}
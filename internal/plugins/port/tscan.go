@@ -110,13 +110,19 @@ func (t *TscanPortPlugin) Execute(ctx context.Context, input []string) ([]engine
 	projectName := "myrecon-" + strconv.FormatInt(time.Now().UnixNano(), 10)
 	outputPath := filepath.Join(workDir, "port-output.txt")
 
+	concurrency := t.concurrency
+	if _, budgeted := engine.HostLimiterFromContext(ctx).Budget().Scale(len(ipTargets), 0, t.concurrency); budgeted > 0 {
+		concurrency = budgeted
+		fmt.Printf("[Tscan] Host budget caps concurrency at %d for %d targets\n", concurrency, len(ipTargets))
+	}
+
 	args := []string{
 		"-m", "port",
 		"-hf", targetFile,
 		"-pr", projectName,
 		"-o", outputPath,
 		"-nocolor",
-		"-t", strconv.Itoa(t.concurrency),
+		"-t", strconv.Itoa(concurrency),
 		"-time", strconv.Itoa(t.timeoutSec),
 	}
 	if t.ports != "" {
@@ -344,6 +350,13 @@ func copyFile(src, dst string, perm os.FileMode) error {
 	return nil
 }
 
+// TscanAvailable reports whether a tscanclient binary can be found in PATH or
+// one of the well-known install locations.
+func TscanAvailable() bool {
+	_, err := resolveTscanBinary()
+	return err == nil
+}
+
 func resolveTscanBinary() (string, error) {
 	candidates := []string{
 		"tscanclient",
@@ -609,16 +622,13 @@ func buildTscanResults(rows []tscanIPScanRow, ipHosts map[string][]string) []eng
 			openKey := fmt.Sprintf("%s|%d|%s", row.Host, row.Port, host)
 			if !seenOpen[openKey] {
 				seenOpen[openKey] = true
-				results = append(results, engine.Result{
-					Type: "open_port",
-					Data: map[string]interface{}{
-						"host":     host,
-						"domain":   host,
-						"ip":       row.Host,
-						"port":     row.Port,
-						"protocol": protocol,
-					},
-				})
+				results = append(results, engine.OpenPort{
+					Host:     host,
+					Domain:   host,
+					IP:       row.Host,
+					Port:     row.Port,
+					Protocol: protocol,
+				}.Result())
 			}
 
 			if service == "" && version == "" && banner == "" {
@@ -631,19 +641,16 @@ func buildTscanResults(rows []tscanIPScanRow, ipHosts map[string][]string) []eng
 			}
 			seenService[serviceKey] = true
 
-			results = append(results, engine.Result{
-				Type: "port_service",
-				Data: map[string]interface{}{
-					"domain":   host,
-					"host":     host,
-					"ip":       row.Host,
-					"port":     row.Port,
-					"protocol": protocol,
-					"service":  service,
-					"version":  version,
-					"banner":   banner,
-				},
-			})
+			results = append(results, engine.PortService{
+				Domain:   host,
+				Host:     host,
+				IP:       row.Host,
+				Port:     row.Port,
+				Protocol: protocol,
+				Service:  service,
+				Version:  version,
+				Banner:   banner,
+			}.Result())
 		}
 	}
 
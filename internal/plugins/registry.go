@@ -0,0 +1,224 @@
+package plugins
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"hunter/internal/engine"
+)
+
+// Plugin categories.
+const (
+	CategorySubdomain = "subdomain"
+	CategoryPort      = "port"
+	CategoryWeb       = "web"
+	CategoryVuln      = "vuln"
+)
+
+// InputRootDomain marks scanners that take root domains rather than the
+// results of another scanner.
+const InputRootDomain = "root_domain"
+
+// PluginOption describes one option a plugin accepts in pipeline node
+// options.
+type PluginOption struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PluginInfo is the capability metadata of a registered scanner.
+type PluginInfo struct {
+	Name        string         `json:"name"`
+	Aliases     []string       `json:"aliases,omitempty"`
+	Category    string         `json:"category"`
+	Description string         `json:"description"`
+	Inputs      []string       `json:"inputs"`
+	Outputs     []string       `json:"outputs"`
+	Binary      string         `json:"binary,omitempty"`
+	Options     []PluginOption `json:"options,omitempty"`
+	External    bool           `json:"external"`
+
+	// Probe reports whether the plugin can run on this host. When nil the
+	// plugin is available if Binary is empty or found in PATH.
+	Probe func() bool `json:"-"`
+
+	factory func(cfg ScannerConfig, options map[string]string) engine.Scanner
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*PluginInfo)
+	aliases    = make(map[string]string)
+)
+
+// Register adds a scanner to the registry. It panics on duplicate names so
+// wiring mistakes surface at startup.
+func Register(info PluginInfo, factory func(cfg ScannerConfig, options map[string]string) engine.Scanner) {
+	if err := register(info, factory); err != nil {
+		panic("plugins: " + err.Error())
+	}
+}
+
+func register(info PluginInfo, factory func(cfg ScannerConfig, options map[string]string) engine.Scanner) error {
+	name := strings.ToLower(strings.TrimSpace(info.Name))
+	if name == "" || factory == nil {
+		return fmt.Errorf("plugin registration requires a name and a factory")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if lookupLocked(name) != nil {
+		return fmt.Errorf("duplicate plugin %s", name)
+	}
+	info.Name = name
+	info.factory = factory
+	registry[name] = &info
+	for _, alias := range info.Aliases {
+		aliases[strings.ToLower(strings.TrimSpace(alias))] = name
+	}
+	return nil
+}
+
+// LookupPlugin resolves a plugin by name or alias.
+func LookupPlugin(name string) (PluginInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info := lookupLocked(name)
+	if info == nil {
+		return PluginInfo{}, false
+	}
+	return *info, true
+}
+
+func lookupLocked(name string) *PluginInfo {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := aliases[key]; ok {
+		key = canonical
+	}
+	return registry[key]
+}
+
+// ListPlugins returns all registered plugins sorted by category and name.
+func ListPlugins() []PluginInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]PluginInfo, 0, len(registry))
+	for _, info := range registry {
+		out = append(out, *info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Category != out[j].Category {
+			return out[i].Category < out[j].Category
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// IsPluginName reports whether name is a registered plugin or alias.
+func IsPluginName(name string) bool {
+	_, ok := LookupPlugin(name)
+	return ok
+}
+
+// NewScannerByName builds a scanner from its module name. Node options
+// override job-level settings where they apply.
+func NewScannerByName(name string, cfg ScannerConfig, options map[string]string) (engine.Scanner, error) {
+	registryMu.RLock()
+	info := lookupLocked(name)
+	registryMu.RUnlock()
+	if info == nil {
+		return nil, fmt.Errorf("unknown scanner: %s", name)
+	}
+	return info.factory(cfg, options), nil
+}
+
+// AvailablePlugins returns the names of the registered plugins that can run
+// on this host.
+func AvailablePlugins() []string {
+	var out []string
+	for _, info := range ListPlugins() {
+		if info.Available() {
+			out = append(out, info.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Available reports whether the plugin can run on this host.
+func (info PluginInfo) Available() bool {
+	if info.Probe != nil {
+		return info.Probe()
+	}
+	if info.Binary == "" {
+		return true
+	}
+	_, err := exec.LookPath(info.Binary)
+	return err == nil
+}
+
+func optionBool(options map[string]string, key string, fallback bool) bool {
+	raw, ok := options[key]
+	if !ok {
+		return fallback
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func optionInt(options map[string]string, key string, fallback int) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(options[key])); err == nil {
+		return n
+	}
+	return fallback
+}
+
+func optionString(options map[string]string, key, fallback string) string {
+	if raw := strings.TrimSpace(options[key]); raw != "" {
+		return raw
+	}
+	return fallback
+}
+
+// scannerSourceAliases maps lowercased Scanner.Name values that differ from
+// their module name.
+var scannerSourceAliases = map[string]string{
+	"dnsxbruteforce": "dnsx_bruteforce",
+	"dnsbruteforce":  "dns_bruteforce",
+	"tscanport":      "tscan",
+}
+
+// SourceName returns the module name used to attribute results of the
+// scanner whose Name() is scannerName ("DNSXBruteforce" -> "dnsx_bruteforce").
+func SourceName(scannerName string) string {
+	key := strings.ToLower(strings.TrimSpace(scannerName))
+	if alias, ok := scannerSourceAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// ResultSources returns the module names a result is attributed to.
+func ResultSources(r engine.Result) []string {
+	raw := engine.SplitSources(r.Source)
+	out := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, name := range raw {
+		source := SourceName(name)
+		if source == "" || seen[source] {
+			continue
+		}
+		seen[source] = true
+		out = append(out, source)
+	}
+	return out
+}
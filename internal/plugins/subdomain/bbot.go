@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"hunter/internal/engine"
+	"hunter/internal/plugins/common"
 )
 
 // BBOTPlugin implements subdomain collection via BBOT.
@@ -50,6 +51,14 @@ func (b *BBOTPlugin) Execute(ctx context.Context, input []string) ([]engine.Resu
 	}
 	defer os.RemoveAll(scanDir)
 
+	keyConfig, keyProviders, err := bbotKeyConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write bbot key config: %v", err)
+	}
+	if keyConfig != "" {
+		defer common.RemoveTempFile(keyConfig)
+	}
+
 	outputFile := filepath.Join(scanDir, "subdomains.txt")
 	scanName := fmt.Sprintf("hunter_subs_%d", time.Now().UnixNano())
 
@@ -62,6 +71,11 @@ func (b *BBOTPlugin) Execute(ctx context.Context, input []string) ([]engine.Resu
 		"-o", scanDir,
 		"-c", fmt.Sprintf("modules.subdomains.output_file=%s", outputFile),
 	)
+	// -c takes every following key=value or config file; a second -c would
+	// replace the first. Keys go in a file so they never show up in argv.
+	if keyConfig != "" {
+		args = append(args, keyConfig)
+	}
 	if b.passiveOnly {
 		args = append(args, "-rf", "passive", "-ef", "aggressive")
 	}
@@ -76,6 +90,9 @@ func (b *BBOTPlugin) Execute(ctx context.Context, input []string) ([]engine.Resu
 
 	cmd := exec.Command("bbot", args...)
 	output, runErr := runCommandWithContext(runCtx, cmd)
+	if cmd.Process != nil {
+		reportKeyUse(ctx, keyProviders)
+	}
 
 	subdomains, parseErr := readSubdomainsFile(outputFile)
 	if parseErr != nil {
@@ -108,10 +125,7 @@ func (b *BBOTPlugin) Execute(ctx context.Context, input []string) ([]engine.Resu
 
 	results := make([]engine.Result, 0, len(subdomains))
 	for _, subdomain := range subdomains {
-		results = append(results, engine.Result{
-			Type: "domain",
-			Data: subdomain,
-		})
+		results = append(results, engine.Domain(subdomain).Result())
 	}
 
 	fmt.Printf("[BBOT] Found %d subdomains\n", len(results))
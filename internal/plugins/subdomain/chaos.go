@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 
@@ -40,7 +39,7 @@ func (c *ChaosPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 		return []engine.Result{}, nil
 	}
 
-	apiKey := resolveChaosAPIKey()
+	apiKey := resolveChaosAPIKey(ctx)
 
 	fmt.Printf("[Chaos] Running passive enumeration for %d root domains...\n", len(targets))
 
@@ -82,13 +81,8 @@ func (c *ChaosPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 	return results, nil
 }
 
-func resolveChaosAPIKey() string {
-	apiKey := strings.TrimSpace(os.Getenv("CHAOS_KEY"))
-	if apiKey != "" {
-		return apiKey
-	}
-	apiKey = strings.TrimSpace(os.Getenv("PDCP_API_KEY"))
-	if apiKey != "" {
+func resolveChaosAPIKey(ctx context.Context) string {
+	if apiKey := engine.APIKey(ctx, engine.ProviderChaos, "CHAOS_KEY", "PDCP_API_KEY"); apiKey != "" {
 		return apiKey
 	}
 	return defaultChaosAPIKey
@@ -121,10 +115,7 @@ func (c *ChaosPlugin) executeOnce(ctx context.Context, args []string, seen map[s
 			continue
 		}
 		seen[subdomain] = true
-		results = append(results, engine.Result{
-			Type: "domain",
-			Data: subdomain,
-		})
+		results = append(results, engine.Domain(subdomain).Result())
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("failed while reading chaos output: %v", err)
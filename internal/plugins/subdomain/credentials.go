@@ -0,0 +1,124 @@
+package subdomain
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"hunter/internal/engine"
+)
+
+// bbotKeyModules maps providers to the bbot modules that take their keys.
+var bbotKeyModules = map[string]string{
+	engine.ProviderChaos:          "chaos",
+	engine.ProviderShodan:         "shodan_dns",
+	engine.ProviderSecurityTrails: "securitytrails",
+	engine.ProviderVirusTotal:     "virustotal",
+	engine.ProviderCensys:         "censys",
+	engine.ProviderGitHub:         "github_codesearch",
+	engine.ProviderBinaryEdge:     "binaryedge",
+}
+
+// findomainTokenEnv maps providers to the environment variables findomain
+// reads their tokens from.
+var findomainTokenEnv = map[string]string{
+	engine.ProviderSecurityTrails: "findomain_securitytrails_token",
+	engine.ProviderVirusTotal:     "findomain_virustotal_token",
+}
+
+// subfinderProviderConfig writes the managed keys in ctx as a subfinder
+// provider config and returns its path and providers, or "" when ctx
+// carries no keys. The file replaces the worker's own provider config for
+// the run. No use is reported; see reportKeyUse.
+func subfinderProviderConfig(ctx context.Context) (string, []string, error) {
+	creds := engine.CredentialsFromContext(ctx)
+	var b strings.Builder
+	var providers []string
+	for _, provider := range creds.Providers() {
+		key, _ := creds.Key(provider)
+		b.WriteString(provider + ":\n  - " + strconv.Quote(key) + "\n")
+		providers = append(providers, provider)
+	}
+	if len(providers) == 0 {
+		return "", nil, nil
+	}
+	path, err := writeKeyConfig("subfinder_providers_*.yaml", b.String())
+	if err != nil {
+		return "", nil, err
+	}
+	return path, providers, nil
+}
+
+// bbotKeyConfig writes the managed keys in ctx as a bbot config file
+// setting modules.<module>.api_key, and returns its path and providers, or
+// "" when no key maps to a bbot module. No use is reported; see
+// reportKeyUse.
+func bbotKeyConfig(ctx context.Context) (string, []string, error) {
+	creds := engine.CredentialsFromContext(ctx)
+	var b strings.Builder
+	var providers []string
+	for _, provider := range creds.Providers() {
+		module, ok := bbotKeyModules[provider]
+		if !ok {
+			continue
+		}
+		key, _ := creds.Key(provider)
+		b.WriteString("  " + module + ":\n    api_key: " + strconv.Quote(key) + "\n")
+		providers = append(providers, provider)
+	}
+	if len(providers) == 0 {
+		return "", nil, nil
+	}
+	path, err := writeKeyConfig("bbot_keys_*.yml", "modules:\n"+b.String())
+	if err != nil {
+		return "", nil, err
+	}
+	return path, providers, nil
+}
+
+// writeKeyConfig writes content to a new temp file. os.CreateTemp creates
+// it with mode 0600, so the keys are not readable by other users.
+func writeKeyConfig(pattern, content string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// reportKeyUse counts one use of the managed key of each provider. Callers
+// report once per tool run that read the key config.
+func reportKeyUse(ctx context.Context, providers []string) {
+	creds := engine.CredentialsFromContext(ctx)
+	for _, provider := range providers {
+		creds.Use(provider)
+	}
+}
+
+// findomainKeyEnv returns the environment for findomain with the managed
+// tokens in ctx added.
+func findomainKeyEnv(ctx context.Context) []string {
+	creds := engine.CredentialsFromContext(ctx)
+	var extra []string
+	for _, provider := range creds.Providers() {
+		env, ok := findomainTokenEnv[provider]
+		if !ok {
+			continue
+		}
+		key, _ := creds.Use(provider)
+		extra = append(extra, env+"="+key)
+	}
+	if len(extra) == 0 {
+		return nil
+	}
+	return append(os.Environ(), extra...)
+}
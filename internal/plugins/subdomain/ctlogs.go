@@ -0,0 +1,317 @@
+package subdomain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hunter/internal/engine"
+)
+
+const (
+	defaultCrtshURL       = "https://crt.sh"
+	defaultCertspotterURL = "https://api.certspotter.com"
+
+	ctMaxAttempts        = 3
+	ctCertspotterMaxPage = 20
+	// ctMaxBody caps one API response; crt.sh answers for large domains
+	// run to tens of megabytes.
+	ctMaxBody = 64 << 20
+)
+
+// CTLogsPlugin collects subdomains from Certificate Transparency search APIs
+// (crt.sh JSON output and the Certspotter issuances API). It needs no
+// external binary. Besides domain results it emits one ct_certificate result
+// per certificate so SAN metadata is kept with the scan.
+type CTLogsPlugin struct {
+	crtshURL         string
+	certspotterURL   string
+	certspotterToken string
+	userAgent        string
+	client           *http.Client
+}
+
+// NewCTLogsPlugin creates a CT log plugin. Empty base URLs fall back to
+// CT_CRTSH_URL / CT_CERTSPOTTER_URL and then to the public services; "off"
+// disables a source.
+func NewCTLogsPlugin(crtshURL, certspotterURL string) *CTLogsPlugin {
+	timeoutSec := 120
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv("CT_TIMEOUT_SEC"))); err == nil && v > 0 {
+		timeoutSec = v
+	}
+	return &CTLogsPlugin{
+		crtshURL:         resolveCTBaseURL(crtshURL, "CT_CRTSH_URL", defaultCrtshURL),
+		certspotterURL:   resolveCTBaseURL(certspotterURL, "CT_CERTSPOTTER_URL", defaultCertspotterURL),
+		certspotterToken: strings.TrimSpace(os.Getenv("CERTSPOTTER_API_KEY")),
+		userAgent:        "myrecon-ctlogs/1.0",
+		client:           &http.Client{Timeout: time.Duration(timeoutSec) * time.Second},
+	}
+}
+
+func resolveCTBaseURL(value, envKey, fallback string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = strings.TrimSpace(os.Getenv(envKey))
+	}
+	if value == "" {
+		value = fallback
+	}
+	if strings.EqualFold(value, "off") {
+		return ""
+	}
+	return strings.TrimRight(value, "/")
+}
+
+// Name returns plugin name.
+func (c *CTLogsPlugin) Name() string {
+	return "CTLogs"
+}
+
+// Execute queries every enabled CT source for each root domain. Source
+// failures are logged and skipped so one flaky API does not fail the stage.
+func (c *CTLogsPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	targets := normalizeDomains(input)
+	if len(targets) == 0 || (c.crtshURL == "" && c.certspotterURL == "") {
+		return []engine.Result{}, nil
+	}
+
+	fmt.Printf("[CTLogs] Querying certificate transparency logs for %d root domains...\n", len(targets))
+
+	seenDomains := make(map[string]bool, 1024)
+	seenCerts := make(map[string]bool, 1024)
+	results := make([]engine.Result, 0, 1024)
+	domainCount := 0
+
+	add := func(rootDomain string, certs []engine.CTCertificate) {
+		for _, cert := range certs {
+			cert.RootDomain = rootDomain
+			cert.Names = ctNamesUnder(cert.Names, rootDomain)
+			if len(cert.Names) == 0 {
+				continue
+			}
+			for _, name := range cert.Names {
+				if seenDomains[name] {
+					continue
+				}
+				seenDomains[name] = true
+				domainCount++
+				results = append(results, engine.Domain(name).Result())
+			}
+			key := cert.Source + "|" + cert.CertID
+			if cert.CertID == "" || seenCerts[key] {
+				continue
+			}
+			seenCerts[key] = true
+			results = append(results, cert.Result())
+		}
+	}
+
+	for _, domain := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		if c.crtshURL != "" {
+			certs, err := c.queryCrtsh(ctx, domain)
+			if err != nil {
+				fmt.Printf("[CTLogs] crt.sh query for %s failed, skipped: %v\n", domain, err)
+			}
+			add(domain, certs)
+		}
+		if c.certspotterURL != "" {
+			certs, err := c.queryCertspotter(ctx, domain)
+			if err != nil {
+				fmt.Printf("[CTLogs] certspotter query for %s failed, skipped: %v\n", domain, err)
+			}
+			add(domain, certs)
+		}
+	}
+
+	fmt.Printf("[CTLogs] Found %d subdomains in %d certificates\n", domainCount, len(seenCerts))
+	return results, nil
+}
+
+type crtshEntry struct {
+	ID           int64  `json:"id"`
+	IssuerName   string `json:"issuer_name"`
+	CommonName   string `json:"common_name"`
+	NameValue    string `json:"name_value"`
+	SerialNumber string `json:"serial_number"`
+	NotBefore    string `json:"not_before"`
+	NotAfter     string `json:"not_after"`
+}
+
+func (c *CTLogsPlugin) queryCrtsh(ctx context.Context, domain string) ([]engine.CTCertificate, error) {
+	query := url.Values{}
+	query.Set("q", "%."+domain)
+	query.Set("output", "json")
+	body, err := c.get(ctx, c.crtshURL+"/?"+query.Encode(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []crtshEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("decode crt.sh response: %v", err)
+	}
+	// crt.sh lists the precertificate and the final certificate separately;
+	// they share issuer and serial.
+	bySerial := make(map[string]int, len(entries))
+	var certs []engine.CTCertificate
+	for _, entry := range entries {
+		names := append(strings.Split(entry.NameValue, "\n"), entry.CommonName)
+		key := entry.IssuerName + "|" + entry.SerialNumber
+		if idx, ok := bySerial[key]; ok && entry.SerialNumber != "" {
+			certs[idx].Names = append(certs[idx].Names, names...)
+			continue
+		}
+		bySerial[key] = len(certs)
+		certs = append(certs, engine.CTCertificate{
+			Source:    "crtsh",
+			CertID:    strconv.FormatInt(entry.ID, 10),
+			Names:     names,
+			Issuer:    entry.IssuerName,
+			Serial:    entry.SerialNumber,
+			NotBefore: ctTimestamp(entry.NotBefore),
+			NotAfter:  ctTimestamp(entry.NotAfter),
+		})
+	}
+	return certs, nil
+}
+
+type certspotterIssuance struct {
+	ID        string   `json:"id"`
+	DNSNames  []string `json:"dns_names"`
+	NotBefore string   `json:"not_before"`
+	NotAfter  string   `json:"not_after"`
+	Issuer    struct {
+		Name string `json:"name"`
+	} `json:"issuer"`
+	CertSHA256 string `json:"cert_sha256"`
+}
+
+func (c *CTLogsPlugin) queryCertspotter(ctx context.Context, domain string) ([]engine.CTCertificate, error) {
+	token := c.certspotterToken
+	if key, ok := engine.CredentialsFromContext(ctx).Use(engine.ProviderCertspotter); ok {
+		token = key
+	}
+	var certs []engine.CTCertificate
+	after := ""
+	for page := 0; page < ctCertspotterMaxPage; page++ {
+		query := url.Values{}
+		query.Set("domain", domain)
+		query.Set("include_subdomains", "true")
+		query.Add("expand", "dns_names")
+		query.Add("expand", "issuer")
+		if after != "" {
+			query.Set("after", after)
+		}
+		body, err := c.get(ctx, c.certspotterURL+"/v1/issuances?"+query.Encode(), token)
+		if err != nil {
+			return certs, err
+		}
+		var issuances []certspotterIssuance
+		if err := json.Unmarshal(body, &issuances); err != nil {
+			return certs, fmt.Errorf("decode certspotter response: %v", err)
+		}
+		if len(issuances) == 0 {
+			break
+		}
+		for _, item := range issuances {
+			certs = append(certs, engine.CTCertificate{
+				Source:            "certspotter",
+				CertID:            item.ID,
+				Names:             item.DNSNames,
+				Issuer:            item.Issuer.Name,
+				FingerprintSHA256: strings.ToLower(item.CertSHA256),
+				NotBefore:         ctTimestamp(item.NotBefore),
+				NotAfter:          ctTimestamp(item.NotAfter),
+			})
+		}
+		after = issuances[len(issuances)-1].ID
+	}
+	return certs, nil
+}
+
+// get fetches rawURL, retrying rate limits and server errors with backoff.
+func (c *CTLogsPlugin) get(ctx context.Context, rawURL, token string) ([]byte, error) {
+	var lastErr error
+	for attempt := 1; attempt <= ctMaxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.client.Do(req)
+		if err == nil {
+			body, readErr := io.ReadAll(io.LimitReader(resp.Body, ctMaxBody+1))
+			resp.Body.Close()
+			switch {
+			case readErr != nil:
+				err = readErr
+			case len(body) > ctMaxBody:
+				return nil, fmt.Errorf("response exceeds %d bytes", ctMaxBody)
+			case resp.StatusCode == http.StatusOK:
+				return body, nil
+			case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+				err = fmt.Errorf("HTTP %d", resp.StatusCode)
+			default:
+				return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), 200)])))
+			}
+		}
+		lastErr = err
+		if ctx.Err() != nil || attempt == ctMaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(attempt*attempt) * 2 * time.Second):
+		}
+	}
+	return nil, lastErr
+}
+
+// ctTimestamp converts a CT API timestamp to RFC 3339. crt.sh omits the
+// zone and reports UTC. Unparseable values are returned unchanged.
+func ctTimestamp(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+// ctNamesUnder normalises certificate names and keeps those at or below
+// rootDomain. Wildcard labels are stripped.
+func ctNamesUnder(names []string, rootDomain string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "*."), ".")
+		if name == "" || seen[name] || strings.ContainsAny(name, " \t/\\*@") {
+			continue
+		}
+		if name != rootDomain && !strings.HasSuffix(name, "."+rootDomain) {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -59,10 +59,7 @@ func (d *DictgenPlugin) Execute(_ context.Context, input []string) ([]engine.Res
 	words := rankWords(wordScores, d.maxWords)
 	results := make([]engine.Result, 0, len(words))
 	for _, word := range words {
-		results = append(results, engine.Result{
-			Type: "dict_word",
-			Data: word,
-		})
+		results = append(results, engine.DictWord(word).Result())
 	}
 
 	fmt.Printf("[Dictgen] Generated %d dictionary words (limit=%d)\n", len(results), d.maxWords)
@@ -0,0 +1,110 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"hunter/internal/engine"
+	"hunter/internal/resolver"
+)
+
+const defaultDNSBruteforceConcurrency = 100
+
+// DNSBruteforcePlugin resolves brute-force candidates with the built-in
+// resolver pool. Unlike dnsx it drops names that only resolve because of a
+// wildcard record on their parent.
+type DNSBruteforcePlugin struct {
+	rootDomains   []string
+	resolversFile string
+	concurrency   int
+}
+
+// NewDNSBruteforcePlugin creates a native brute-force plugin.
+func NewDNSBruteforcePlugin(rootDomains []string, resolversFile string) *DNSBruteforcePlugin {
+	return &DNSBruteforcePlugin{
+		rootDomains:   normalizeDomains(rootDomains),
+		resolversFile: strings.TrimSpace(resolversFile),
+		concurrency:   defaultDNSBruteforceConcurrency,
+	}
+}
+
+// Name returns plugin name.
+func (d *DNSBruteforcePlugin) Name() string {
+	return "DNSBruteforce"
+}
+
+// Execute resolves word.root for every root domain and word.
+func (d *DNSBruteforcePlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	words := normalizeWordList(input)
+	if len(d.rootDomains) == 0 || len(words) == 0 {
+		return []engine.Result{}, nil
+	}
+	candidates := make([]string, 0, len(words)*len(d.rootDomains))
+	for _, root := range d.rootDomains {
+		for _, word := range words {
+			candidates = append(candidates, word+"."+root)
+		}
+	}
+	fmt.Printf("[DNSBruteforce] Resolving %d candidates for %d root domains...\n", len(candidates), len(d.rootDomains))
+	return resolveCandidates(ctx, "DNSBruteforce", d.resolversFile, candidates, d.concurrency)
+}
+
+// resolveCandidates resolves candidate hostnames through the pool read from
+// resolversFile and returns a domain result for each name that exists and
+// is not a wildcard hit. tag prefixes the log lines.
+func resolveCandidates(ctx context.Context, tag, resolversFile string, candidates []string, concurrency int) ([]engine.Result, error) {
+	pool, err := resolver.NewPoolFromFile(resolversFile)
+	if err != nil {
+		return nil, err
+	}
+	detector := resolver.NewWildcardDetector(pool)
+
+	answers := pool.ResolveAll(ctx, candidates, concurrency)
+	results := make([]engine.Result, 0, len(answers))
+	wildcardHits := 0
+	for _, host := range candidates {
+		answer, ok := answers[host]
+		if !ok {
+			continue
+		}
+		if detector.IsWildcardHit(ctx, answer) {
+			wildcardHits++
+			continue
+		}
+		results = append(results, engine.Domain(host).Result())
+	}
+	for _, zone := range detector.Zones() {
+		fmt.Printf("[%s] Wildcard zone *.%s -> ips=%s cname=%s\n", tag, zone.Zone, strings.Join(zone.IPs, ","), zone.CNAME)
+	}
+
+	fmt.Printf("[%s] Found %d active subdomains (%d wildcard hits dropped)\n", tag, len(results), wildcardHits)
+	return results, nil
+}
+
+// ActiveBruteforceEngine returns the brute-force plugin name selected by
+// DNS_BRUTEFORCE_ENGINE: "dnsx", "native", or "auto" (default), which uses
+// dnsx when it is installed and the native resolver otherwise.
+func ActiveBruteforceEngine() string {
+	if name := PinnedActiveBruteforceEngine(); name != "" {
+		return name
+	}
+	if _, err := exec.LookPath("dnsx"); err == nil {
+		return "dnsx_bruteforce"
+	}
+	return "dns_bruteforce"
+}
+
+// PinnedActiveBruteforceEngine returns the registry name of the brute-force
+// plugin set by DNS_BRUTEFORCE_ENGINE, or "" in auto mode.
+func PinnedActiveBruteforceEngine() string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("DNS_BRUTEFORCE_ENGINE"))) {
+	case "dnsx":
+		return "dnsx_bruteforce"
+	case "native":
+		return "dns_bruteforce"
+	}
+	return ""
+}
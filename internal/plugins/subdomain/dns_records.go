@@ -0,0 +1,68 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hunter/internal/engine"
+	"hunter/internal/resolver"
+)
+
+const defaultDNSRecordsConcurrency = 50
+
+// DNSRecordsPlugin collects the A, AAAA, CNAME, MX, NS and TXT records of
+// every input host with the built-in resolver pool.
+type DNSRecordsPlugin struct {
+	resolversFile string
+	concurrency   int
+}
+
+// NewDNSRecordsPlugin creates a DNS record inventory plugin.
+func NewDNSRecordsPlugin(resolversFile string) *DNSRecordsPlugin {
+	return &DNSRecordsPlugin{
+		resolversFile: strings.TrimSpace(resolversFile),
+		concurrency:   defaultDNSRecordsConcurrency,
+	}
+}
+
+// Name returns plugin name.
+func (d *DNSRecordsPlugin) Name() string {
+	return "DNSRecords"
+}
+
+// Execute resolves every host and emits one dns_records result per host
+// that has records.
+func (d *DNSRecordsPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	hosts := normalizeDomains(input)
+	if len(hosts) == 0 {
+		return []engine.Result{}, nil
+	}
+	pool, err := resolver.NewPoolFromFile(d.resolversFile)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("[DNSRecords] Resolving records of %d hosts...\n", len(hosts))
+
+	inventory := pool.LookupRecordsAll(ctx, hosts, d.concurrency)
+	results := make([]engine.Result, 0, len(inventory))
+	for _, host := range hosts {
+		records, ok := inventory[host]
+		if !ok {
+			continue
+		}
+		results = append(results, engine.DNSRecords{
+			Host:   records.Host,
+			A:      records.A,
+			AAAA:   records.AAAA,
+			CNAME:  records.CNAME,
+			MX:     records.MX,
+			NS:     records.NS,
+			TXT:    records.TXT,
+			Failed: records.Failed,
+		}.Result())
+	}
+
+	fmt.Printf("[DNSRecords] Collected records for %d/%d hosts\n", len(results), len(hosts))
+	return results, nil
+}
@@ -92,10 +92,7 @@ func (d *DNSXBruteforcePlugin) Execute(ctx context.Context, input []string) ([]e
 			continue
 		}
 		seen[subdomain] = true
-		results = append(results, engine.Result{
-			Type: "domain",
-			Data: subdomain,
-		})
+		results = append(results, engine.Domain(subdomain).Result())
 	}
 
 	if scanErr := scanner.Err(); scanErr != nil {
@@ -118,7 +115,7 @@ func normalizeWordList(input []string) []string {
 	for _, item := range input {
 		word := strings.ToLower(strings.TrimSpace(item))
 		word = strings.Trim(word, ".-")
-		if !isValidWordToken(word) || seen[word] {
+		if !isValidBruteforceWord(word) || seen[word] {
 			continue
 		}
 		seen[word] = true
@@ -127,3 +124,26 @@ func normalizeWordList(input []string) []string {
 
 	return out
 }
+
+// isValidBruteforceWord accepts single-label dictionary words and the
+// multi-label relative names produced by the permutations module
+// ("api-stg.eu").
+func isValidBruteforceWord(word string) bool {
+	if !strings.Contains(word, ".") {
+		return isValidWordToken(word)
+	}
+	if len(word) > 200 {
+		return false
+	}
+	for _, label := range strings.Split(word, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
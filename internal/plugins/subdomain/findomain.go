@@ -48,6 +48,7 @@ func (f *FindomainPlugin) Execute(ctx context.Context, input []string) ([]engine
 	defer os.Remove(outputFile)
 
 	cmd := exec.CommandContext(ctx, "findomain", "--stdin", "-q", "-u", outputFile)
+	cmd.Env = findomainKeyEnv(ctx)
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -80,10 +81,7 @@ func (f *FindomainPlugin) Execute(ctx context.Context, input []string) ([]engine
 
 	results := make([]engine.Result, 0, len(subdomains))
 	for _, subdomain := range subdomains {
-		results = append(results, engine.Result{
-			Type: "domain",
-			Data: subdomain,
-		})
+		results = append(results, engine.Domain(subdomain).Result())
 	}
 
 	fmt.Printf("[Findomain] Found %d subdomains\n", len(results))
@@ -0,0 +1,48 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hunter/internal/common"
+	"hunter/internal/engine"
+)
+
+// PermutationsPlugin turns known subdomains into alteration candidates
+// (number increments, environment swaps, label insertion, dash/dot joins)
+// and resolves them with the built-in resolver pool. Names that exist are
+// emitted as domain results attributed to the permutations module.
+type PermutationsPlugin struct {
+	rootDomains   []string
+	resolversFile string
+	maxCandidates int
+	concurrency   int
+}
+
+// NewPermutationsPlugin creates a permutation resolver. maxCandidates caps
+// the generated names; non-positive values use the default.
+func NewPermutationsPlugin(rootDomains []string, resolversFile string, maxCandidates int) *PermutationsPlugin {
+	return &PermutationsPlugin{
+		rootDomains:   normalizeDomains(rootDomains),
+		resolversFile: strings.TrimSpace(resolversFile),
+		maxCandidates: maxCandidates,
+		concurrency:   defaultDNSBruteforceConcurrency,
+	}
+}
+
+// Name returns plugin name.
+func (p *PermutationsPlugin) Name() string {
+	return "Permutations"
+}
+
+// Execute generates permutations of the input subdomains and resolves them.
+func (p *PermutationsPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	domains := normalizeDomains(input)
+	candidates := common.BuildPermutations(domains, p.rootDomains, p.maxCandidates)
+	fmt.Printf("[Permutations] Generated %d candidates from %d known subdomains\n", len(candidates), len(domains))
+	if len(candidates) == 0 {
+		return []engine.Result{}, nil
+	}
+	return resolveCandidates(ctx, "Permutations", p.resolversFile, candidates, p.concurrency)
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 
@@ -30,9 +29,9 @@ func (s *ShosubgoPlugin) Execute(ctx context.Context, input []string) ([]engine.
 		return nil, fmt.Errorf("shosubgo not found in PATH. Please install shosubgo and ensure it's in your PATH")
 	}
 
-	apiKey := os.Getenv("SHODAN_API_KEY")
+	apiKey := engine.APIKey(ctx, engine.ProviderShodan, "SHODAN_API_KEY")
 	if apiKey == "" {
-		return nil, fmt.Errorf("SHODAN_API_KEY environment variable is not set")
+		return nil, fmt.Errorf("no Shodan API key: add a managed shodan key or set SHODAN_API_KEY")
 	}
 
 	if len(input) == 0 {
@@ -73,10 +72,7 @@ func (s *ShosubgoPlugin) Execute(ctx context.Context, input []string) ([]engine.
 			seen[subdomain] = true
 			domainCount++
 			totalCount++
-			results = append(results, engine.Result{
-				Type: "domain",
-				Data: subdomain,
-			})
+			results = append(results, engine.Domain(subdomain).Result())
 		}
 
 		if err := cmd.Wait(); err != nil {
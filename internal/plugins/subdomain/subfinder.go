@@ -47,6 +47,16 @@ func (s *SubfinderPlugin) Execute(ctx context.Context, input []string) ([]engine
 	var results []engine.Result
 	var allHosts []string
 
+	var extraArgs []string
+	providerConfig, keyProviders, err := subfinderProviderConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write subfinder provider config: %v", err)
+	}
+	if providerConfig != "" {
+		defer common.RemoveTempFile(providerConfig)
+		extraArgs = append(extraArgs, "-pc", providerConfig)
+	}
+
 	if s.batchMode && len(input) > 1 {
 		fmt.Printf("[Subfinder] Batch mode: collecting subdomains for %d root domains...\n", len(input))
 
@@ -56,7 +66,7 @@ func (s *SubfinderPlugin) Execute(ctx context.Context, input []string) ([]engine
 		}
 		defer common.RemoveTempFile(tmpFile)
 
-		cmd := exec.CommandContext(ctx, "subfinder", "-dL", tmpFile, "-all", "-json", "-silent")
+		cmd := exec.CommandContext(ctx, "subfinder", append([]string{"-dL", tmpFile, "-all", "-json", "-silent"}, extraArgs...)...)
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
@@ -65,6 +75,7 @@ func (s *SubfinderPlugin) Execute(ctx context.Context, input []string) ([]engine
 		if err := cmd.Start(); err != nil {
 			return nil, fmt.Errorf("failed to start subfinder: %v", err)
 		}
+		reportKeyUse(ctx, keyProviders)
 
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
@@ -90,7 +101,7 @@ func (s *SubfinderPlugin) Execute(ctx context.Context, input []string) ([]engine
 		for _, domain := range input {
 			fmt.Printf("[Subfinder] Collecting subdomains for: %s\n", domain)
 
-			cmd := exec.CommandContext(ctx, "subfinder", "-d", domain, "-all", "-json", "-silent")
+			cmd := exec.CommandContext(ctx, "subfinder", append([]string{"-d", domain, "-all", "-json", "-silent"}, extraArgs...)...)
 			stdout, err := cmd.StdoutPipe()
 			if err != nil {
 				return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
@@ -99,6 +110,7 @@ func (s *SubfinderPlugin) Execute(ctx context.Context, input []string) ([]engine
 			if err := cmd.Start(); err != nil {
 				return nil, fmt.Errorf("failed to start subfinder: %v", err)
 			}
+			reportKeyUse(ctx, keyProviders)
 
 			scanner := bufio.NewScanner(stdout)
 			var hosts []string
@@ -127,7 +139,7 @@ func (s *SubfinderPlugin) Execute(ctx context.Context, input []string) ([]engine
 	}
 
 	for _, host := range allHosts {
-		results = append(results, engine.Result{Type: "domain", Data: host})
+		results = append(results, engine.Domain(host).Result())
 	}
 
 	return results, nil
@@ -0,0 +1,190 @@
+package subdomain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hunter/internal/engine"
+	"hunter/internal/resolver"
+)
+
+const zoneExposureSampleSize = 20
+
+// ZoneTransferPlugin asks every authoritative nameserver of a root domain
+// for a zone transfer (AXFR) and, when none allows it, walks the zone's
+// NSEC chain. Disclosed names become domain results; each exposure is also
+// reported as a dns_zone_exposure finding.
+type ZoneTransferPlugin struct {
+	timeout time.Duration
+}
+
+// NewZoneTransferPlugin creates an AXFR/NSEC plugin. ZONE_TRANSFER_TIMEOUT_SEC
+// overrides the per-nameserver timeout (default 30s).
+func NewZoneTransferPlugin() *ZoneTransferPlugin {
+	timeoutSec := 30
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv("ZONE_TRANSFER_TIMEOUT_SEC"))); err == nil && v > 0 {
+		timeoutSec = v
+	}
+	return &ZoneTransferPlugin{timeout: time.Duration(timeoutSec) * time.Second}
+}
+
+// Name returns plugin name.
+func (z *ZoneTransferPlugin) Name() string {
+	return "ZoneTransfer"
+}
+
+type zoneNameserver struct {
+	host string
+	ip   string
+}
+
+// Execute tries AXFR then NSEC walking for each root domain. Lookup and
+// transfer failures are logged; refused transfers are the normal case.
+// Transfers and walk queries count against the nameserver's host budget.
+func (z *ZoneTransferPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	targets := normalizeDomains(input)
+	results := make([]engine.Result, 0, 64)
+	seen := make(map[string]bool, 256)
+	exposed := 0
+	limiter := engine.HostLimiterFromContext(ctx)
+
+	addNames := func(root string, names []string) int {
+		added := 0
+		for _, name := range names {
+			name = strings.TrimPrefix(name, "*.")
+			if name == "" || seen[name] || (name != root && !strings.HasSuffix(name, "."+root)) {
+				continue
+			}
+			seen[name] = true
+			added++
+			results = append(results, engine.Domain(name).Result())
+		}
+		return added
+	}
+
+	for _, root := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		nameservers, err := z.nameservers(ctx, root)
+		if err != nil {
+			fmt.Printf("[ZoneTransfer] NS lookup for %s failed, skipped: %v\n", root, err)
+			continue
+		}
+
+		transferred := false
+		for _, ns := range nameservers {
+			release, err := limiter.Acquire(ctx, ns.host, ns.ip)
+			if err != nil {
+				break
+			}
+			records, err := resolver.Transfer(ctx, ns.ip, root, z.timeout)
+			release()
+			if err != nil {
+				if !errors.Is(err, resolver.ErrTransferRefused) {
+					fmt.Printf("[ZoneTransfer] AXFR %s @%s (%s) failed: %v\n", root, ns.host, ns.ip, err)
+				}
+				continue
+			}
+			names := make([]string, 0, len(records))
+			for _, record := range records {
+				names = append(names, record.Name)
+			}
+			added := addNames(root, names)
+			transferred = true
+			exposed++
+			fmt.Printf("[ZoneTransfer] AXFR allowed for %s @%s: %d records, %d new names\n", root, ns.host, len(records), added)
+			results = append(results, engine.ZoneExposure{
+				Kind:         engine.ZoneExposureAXFR,
+				RootDomain:   root,
+				Nameserver:   ns.host,
+				NameserverIP: ns.ip,
+				RecordCount:  len(records),
+				Sample:       zoneSample(names),
+			}.Result())
+		}
+		if transferred || len(nameservers) == 0 {
+			continue
+		}
+
+		for _, ns := range nameservers {
+			gate := func(ctx context.Context) (func(), error) { return limiter.Acquire(ctx, ns.host, ns.ip) }
+			names, err := resolver.WalkNSEC(ctx, ns.ip, root, z.timeout, gate)
+			if len(names) == 0 {
+				if err != nil && !errors.Is(err, resolver.ErrNotWalkable) {
+					fmt.Printf("[ZoneTransfer] NSEC walk %s @%s failed: %v\n", root, ns.host, err)
+					continue
+				}
+				// The zone's signing mode is the same on every nameserver.
+				break
+			}
+			added := addNames(root, names)
+			exposed++
+			fmt.Printf("[ZoneTransfer] NSEC walk of %s @%s listed %d names, %d new\n", root, ns.host, len(names), added)
+			results = append(results, engine.ZoneExposure{
+				Kind:         engine.ZoneExposureNSECWalk,
+				RootDomain:   root,
+				Nameserver:   ns.host,
+				NameserverIP: ns.ip,
+				RecordCount:  len(names),
+				Sample:       zoneSample(names),
+			}.Result())
+			break
+		}
+	}
+
+	fmt.Printf("[ZoneTransfer] Found %d subdomains, %d zone exposures\n", len(seen), exposed)
+	return results, nil
+}
+
+// nameservers returns one address per authoritative nameserver of root,
+// preferring IPv4.
+func (z *ZoneTransferPlugin) nameservers(ctx context.Context, root string) ([]zoneNameserver, error) {
+	lookupCtx, cancel := context.WithTimeout(ctx, z.timeout)
+	defer cancel()
+	records, err := net.DefaultResolver.LookupNS(lookupCtx, root)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]zoneNameserver, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(strings.ToLower(record.Host), ".")
+		addrs, err := net.DefaultResolver.LookupIPAddr(lookupCtx, host)
+		if err != nil || len(addrs) == 0 {
+			continue
+		}
+		ip := addrs[0].IP
+		for _, addr := range addrs {
+			if addr.IP.To4() != nil {
+				ip = addr.IP
+				break
+			}
+		}
+		out = append(out, zoneNameserver{host: host, ip: ip.String()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].host < out[j].host })
+	return out, nil
+}
+
+func zoneSample(names []string) []string {
+	seen := make(map[string]bool, zoneExposureSampleSize)
+	out := make([]string, 0, zoneExposureSampleSize)
+	for _, name := range names {
+		if len(out) >= zoneExposureSampleSize {
+			break
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
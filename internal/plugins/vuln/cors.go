@@ -122,25 +122,22 @@ func (c *CorsPlugin) Execute(ctx context.Context, input []string) ([]engine.Resu
 			}
 			rawJSON, _ := json.Marshal(rawPayload)
 
-			result := engine.Result{
-				Type: "vulnerability",
-				Data: map[string]interface{}{
-					"template_id":   finding.templateID,
-					"template_name": finding.templateName,
-					"severity":      finding.severity,
-					"matched_at":    target.URL,
-					"host":          target.Host,
-					"domain":        target.Host,
-					"root_domain":   target.RootDomain,
-					"url":           target.URL,
-					"matcher_name":  finding.rule,
-					"description":   finding.description,
-					"reference":     c.referenceDocs,
-					"template_url":  c.referenceDocs,
-					"raw":           string(rawJSON),
-					"discovered_at": time.Now(),
-				},
-			}
+			result := engine.Vulnerability{
+				TemplateID:   finding.templateID,
+				TemplateName: finding.templateName,
+				Severity:     finding.severity,
+				MatchedAt:    target.URL,
+				Host:         target.Host,
+				Domain:       target.Host,
+				RootDomain:   target.RootDomain,
+				URL:          target.URL,
+				MatcherName:  finding.rule,
+				Description:  finding.description,
+				Reference:    c.referenceDocs,
+				TemplateURL:  c.referenceDocs,
+				Raw:          string(rawJSON),
+				DiscoveredAt: time.Now(),
+			}.Result()
 			results = append(results, result)
 			// Keep one high-risk CORS finding per endpoint to avoid noise.
 			break
@@ -232,6 +229,11 @@ func (c *CorsPlugin) requestWithOrigin(ctx context.Context, targetURL, origin st
 	if err != nil {
 		return nil, 0, err
 	}
+	release, err := engine.HostLimiterFromContext(ctx).Acquire(ctx, targetURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
 	req.Header.Set("Origin", origin)
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("User-Agent", c.userAgent)
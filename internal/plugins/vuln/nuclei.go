@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -68,6 +69,12 @@ func (n *NucleiPlugin) Name() string {
 
 // Execute runs nuclei against input URLs and emits vulnerability results.
 func (n *NucleiPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	return n.ExecuteStream(ctx, input, nil)
+}
+
+// ExecuteStream runs nuclei and hands each finding to emit as soon as nuclei
+// prints it, so long template runs persist partial results.
+func (n *NucleiPlugin) ExecuteStream(ctx context.Context, input []string, emit engine.ResultHandler) ([]engine.Result, error) {
 	if _, err := exec.LookPath("nuclei"); err != nil {
 		return nil, fmt.Errorf("nuclei not found in PATH. Please install nuclei and ensure it's in your PATH")
 	}
@@ -120,6 +127,15 @@ func (n *NucleiPlugin) Execute(ctx context.Context, input []string) ([]engine.Re
 	if len(n.excludeTags) > 0 {
 		args = append(args, "-etags", strings.Join(n.excludeTags, ","))
 	}
+	// nuclei defaults: -rl 150 requests/s, -c 25 templates in parallel per
+	// host, so concurrency maps to -c unscaled.
+	budget := engine.HostLimiterFromContext(ctx).Budget()
+	if rate, _ := budget.Scale(engine.CountHosts(targets), 150, 0); rate > 0 {
+		args = append(args, "-rl", strconv.Itoa(rate))
+	}
+	if budget.MaxConcurrent > 0 {
+		args = append(args, "-c", strconv.Itoa(min(budget.MaxConcurrent, 25)))
+	}
 	fmt.Printf("[Nuclei] filters ept=%s eid=%d es=%s etags=%s\n",
 		strings.Join(n.excludeProtocolTypes, ","),
 		len(n.excludeTemplateIDs),
@@ -148,6 +164,9 @@ func (n *NucleiPlugin) Execute(ctx context.Context, input []string) ([]engine.Re
 		}
 		if result, ok := parseNucleiJSONLLine(line, rootDomainHints); ok {
 			results = append(results, result)
+			if emit != nil {
+				emit(result)
+			}
 		}
 	}
 
@@ -159,6 +178,11 @@ func (n *NucleiPlugin) Execute(ctx context.Context, input []string) ([]engine.Re
 	if len(results) == 0 {
 		if fileResults, err := parseNucleiResultFile(resultPath, rootDomainHints); err == nil {
 			results = append(results, fileResults...)
+			if emit != nil {
+				for _, result := range fileResults {
+					emit(result)
+				}
+			}
 		}
 	}
 	if waitErr != nil && len(results) == 0 {
@@ -219,26 +243,23 @@ func parseNucleiJSONLLine(line string, rootDomainHints map[string]string) (engin
 
 	cve := extractCVE(nResult.TemplateID + " " + nResult.Template + " " + nResult.Info.Name + " " + nResult.Info.Description)
 
-	return engine.Result{
-		Type: "vulnerability",
-		Data: map[string]interface{}{
-			"template_id":   nResult.TemplateID,
-			"template_name": nResult.Info.Name,
-			"severity":      strings.ToLower(nResult.Info.Severity),
-			"matched_at":    nResult.MatchedAt,
-			"host":          nResult.Host,
-			"domain":        domain,
-			"root_domain":   rootDomain,
-			"ip":            nResult.IP,
-			"matcher_name":  nResult.MatcherName,
-			"description":   nResult.Info.Description,
-			"reference":     references,
-			"template_url":  nResult.TemplateURL,
-			"cve":           cve,
-			"raw":           line,
-			"discovered_at": time.Now(),
-		},
-	}, true
+	return engine.Vulnerability{
+		TemplateID:   nResult.TemplateID,
+		TemplateName: nResult.Info.Name,
+		Severity:     strings.ToLower(nResult.Info.Severity),
+		MatchedAt:    nResult.MatchedAt,
+		Host:         nResult.Host,
+		Domain:       domain,
+		RootDomain:   rootDomain,
+		IP:           nResult.IP,
+		MatcherName:  nResult.MatcherName,
+		Description:  nResult.Info.Description,
+		Reference:    references,
+		TemplateURL:  nResult.TemplateURL,
+		CVE:          cve,
+		Raw:          line,
+		DiscoveredAt: time.Now(),
+	}.Result(), true
 }
 
 func normalizeNucleiTargets(input []string) ([]string, map[string]string) {
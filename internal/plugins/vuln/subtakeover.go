@@ -273,25 +273,23 @@ func (s *SubTakeoverPlugin) parseSubjackOutput(path string, rootHints map[string
 			matchedURL = "https://" + host
 		}
 
-		results = append(results, engine.Result{
-			Type: "vulnerability",
-			Data: map[string]interface{}{
-				"template_id":   templateID,
-				"template_name": templateName,
-				"severity":      s.severity,
-				"matched_at":    host,
-				"host":          host,
-				"domain":        host,
-				"root_domain":   rootDomain,
-				"url":           matchedURL,
-				"matcher_name":  "subjack",
-				"description":   description,
-				"reference":     reference,
-				"template_url":  reference,
-				"raw":           string(rowJSON),
-				"discovered_at": time.Now(),
-			},
-		})
+		results = append(results, engine.Vulnerability{
+			TemplateID:   templateID,
+			TemplateName: templateName,
+			Severity:     s.severity,
+			MatchedAt:    host,
+			Host:         host,
+			Domain:       host,
+			RootDomain:   rootDomain,
+			URL:          matchedURL,
+			MatcherName:  "subjack",
+			CNAME:        strings.TrimSpace(row.Domain),
+			Description:  description,
+			Reference:    reference,
+			TemplateURL:  reference,
+			Raw:          string(rowJSON),
+			DiscoveredAt: time.Now(),
+		}.Result())
 	}
 	return results, nil
 }
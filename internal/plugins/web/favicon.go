@@ -0,0 +1,146 @@
+package web
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	linkTagPattern = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	relAttrPattern = regexp.MustCompile(`(?is)\brel\s*=\s*["']?([^"'>]+)`)
+	// hrefPattern takes a quoted value or an unquoted one up to whitespace.
+	hrefPattern = regexp.MustCompile(`(?is)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// favicon is a fetched icon with its Shodan-compatible mmh3 hash and MD5.
+type favicon struct {
+	url  string
+	mmh3 string
+	md5  string
+}
+
+// faviconMMH3 returns the favicon hash used by Shodan (http.favicon.hash)
+// and httpx: the signed 32-bit MurmurHash3 of the base64 encoding with a
+// newline after every 76 characters, as Python's base64.encodebytes does.
+func faviconMMH3(data []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	b.Grow(len(encoded) + len(encoded)/76 + 1)
+	for i := 0; i < len(encoded); i += 76 {
+		end := min(i+76, len(encoded))
+		b.WriteString(encoded[i:end])
+		b.WriteByte('\n')
+	}
+	return int32(murmur3x86_32([]byte(b.String()), 0))
+}
+
+// murmur3x86_32 is MurmurHash3_x86_32.
+func murmur3x86_32(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+	tail := data[n*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// faviconCandidates returns the icon URLs declared by <link rel=icon> on
+// the page at base, followed by /favicon.ico. Icons on other hosts are
+// skipped so the fetch stays on the probed target.
+func faviconCandidates(base *url.URL, body []byte) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Hostname(), base.Hostname()) {
+			return
+		}
+		u.Fragment = ""
+		if s := u.String(); !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	for _, tag := range linkTagPattern.FindAll(body, -1) {
+		rel := relAttrPattern.FindSubmatch(tag)
+		if rel == nil || !strings.Contains(strings.ToLower(string(rel[1])), "icon") {
+			continue
+		}
+		if m := hrefPattern.FindSubmatch(tag); m != nil {
+			for _, ref := range m[1:] {
+				if len(ref) > 0 {
+					add(string(ref))
+					break
+				}
+			}
+		}
+	}
+	add("/favicon.ico")
+	return out
+}
+
+// fetchFavicon fetches the first available icon of the page at pageURL.
+// Error pages served as HTML are not icons and are skipped.
+func (p *HTTPProbePlugin) fetchFavicon(ctx context.Context, client *http.Client, pageURL *url.URL, body []byte) (favicon, bool) {
+	for _, candidate := range faviconCandidates(pageURL, body) {
+		resp, err := p.fetch(ctx, client, candidate)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		contentType := strings.ToLower(resp.header.Get("Content-Type"))
+		if resp.status != http.StatusOK || len(resp.body) == 0 || strings.Contains(contentType, "text/html") {
+			continue
+		}
+		sum := md5.Sum(resp.body)
+		return favicon{
+			url:  candidate,
+			mmh3: strconv.FormatInt(int64(faviconMMH3(resp.body)), 10),
+			md5:  hex.EncodeToString(sum[:]),
+		}, true
+	}
+	return favicon{}, false
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMurmur3x86_32(t *testing.T) {
+	// Reference vectors of MurmurHash3_x86_32 from SMHasher.
+	tests := []struct {
+		data string
+		seed uint32
+		want uint32
+	}{
+		{"", 0, 0},
+		{"", 1, 0x514e28b7},
+		{"", 0xffffffff, 0x81f16f39},
+		{"\x00\x00\x00\x00", 0, 0x2362f9de},
+		{"aaaa", 0x9747b28c, 0x5a97808a},
+		{"Hello, world!", 0x9747b28c, 0x24884cba},
+		{"The quick brown fox jumps over the lazy dog", 0x9747b28c, 0x2fa826cd},
+	}
+	for _, tt := range tests {
+		if got := murmur3x86_32([]byte(tt.data), tt.seed); got != tt.want {
+			t.Errorf("murmur3x86_32(%q, %#x) = %#x, want %#x", tt.data, tt.seed, got, tt.want)
+		}
+	}
+}
+
+func TestFaviconMMH3(t *testing.T) {
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+	// Expected values are mmh3.hash(base64.encodebytes(data)), the hash
+	// Shodan and httpx report.
+	tests := []struct {
+		name string
+		data []byte
+		want int32
+	}{
+		{"empty", nil, 0},
+		{"short", []byte("hello"), 1155597304},
+		{"wrapped at 76 characters", allBytes, -757223386},
+		{"zeros", make([]byte, 100), -1140816753},
+	}
+	for _, tt := range tests {
+		if got := faviconMMH3(tt.data); got != tt.want {
+			t.Errorf("%s: faviconMMH3() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFaviconCandidates(t *testing.T) {
+	base, _ := url.Parse("https://www.example.com/app/index.html")
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "no link tags",
+			body: "<html><head><title>x</title></head></html>",
+			want: []string{"https://www.example.com/favicon.ico"},
+		},
+		{
+			name: "relative, absolute and shortcut icons",
+			body: `<link rel="icon" href="img/fav.png"><link rel='shortcut icon' href='/static/f.ico#v2'><LINK REL=apple-touch-icon HREF=https://www.example.com/t.png>`,
+			want: []string{
+				"https://www.example.com/app/img/fav.png",
+				"https://www.example.com/static/f.ico",
+				"https://www.example.com/t.png",
+				"https://www.example.com/favicon.ico",
+			},
+		},
+		{
+			name: "other hosts, schemes and rels are skipped",
+			body: `<link rel="icon" href="https://cdn.other.test/f.ico"><link rel="icon" href="data:image/png;base64,AAAA"><link rel="stylesheet" href="/s.css">`,
+			want: []string{"https://www.example.com/favicon.ico"},
+		},
+		{
+			name: "duplicates collapse",
+			body: `<link rel="icon" href="/favicon.ico"><link rel="icon" href="https://www.example.com/favicon.ico">`,
+			want: []string{"https://www.example.com/favicon.ico"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := faviconCandidates(base, []byte(tt.body)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("faviconCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -106,15 +106,12 @@ func (g *GowitnessPlugin) Execute(ctx context.Context, input []string) ([]engine
 
 		fmt.Printf("[Gowitness] %s completed with %d screenshots\n", rootDomain, count)
 
-		results = append(results, engine.Result{
-			Type: "screenshot",
-			Data: map[string]interface{}{
-				"root_domain":      rootDomain,
-				"screenshot_count": count,
-				"screenshot_dir":   screenshotDir,
-				"database":         filepath.Join(domainDir, "gowitness.sqlite3"),
-			},
-		})
+		results = append(results, engine.Screenshot{
+			RootDomain:      rootDomain,
+			ScreenshotCount: count,
+			ScreenshotDir:   screenshotDir,
+			Database:        filepath.Join(domainDir, "gowitness.sqlite3"),
+		}.Result())
 	}
 
 	fmt.Printf("[Gowitness] Screenshot task finished, total %d screenshots\n", totalScreenshots)
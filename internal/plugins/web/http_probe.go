@@ -0,0 +1,498 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hunter/internal/engine"
+)
+
+const (
+	defaultHTTPProbeConcurrency = 50
+	defaultHTTPProbeTimeout     = 10 * time.Second
+	// httpProbeMaxRedirects caps the same-host redirects followed per URL.
+	httpProbeMaxRedirects = 5
+	// httpProbeMaxBody is how much of each body is read for title and
+	// technology detection.
+	httpProbeMaxBody  = 512 << 10
+	httpProbeMaxTitle = 256
+	httpProbeAgent    = "Mozilla/5.0 (compatible; myrecon-probe/1.0)"
+)
+
+// defaultHTTPProbePorts are probed for bare hosts: the standard web ports
+// and the common alternative ones.
+var defaultHTTPProbePorts = []int{80, 443, 8080, 8443, 8000, 8888}
+
+// httpsFirstPorts are tried with https before http.
+var httpsFirstPorts = map[int]bool{443: true, 4443: true, 8443: true, 9443: true}
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// HTTPProbePlugin is a built-in HTTP prober used when httpx is not
+// installed. It emits the same web_service results as httpx.
+type HTTPProbePlugin struct {
+	ports       []int
+	timeout     time.Duration
+	concurrency int
+}
+
+// httpProbeTarget is one URL to probe. fallback is the same endpoint with
+// the other scheme, tried when the first one does not speak HTTP(S).
+type httpProbeTarget struct {
+	url      string
+	fallback string
+}
+
+// NewHTTPProbePlugin creates a native prober configured from
+// HTTP_PROBE_PORTS, HTTP_PROBE_TIMEOUT_MS and HTTP_PROBE_CONCURRENCY.
+func NewHTTPProbePlugin() *HTTPProbePlugin {
+	p := &HTTPProbePlugin{
+		ports:       parsePortList(os.Getenv("HTTP_PROBE_PORTS")),
+		timeout:     defaultHTTPProbeTimeout,
+		concurrency: defaultHTTPProbeConcurrency,
+	}
+	if len(p.ports) == 0 {
+		p.ports = defaultHTTPProbePorts
+	}
+	if ms, err := strconv.Atoi(strings.TrimSpace(os.Getenv("HTTP_PROBE_TIMEOUT_MS"))); err == nil && ms > 0 {
+		p.timeout = time.Duration(ms) * time.Millisecond
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("HTTP_PROBE_CONCURRENCY"))); err == nil && n > 0 {
+		p.concurrency = n
+	}
+	return p
+}
+
+// HTTPProbeEngine returns the registry name of the web prober selected by
+// HTTP_PROBE_ENGINE: "httpx", "native", or "auto" (default), which uses
+// httpx when it is installed and the native prober otherwise.
+func HTTPProbeEngine() string {
+	if name := PinnedHTTPProbeEngine(); name != "" {
+		return name
+	}
+	if _, err := exec.LookPath("httpx"); err == nil {
+		return "httpx"
+	}
+	return "http_probe"
+}
+
+// PinnedHTTPProbeEngine returns the registry name of the prober set by
+// HTTP_PROBE_ENGINE, or "" in auto mode.
+func PinnedHTTPProbeEngine() string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("HTTP_PROBE_ENGINE"))) {
+	case "httpx":
+		return "httpx"
+	case "native":
+		return "http_probe"
+	}
+	return ""
+}
+
+// Name returns plugin name.
+func (p *HTTPProbePlugin) Name() string {
+	return "HTTPProbe"
+}
+
+// Execute probes the input hosts and returns live web services.
+func (p *HTTPProbePlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	return p.ExecuteStream(ctx, input, nil)
+}
+
+// ExecuteStream probes every input and hands each live service to emit as
+// soon as it answers. Inputs may be hosts, host:port pairs or URLs; bare
+// hosts are probed on the configured ports.
+func (p *HTTPProbePlugin) ExecuteStream(ctx context.Context, input []string, emit engine.ResultHandler) ([]engine.Result, error) {
+	targets := p.targets(input)
+	if len(targets) == 0 {
+		return []engine.Result{}, nil
+	}
+	fmt.Printf("[HTTPProbe] Probing %d URLs for %d inputs...\n", len(targets), len(input))
+
+	client := p.newClient()
+	jobs := make(chan httpProbeTarget)
+	var (
+		mu      sync.Mutex
+		results []engine.Result
+		seen    = make(map[string]bool)
+		wg      sync.WaitGroup
+	)
+	workers := p.concurrency
+	if workers > len(targets) {
+		workers = len(targets)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				service, cert, ok := p.probeTarget(ctx, client, target)
+				if !ok {
+					continue
+				}
+				batch := []engine.Result{service.Result()}
+				certKey := ""
+				if cert != nil {
+					batch = append(batch, cert.Result())
+					certKey = "cert " + net.JoinHostPort(cert.Host, strconv.Itoa(cert.Port))
+				}
+				mu.Lock()
+				if seen[service.URL] {
+					mu.Unlock()
+					continue
+				}
+				seen[service.URL] = true
+				if certKey != "" {
+					// One certificate per endpoint, however many URLs reach it.
+					if seen[certKey] {
+						batch = batch[:1]
+					}
+					seen[certKey] = true
+				}
+				results = append(results, batch...)
+				if emit != nil {
+					for _, result := range batch {
+						emit(result)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, target := range targets {
+		select {
+		case jobs <- target:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	fmt.Printf("[HTTPProbe] Probe completed, found %d live services\n", len(results))
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// targets expands the input into probe URLs, de-duplicated.
+func (p *HTTPProbePlugin) targets(input []string) []httpProbeTarget {
+	var out []httpProbeTarget
+	seen := make(map[string]bool)
+	add := func(t httpProbeTarget) {
+		if !seen[t.url] {
+			seen[t.url] = true
+			out = append(out, t)
+		}
+	}
+	for _, raw := range input {
+		raw = strings.TrimSpace(raw)
+		if idx := strings.Index(raw, "|"); idx >= 0 {
+			raw = raw[:idx]
+		}
+		if raw == "" {
+			continue
+		}
+		if strings.Contains(raw, "://") {
+			if u, err := url.Parse(raw); err == nil && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https") {
+				add(httpProbeTarget{url: u.String()})
+			}
+			continue
+		}
+		if host, portText, err := net.SplitHostPort(raw); err == nil {
+			if port, err := strconv.Atoi(portText); err == nil && port > 0 && port < 65536 {
+				add(schemeTargets(host, port))
+			}
+			continue
+		}
+		host := strings.TrimSuffix(strings.ToLower(strings.Trim(raw, "[]")), ".")
+		for _, port := range p.ports {
+			add(schemeTargets(host, port))
+		}
+	}
+	return out
+}
+
+// schemeTargets returns the URL of host:port with the likely scheme first.
+// 80 and 443 are only tried with their own scheme.
+func schemeTargets(host string, port int) httpProbeTarget {
+	httpURL := probeURL("http", host, port)
+	httpsURL := probeURL("https", host, port)
+	switch {
+	case port == 80:
+		return httpProbeTarget{url: httpURL}
+	case port == 443:
+		return httpProbeTarget{url: httpsURL}
+	case httpsFirstPorts[port]:
+		return httpProbeTarget{url: httpsURL, fallback: httpURL}
+	default:
+		return httpProbeTarget{url: httpURL, fallback: httpsURL}
+	}
+}
+
+func probeURL(scheme, host string, port int) string {
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		if strings.Contains(host, ":") {
+			return scheme + "://[" + host + "]"
+		}
+		return scheme + "://" + host
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (p *HTTPProbePlugin) newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: p.timeout}
+	return &http.Client{
+		Timeout: p.timeout,
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+			// Targets often serve self-signed or mismatched certificates.
+			TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+			TLSHandshakeTimeout:   p.timeout,
+			ResponseHeaderTimeout: p.timeout,
+			DisableKeepAlives:     true,
+		},
+		// Redirects are followed by probe so each hop can be recorded and
+		// kept on the probed host.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// probeTarget probes target, falling back to the other scheme when the
+// port answers but not with the expected protocol.
+func (p *HTTPProbePlugin) probeTarget(ctx context.Context, client *http.Client, target httpProbeTarget) (engine.WebService, *engine.TLSCertificate, bool) {
+	service, cert, err := p.probe(ctx, client, target.url)
+	if err == nil {
+		return service, cert, true
+	}
+	if target.fallback == "" || isDialError(err) || ctx.Err() != nil {
+		return engine.WebService{}, nil, false
+	}
+	service, cert, err = p.probe(ctx, client, target.fallback)
+	return service, cert, err == nil
+}
+
+// isDialError reports whether err means nothing listens on the port, in
+// which case the other scheme is not worth trying.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+var errPlainHTTPToTLS = errors.New("plain HTTP request sent to a TLS port")
+
+// plainHTTPToTLS reports whether a 400 body is a server complaining that it
+// expected TLS, so the https fallback should be used instead.
+func plainHTTPToTLS(body []byte) bool {
+	lower := strings.ToLower(string(body))
+	return strings.Contains(lower, "http request to an https server") ||
+		strings.Contains(lower, "plain http request was sent to https port")
+}
+
+// httpProbeResponse is what probe keeps of one response.
+type httpProbeResponse struct {
+	status   int
+	location string
+	header   http.Header
+	body     []byte
+	ip       string
+	tlsNames []string
+	cert     *x509.Certificate // leaf certificate of an https response
+	length   int
+	bodyHash [sha256.Size]byte // of the body as read, up to httpProbeMaxBody
+	elapsed  time.Duration
+}
+
+// probe requests rawURL and follows redirects that stay on the same host.
+// Status code and location are those of the first response, like httpx;
+// title, server and technologies come from the last response fetched. For
+// https URLs the certificate of the first response is returned as well.
+func (p *HTTPProbePlugin) probe(ctx context.Context, client *http.Client, rawURL string) (engine.WebService, *engine.TLSCertificate, error) {
+	first, err := p.fetch(ctx, client, rawURL)
+	if err != nil {
+		return engine.WebService{}, nil, err
+	}
+	start, _ := url.Parse(rawURL)
+	if start.Scheme == "http" && first.status == http.StatusBadRequest && plainHTTPToTLS(first.body) {
+		return engine.WebService{}, nil, errPlainHTTPToTLS
+	}
+	last := first
+	current := start
+	var chain []string
+	for i := 0; i < httpProbeMaxRedirects && last.location != ""; i++ {
+		next, err := current.Parse(last.location)
+		if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+			break
+		}
+		chain = append(chain, next.String())
+		// Other hosts may be out of scope; they are recorded but not fetched.
+		if !strings.EqualFold(next.Hostname(), start.Hostname()) {
+			break
+		}
+		resp, err := p.fetch(ctx, client, next.String())
+		if err != nil {
+			break
+		}
+		last, current = resp, next
+	}
+
+	server := strings.TrimSpace(last.header.Get("Server"))
+	icon, _ := p.fetchFavicon(ctx, client, current, last.body)
+	return engine.WebService{
+		URL:           rawURL,
+		StatusCode:    first.status,
+		Title:         extractTitle(last.body),
+		Technologies:  detectTechnologies(last.header, last.body),
+		IP:            first.ip,
+		Domain:        start.Hostname(),
+		Location:      first.location,
+		RedirectChain: chain,
+		Webserver:     server,
+		FinalURL:      current.String(),
+		ContentType:   strings.TrimSpace(last.header.Get("Content-Type")),
+		ContentLength: last.length,
+		BodySHA256:    hex.EncodeToString(last.bodyHash[:]),
+		ResponseTime:  int(last.elapsed / time.Millisecond),
+		Headers:       flattenHeaders(last.header),
+		CDN:           detectCDN(last.header),
+		FaviconMMH3:   icon.mmh3,
+		FaviconMD5:    icon.md5,
+		FaviconURL:    icon.url,
+		TLSNames:      first.tlsNames,
+		DiscoveredAt:  time.Now(),
+	}, probeCertificate(start, first), nil
+}
+
+// probeCertificate describes the certificate served for u, or returns nil
+// when the response did not come over TLS.
+func probeCertificate(u *url.URL, resp httpProbeResponse) *engine.TLSCertificate {
+	if resp.cert == nil {
+		return nil
+	}
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		port = 443
+	}
+	cert := engine.CertificateFromX509(resp.cert)
+	cert.Host = strings.ToLower(u.Hostname())
+	cert.IP = resp.ip
+	cert.Port = port
+	cert.URL = u.String()
+	cert.Source = "http_probe"
+	return &cert
+}
+
+func (p *HTTPProbePlugin) fetch(ctx context.Context, client *http.Client, rawURL string) (httpProbeResponse, error) {
+	release, err := engine.HostLimiterFromContext(ctx).Acquire(ctx, rawURL)
+	if err != nil {
+		return httpProbeResponse{}, err
+	}
+	defer release()
+
+	var out httpProbeResponse
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if addr, ok := info.Conn.RemoteAddr().(*net.TCPAddr); ok {
+				out.ip = addr.IP.String()
+			}
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, rawURL, nil)
+	if err != nil {
+		return httpProbeResponse{}, err
+	}
+	req.Header.Set("User-Agent", httpProbeAgent)
+	req.Header.Set("Accept", "*/*")
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return httpProbeResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, httpProbeMaxBody))
+
+	out.elapsed = time.Since(started)
+	out.status = resp.StatusCode
+	out.header = resp.Header
+	out.body = body
+	out.bodyHash = sha256.Sum256(body)
+	out.length = len(body)
+	if resp.ContentLength > int64(out.length) {
+		out.length = int(resp.ContentLength)
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		out.location = strings.TrimSpace(resp.Header.Get("Location"))
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		cert := resp.TLS.PeerCertificates[0]
+		out.cert = cert
+		if cn := strings.TrimSpace(cert.Subject.CommonName); cn != "" {
+			out.tlsNames = append(out.tlsNames, cn)
+		}
+		out.tlsNames = append(out.tlsNames, cert.DNSNames...)
+	}
+	return out, nil
+}
+
+// flattenHeaders joins repeated header values with ", ".
+func flattenHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// extractTitle returns the page title with entities decoded and whitespace
+// collapsed.
+func extractTitle(body []byte) string {
+	m := titlePattern.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	if r := []rune(title); len(r) > httpProbeMaxTitle {
+		title = string(r[:httpProbeMaxTitle])
+	}
+	return title
+}
+
+// parsePortList parses a comma-separated port list, skipping invalid
+// entries.
+func parsePortList(raw string) []int {
+	var out []int
+	seen := make(map[int]bool)
+	for _, field := range strings.Split(raw, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || port <= 0 || port > 65535 || seen[port] {
+			continue
+		}
+		seen[port] = true
+		out = append(out, port)
+	}
+	return out
+}
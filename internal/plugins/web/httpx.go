@@ -2,11 +2,16 @@ package web
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +36,37 @@ type HttpxResult struct {
 	Webserver   string   `json:"webserver"`
 	CDN         bool     `json:"cdn"`
 	CDNName     string   `json:"cdn_name"`
+	Location    string   `json:"location"`  // redirect target
+	FinalURL    string   `json:"final_url"` // -fhr, last URL fetched
+	ChainStatus []int    `json:"chain_status_codes"`
+	ContentLen  int      `json:"content_length"`
+	Time        string   `json:"time"` // response time, e.g. "152.3ms"
+	Hash        struct {
+		BodySHA256 string `json:"body_sha256"`
+	} `json:"hash"`
+	Header     map[string]interface{} `json:"header"`  // -irh, keys lower_snake_case
+	Favicon    string                 `json:"favicon"` // mmh3
+	FaviconMD5 string                 `json:"favicon_md5"`
+	FaviconURL string                 `json:"favicon_url"`
+	CNAME      []string               `json:"cname"`
+	TLS        *HttpxTLS              `json:"tls"` // -tls-grab
+}
+
+// HttpxTLS is the certificate part of httpx's tls-grab output.
+type HttpxTLS struct {
+	IP              string    `json:"ip"`
+	SubjectDN       string    `json:"subject_dn"`
+	SubjectCN       string    `json:"subject_cn"`
+	SubjectAN       []string  `json:"subject_an"`
+	IssuerDN        string    `json:"issuer_dn"`
+	IssuerCN        string    `json:"issuer_cn"`
+	NotBefore       time.Time `json:"not_before"`
+	NotAfter        time.Time `json:"not_after"`
+	Serial          string    `json:"serial"` // colon-separated hex
+	SelfSigned      bool      `json:"self_signed"`
+	FingerprintHash struct {
+		SHA256 string `json:"sha256"`
+	} `json:"fingerprint_hash"`
 }
 
 // NewHttpxPlugin creates an Httpx plugin instance.
@@ -45,6 +81,12 @@ func (h *HttpxPlugin) Name() string {
 
 // Execute runs httpx against domains/subdomains and returns live web service results.
 func (h *HttpxPlugin) Execute(ctx context.Context, input []string) ([]engine.Result, error) {
+	return h.ExecuteStream(ctx, input, nil)
+}
+
+// ExecuteStream runs httpx and hands each live service to emit as soon as its
+// JSONL line is parsed.
+func (h *HttpxPlugin) ExecuteStream(ctx context.Context, input []string, emit engine.ResultHandler) ([]engine.Result, error) {
 	if _, err := exec.LookPath("httpx"); err != nil {
 		return nil, fmt.Errorf("httpx not found in PATH. Please install httpx and ensure it's in your PATH")
 	}
@@ -68,17 +110,38 @@ func (h *HttpxPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 	}
 	_ = tmpFile.Close()
 
-	cmd := exec.CommandContext(ctx, "httpx",
+	args := []string{
 		"-l", tmpFile.Name(),
 		"-json",
 		"-sc",
 		"-title",
 		"-td",
 		"-ip",
+		"-location",
+		"-follow-host-redirects",
+		"-cname",
+		"-tls-grab",
+		"-ct",
+		"-cl",
+		"-server",
+		"-cdn",
+		"-rt",
+		"-hash", "sha256",
+		"-irh",
+		"-favicon",
 		"-silent",
 		"-timeout", "10",
 		"-retries", "2",
-	)
+	}
+	// httpx defaults: -rl 150 requests/s, -t 50 threads.
+	rate, threads := engine.HostLimiterFromContext(ctx).Budget().Scale(engine.CountHosts(input), 150, 50)
+	if rate > 0 {
+		args = append(args, "-rl", strconv.Itoa(rate))
+	}
+	if threads > 0 {
+		args = append(args, "-t", strconv.Itoa(threads))
+	}
+	cmd := exec.CommandContext(ctx, "httpx", args...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -91,6 +154,9 @@ func (h *HttpxPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 
 	var results []engine.Result
 	scanner := bufio.NewScanner(stdout)
+	// Lines carry response headers and certificates and can exceed the
+	// default 64 KiB token size.
+	scanner.Buffer(make([]byte, 0, 1<<20), 16<<20)
 	liveCount := 0
 	seenURLs := make(map[string]bool)
 
@@ -117,18 +183,61 @@ func (h *HttpxPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 		seenURLs[url] = true
 		liveCount++
 
-		results = append(results, engine.Result{
-			Type: "web_service",
-			Data: map[string]interface{}{
-				"url":           url,
-				"status_code":   httpxResult.StatusCode,
-				"title":         httpxResult.Title,
-				"technologies":  httpxResult.Tech,
-				"ip":            ip,
-				"domain":        httpxResult.Host,
-				"discovered_at": time.Now(),
-			},
-		})
+		var tlsNames []string
+		if httpxResult.TLS != nil {
+			if cn := strings.TrimSpace(httpxResult.TLS.SubjectCN); cn != "" {
+				tlsNames = append(tlsNames, cn)
+			}
+			tlsNames = append(tlsNames, httpxResult.TLS.SubjectAN...)
+		}
+
+		// With redirects followed, status_code is the last response's;
+		// report the first one like the native probe does.
+		statusCode := httpxResult.StatusCode
+		if len(httpxResult.ChainStatus) > 0 {
+			statusCode = httpxResult.ChainStatus[0]
+		}
+
+		result := engine.WebService{
+			URL:           url,
+			StatusCode:    statusCode,
+			Title:         httpxResult.Title,
+			Technologies:  httpxResult.Tech,
+			IP:            ip,
+			Domain:        httpxResult.Host,
+			Location:      strings.TrimSpace(httpxResult.Location),
+			Webserver:     strings.TrimSpace(httpxResult.Webserver),
+			FinalURL:      cmp.Or(strings.TrimSpace(httpxResult.FinalURL), url),
+			ContentType:   strings.TrimSpace(httpxResult.ContentType),
+			ContentLength: httpxResult.ContentLen,
+			BodySHA256:    httpxResult.Hash.BodySHA256,
+			ResponseTime:  httpxResponseTime(httpxResult.Time),
+			Headers:       httpxHeaders(httpxResult.Header),
+			CDN:           strings.TrimSpace(httpxResult.CDNName),
+			FaviconMMH3:   strings.TrimSpace(httpxResult.Favicon),
+			FaviconMD5:    strings.TrimSpace(httpxResult.FaviconMD5),
+			FaviconURL:    strings.TrimSpace(httpxResult.FaviconURL),
+			CNAMEs:        httpxResult.CNAME,
+			TLSNames:      tlsNames,
+			DiscoveredAt:  time.Now(),
+		}.Result()
+		results = append(results, result)
+		if emit != nil {
+			emit(result)
+		}
+		if cert, ok := httpxCertificate(url, ip, httpxResult.TLS); ok {
+			certResult := cert.Result()
+			results = append(results, certResult)
+			if emit != nil {
+				emit(certResult)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("[Httpx] Failed to read output: %v\n", err)
+		// Drain the rest so httpx does not block on a full pipe.
+		_, _ = io.Copy(io.Discard, stdout)
 	}
 
 	if err := cmd.Wait(); err != nil {
@@ -139,3 +248,89 @@ func (h *HttpxPlugin) Execute(ctx context.Context, input []string) ([]engine.Res
 	fmt.Printf("[Httpx] Probe completed, found %d live services\n", liveCount)
 	return results, nil
 }
+
+// httpxCertificate converts the tls-grab output of an https URL. Lines
+// without a certificate fingerprint carry nothing to inventory.
+func httpxCertificate(rawURL, ip string, t *HttpxTLS) (engine.TLSCertificate, bool) {
+	if t == nil || strings.TrimSpace(t.FingerprintHash.SHA256) == "" {
+		return engine.TLSCertificate{}, false
+	}
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return engine.TLSCertificate{}, false
+	}
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		port = 443
+	}
+	if ip == "" {
+		ip = strings.TrimSpace(t.IP)
+	}
+	sans := make([]string, 0, len(t.SubjectAN))
+	for _, name := range t.SubjectAN {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			sans = append(sans, name)
+		}
+	}
+	return engine.TLSCertificate{
+		Host:              strings.ToLower(u.Hostname()),
+		IP:                ip,
+		Port:              port,
+		URL:               rawURL,
+		Source:            "httpx",
+		Subject:           strings.TrimSpace(t.SubjectDN),
+		SubjectCN:         strings.TrimSpace(t.SubjectCN),
+		Issuer:            strings.TrimSpace(t.IssuerDN),
+		IssuerCN:          strings.TrimSpace(t.IssuerCN),
+		SANs:              sans,
+		NotBefore:         t.NotBefore.UTC(),
+		NotAfter:          t.NotAfter.UTC(),
+		Serial:            httpxSerial(t.Serial),
+		FingerprintSHA256: strings.ToLower(strings.TrimSpace(t.FingerprintHash.SHA256)),
+		SelfSigned:        t.SelfSigned,
+	}, true
+}
+
+// httpxSerial converts "0A:1B:..." to the unpadded upper-case hex used by
+// engine.CertificateFromX509.
+func httpxSerial(raw string) string {
+	serial := strings.TrimLeft(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(raw), ":", "")), "0")
+	if serial == "" && strings.TrimSpace(raw) != "" {
+		return "0"
+	}
+	return serial
+}
+
+// httpxResponseTime converts httpx's "time" field to whole milliseconds.
+func httpxResponseTime(raw string) int {
+	d, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return int(d / time.Millisecond)
+}
+
+// httpxHeaders converts httpx's header map back to canonical header names.
+// Repeated headers are joined with ", ".
+func httpxHeaders(raw map[string]interface{}) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(raw))
+	for key, value := range raw {
+		name := http.CanonicalHeaderKey(strings.ReplaceAll(key, "_", "-"))
+		switch v := value.(type) {
+		case string:
+			out[name] = v
+		case []interface{}:
+			parts := make([]string, 0, len(v))
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+			out[name] = strings.Join(parts, ", ")
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,231 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// serverProducts maps Server / X-Powered-By product tokens to the names
+// httpx reports.
+var serverProducts = map[string]string{
+	"nginx":            "Nginx",
+	"apache":           "Apache HTTP Server",
+	"microsoft-iis":    "IIS",
+	"openresty":        "OpenResty",
+	"tengine":          "Tengine",
+	"litespeed":        "LiteSpeed",
+	"caddy":            "Caddy",
+	"envoy":            "Envoy",
+	"gunicorn":         "gunicorn",
+	"uvicorn":          "uvicorn",
+	"jetty":            "Jetty",
+	"apache-coyote":    "Apache Tomcat",
+	"kestrel":          "Kestrel",
+	"cloudflare":       "Cloudflare",
+	"awselb":           "Amazon ELB",
+	"amazons3":         "Amazon S3",
+	"php":              "PHP",
+	"asp.net":          "Microsoft ASP.NET",
+	"express":          "Express",
+	"next.js":          "Next.js",
+	"werkzeug":         "Werkzeug",
+	"phusion":          "Phusion Passenger",
+	"bigip":            "F5 BIG-IP",
+	"akamaighost":      "Akamai",
+	"gws":              "Google Web Server",
+	"varnish":          "Varnish",
+	"squid":            "Squid",
+	"servlet":          "Java Servlet",
+	"weblogic":         "Oracle WebLogic Server",
+	"websphere":        "IBM WebSphere",
+	"zope":             "Zope",
+	"mini_httpd":       "mini_httpd",
+	"lighttpd":         "lighttpd",
+	"cowboy":           "Cowboy",
+	"tornadoserver":    "TornadoServer",
+	"twistedweb":       "TwistedWeb",
+	"python":           "Python",
+	"openssl":          "OpenSSL",
+	"ubuntu":           "Ubuntu",
+	"debian":           "Debian",
+	"centos":           "CentOS",
+	"win32":            "Windows Server",
+	"unix":             "UNIX",
+	"microsofthttpapi": "Microsoft HTTPAPI",
+}
+
+// headerSignatures detect technologies from the presence of a header.
+var headerSignatures = map[string]string{
+	"Cf-Ray":               "Cloudflare",
+	"X-Amz-Cf-Id":          "Amazon CloudFront",
+	"X-Amz-Request-Id":     "Amazon S3",
+	"X-Vercel-Id":          "Vercel",
+	"X-Nf-Request-Id":      "Netlify",
+	"X-Github-Request-Id":  "GitHub Pages",
+	"X-Drupal-Cache":       "Drupal",
+	"X-Aspnet-Version":     "Microsoft ASP.NET",
+	"X-Aspnetmvc-Version":  "Microsoft ASP.NET MVC",
+	"X-Shopify-Stage":      "Shopify",
+	"X-Varnish":            "Varnish",
+	"X-Akamai-Transformed": "Akamai",
+	"X-Azure-Ref":          "Azure Front Door",
+	"X-Jenkins":            "Jenkins",
+	"X-Gitlab-Meta":        "GitLab",
+}
+
+// cookieSignatures detect technologies from session cookie names.
+var cookieSignatures = map[string]string{
+	"phpsessid":             "PHP",
+	"jsessionid":            "Java",
+	"asp.net_sessionid":     "Microsoft ASP.NET",
+	"aspsessionid":          "Microsoft ASP",
+	"laravel_session":       "Laravel",
+	"ci_session":            "CodeIgniter",
+	"csrftoken":             "Django",
+	"django_language":       "Django",
+	"_rails_session":        "Ruby on Rails",
+	"connect.sid":           "Express",
+	"wordpress_test_cookie": "WordPress",
+	"__cf_bm":               "Cloudflare Bot Management",
+	"awsalb":                "AWS Elastic Load Balancing",
+	"bigipserver":           "F5 BIG-IP",
+}
+
+// bodySignatures detect technologies from markers in the page body.
+var bodySignatures = []struct {
+	marker string
+	tech   string
+}{
+	{"/wp-content/", "WordPress"},
+	{"/wp-includes/", "WordPress"},
+	{"drupal-settings-json", "Drupal"},
+	{"/sites/default/files/", "Drupal"},
+	{"/media/jui/", "Joomla"},
+	{"__NEXT_DATA__", "Next.js"},
+	{"/_next/static/", "Next.js"},
+	{"__NUXT__", "Nuxt.js"},
+	{"ng-version=", "Angular"},
+	{"data-reactroot", "React"},
+	{"data-v-app", "Vue.js"},
+	{"jquery", "jQuery"},
+	{"bootstrap.min.css", "Bootstrap"},
+	{"cdn.shopify.com", "Shopify"},
+	{"static.wixstatic.com", "Wix"},
+	{"squarespace.com", "Squarespace"},
+	{"/static/grafana/", "Grafana"},
+	{"kibana", "Kibana"},
+	{"/webjars/", "Java"},
+	{"Powered by Discourse", "Discourse"},
+	{"/jenkins/", "Jenkins"},
+	{"swagger-ui", "Swagger UI"},
+	{"phpmyadmin", "phpMyAdmin"},
+	{"roundcube", "Roundcube"},
+	{"owa/auth", "Outlook Web App"},
+	{"/cas/login", "CAS"},
+	{"keycloak", "Keycloak"},
+}
+
+// cdnSignatures identify the CDN or WAF in front of a site, checked in
+// order against a header value (empty value = header present).
+var cdnSignatures = []struct {
+	header string
+	value  string
+	name   string
+}{
+	{"Cf-Ray", "", "cloudflare"},
+	{"X-Amz-Cf-Id", "", "cloudfront"},
+	{"X-Akamai-Transformed", "", "akamai"},
+	{"Server", "akamaighost", "akamai"},
+	{"X-Azure-Ref", "", "azure"},
+	{"X-Fastly-Request-Id", "", "fastly"},
+	{"X-Served-By", "cache-", "fastly"},
+	{"X-Sucuri-Id", "", "sucuri"},
+	{"X-Iinfo", "", "incapsula"},
+	{"X-Cdn", "incapsula", "incapsula"},
+	{"Server", "bigip", "f5"},
+	{"X-Vercel-Id", "", "vercel"},
+	{"X-Nf-Request-Id", "", "netlify"},
+	{"Server", "cloudflare", "cloudflare"},
+}
+
+var generatorPattern = regexp.MustCompile(`(?i)<meta[^>]+name=["']generator["'][^>]+content=["']([^"']+)["']`)
+
+// detectTechnologies returns a sorted list of technologies identified from
+// response headers and body markers. Server and X-Powered-By products keep
+// their version, e.g. "Nginx:1.24.0".
+func detectTechnologies(header http.Header, body []byte) []string {
+	found := make(map[string]bool)
+	for _, value := range []string{header.Get("Server"), header.Get("X-Powered-By")} {
+		for _, tech := range productTechnologies(value) {
+			found[tech] = true
+		}
+	}
+	for name, tech := range headerSignatures {
+		if header.Get(name) != "" {
+			found[tech] = true
+		}
+	}
+	for _, cookie := range header.Values("Set-Cookie") {
+		name := strings.ToLower(strings.TrimSpace(strings.SplitN(cookie, "=", 2)[0]))
+		for prefix, tech := range cookieSignatures {
+			if strings.HasPrefix(name, prefix) {
+				found[tech] = true
+			}
+		}
+	}
+	lower := bytes.ToLower(body)
+	for _, sig := range bodySignatures {
+		if bytes.Contains(lower, []byte(strings.ToLower(sig.marker))) {
+			found[sig.tech] = true
+		}
+	}
+	if m := generatorPattern.FindSubmatch(body); m != nil {
+		if generator := strings.TrimSpace(string(m[1])); generator != "" {
+			found[generator] = true
+		}
+	}
+	out := make([]string, 0, len(found))
+	for tech := range found {
+		out = append(out, tech)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// productTechnologies parses a header such as "nginx/1.24.0 (Ubuntu)" into
+// known products with their versions.
+func productTechnologies(value string) []string {
+	var out []string
+	for _, token := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == '(' || r == ')' || r == ',' || r == ';'
+	}) {
+		product, version, _ := strings.Cut(token, "/")
+		name, ok := serverProducts[strings.ToLower(product)]
+		if !ok {
+			continue
+		}
+		if version = strings.TrimSpace(version); version != "" {
+			name += ":" + version
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// detectCDN returns the CDN or WAF name in httpx's lower-case spelling, or
+// "" when none is recognised.
+func detectCDN(header http.Header) string {
+	for _, sig := range cdnSignatures {
+		value := header.Get(sig.header)
+		if value == "" {
+			continue
+		}
+		if sig.value == "" || strings.Contains(strings.ToLower(value), sig.value) {
+			return sig.name
+		}
+	}
+	return ""
+}
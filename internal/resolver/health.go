@@ -0,0 +1,163 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// HealthProbe is a name with known IPv4 addresses. A resolver that answers
+// it with any other address is treated as poisoned.
+type HealthProbe struct {
+	Host   string
+	Expect []string
+}
+
+// DefaultHealthProbes are stable anycast names whose addresses have not
+// changed in years.
+var DefaultHealthProbes = []HealthProbe{
+	{Host: "one.one.one.one", Expect: []string{"1.0.0.1", "1.1.1.1"}},
+	{Host: "dns.google", Expect: []string{"8.8.4.4", "8.8.8.8"}},
+}
+
+// DefaultNXDomainZone is the zone under which random names are queried to
+// detect NXDOMAIN hijacking. It must not have a wildcard record.
+const DefaultNXDomainZone = "example.com"
+
+// HealthCheck configures CheckHealth. Empty fields use the defaults.
+type HealthCheck struct {
+	Probes       []HealthProbe
+	NXDomainZone string
+	Timeout      time.Duration
+}
+
+// Health is the outcome of checking one resolver.
+type Health struct {
+	Server  string
+	Healthy bool
+	// Latency is the mean response time of the answered probes.
+	Latency time.Duration
+	// Poisoned is set when a probe was answered with unexpected addresses.
+	Poisoned bool
+	// Hijacked is set when a non-existent name was answered with addresses.
+	Hijacked bool
+	Err      error
+}
+
+// Bad reports whether the resolver answered incorrectly, as opposed to not
+// answering at all.
+func (h Health) Bad() bool {
+	return h.Poisoned || h.Hijacked
+}
+
+// CheckHealth queries server for every probe and for a random name under the
+// NXDOMAIN zone. The server is healthy when every probe returns only the
+// expected addresses and the random name does not exist.
+func CheckHealth(ctx context.Context, server string, check HealthCheck) Health {
+	addr := NormalizeServer(server)
+	out := Health{Server: addr}
+	if addr == "" {
+		out.Err = fmt.Errorf("invalid resolver address %q", server)
+		return out
+	}
+	probes := check.Probes
+	if len(probes) == 0 {
+		probes = DefaultHealthProbes
+	}
+	zone := strings.Trim(strings.ToLower(strings.TrimSpace(check.NXDomainZone)), ".")
+	if zone == "" {
+		zone = DefaultNXDomainZone
+	}
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	r := NewPool([]string{addr}, timeout, 1)
+
+	var total time.Duration
+	for _, probe := range probes {
+		start := time.Now()
+		ips, err := lookupIPv4(ctx, r, probe.Host)
+		if err != nil {
+			out.Err = fmt.Errorf("%s: %v", probe.Host, err)
+			return out
+		}
+		total += time.Since(start)
+		if unexpected := unexpectedAddrs(ips, probe.Expect); len(unexpected) > 0 || len(ips) == 0 {
+			out.Poisoned = true
+			out.Err = fmt.Errorf("%s answered %s", probe.Host, strings.Join(ips, ","))
+			return out
+		}
+	}
+	if len(probes) > 0 {
+		out.Latency = total / time.Duration(len(probes))
+	}
+
+	name := randomLabel() + "." + zone
+	ips, err := lookupIPv4(ctx, r, name)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		out.Err = fmt.Errorf("nxdomain probe: %v", err)
+		return out
+	default:
+		out.Hijacked = true
+		out.Err = fmt.Errorf("non-existent %s answered %s", name, strings.Join(ips, ","))
+		return out
+	}
+	out.Healthy = true
+	return out
+}
+
+// CheckHealthAll checks servers with up to concurrency checks in flight.
+// Results are returned in the order of servers.
+func CheckHealthAll(ctx context.Context, servers []string, check HealthCheck, concurrency int) []Health {
+	if concurrency <= 0 {
+		concurrency = 20
+	}
+	out := make([]Health, len(servers))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, server := range servers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, server string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			out[i] = CheckHealth(ctx, server, check)
+		}(i, server)
+	}
+	wg.Wait()
+	return out
+}
+
+func lookupIPv4(ctx context.Context, r *Pool, host string) ([]string, error) {
+	answers, err := r.lookup(ctx, normalizeHost(host), dnsmessage.TypeA)
+	if err != nil {
+		return nil, err
+	}
+	_, ips := followCNAME(normalizeHost(host), answers)
+	if len(ips) == 0 {
+		return nil, ErrNotFound
+	}
+	return uniqueSorted(ips), nil
+}
+
+func unexpectedAddrs(got, expect []string) []string {
+	allowed := make(map[string]bool, len(expect))
+	for _, ip := range expect {
+		allowed[ip] = true
+	}
+	var out []string
+	for _, ip := range got {
+		if !allowed[ip] {
+			out = append(out, ip)
+		}
+	}
+	return out
+}
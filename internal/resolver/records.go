@@ -0,0 +1,160 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Records is the DNS record inventory of one hostname. MX, NS and TXT are
+// only looked up for names without a CNAME, since an alias cannot own other
+// records. Failed lists the record types ("A", "MX", ...) whose lookup
+// failed; their sets are unknown rather than empty.
+type Records struct {
+	Host   string
+	A      []string
+	AAAA   []string
+	CNAME  []string
+	MX     []string
+	NS     []string
+	TXT    []string
+	Failed []string
+}
+
+// Empty reports whether no record was found.
+func (r Records) Empty() bool {
+	return len(r.A)+len(r.AAAA)+len(r.CNAME)+len(r.MX)+len(r.NS)+len(r.TXT) == 0
+}
+
+// LookupRecords collects the A, AAAA, CNAME, MX, NS and TXT records of
+// host. The CNAME target comes from the alias chain of the address answers,
+// so it is unknown only when both address lookups failed. Missing record
+// types are not errors; failed ones are listed in Records.Failed, and an
+// error is returned only when every lookup failed.
+func (p *Pool) LookupRecords(ctx context.Context, host string) (Records, error) {
+	host = normalizeHost(host)
+	out := Records{Host: host}
+	if host == "" {
+		return out, ErrNotFound
+	}
+
+	var failures, total int
+	var lastErr error
+	failed := make(map[string]bool, 6)
+	lookup := func(qtype dnsmessage.Type) []dnsmessage.Resource {
+		total++
+		answers, err := p.lookup(ctx, host, qtype)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			failures++
+			failed[typeName(qtype)] = true
+			lastErr = err
+			return nil
+		}
+		return answers
+	}
+
+	cname, ips := followCNAME(host, lookup(dnsmessage.TypeA))
+	out.A = uniqueSorted(ips)
+	aaaaTarget, ips := followCNAME(host, lookup(dnsmessage.TypeAAAA))
+	out.AAAA = uniqueSorted(ips)
+	if cname == "" {
+		cname = aaaaTarget
+	}
+	if cname != "" {
+		out.CNAME = []string{cname}
+	} else if failed["A"] && failed["AAAA"] {
+		failed["CNAME"] = true
+	}
+	if len(out.CNAME) == 0 {
+		for _, rr := range lookup(dnsmessage.TypeMX) {
+			if body, ok := rr.Body.(*dnsmessage.MXResource); ok && normalizeHost(rr.Header.Name.String()) == host {
+				out.MX = append(out.MX, normalizeHost(body.MX.String()))
+			}
+		}
+		for _, rr := range lookup(dnsmessage.TypeNS) {
+			if body, ok := rr.Body.(*dnsmessage.NSResource); ok && normalizeHost(rr.Header.Name.String()) == host {
+				out.NS = append(out.NS, normalizeHost(body.NS.String()))
+			}
+		}
+		for _, rr := range lookup(dnsmessage.TypeTXT) {
+			if body, ok := rr.Body.(*dnsmessage.TXTResource); ok && normalizeHost(rr.Header.Name.String()) == host {
+				out.TXT = append(out.TXT, strings.Join(body.TXT, ""))
+			}
+		}
+	}
+
+	out.MX = uniqueSorted(out.MX)
+	out.NS = uniqueSorted(out.NS)
+	out.TXT = uniqueSorted(out.TXT)
+	for typ := range failed {
+		out.Failed = append(out.Failed, typ)
+	}
+	sort.Strings(out.Failed)
+	if failures == total {
+		return out, lastErr
+	}
+	if out.Empty() {
+		return out, ErrNotFound
+	}
+	return out, nil
+}
+
+// LookupRecordsAll runs LookupRecords for hosts with up to concurrency hosts
+// in flight and returns the inventories of names that have records.
+func (p *Pool) LookupRecordsAll(ctx context.Context, hosts []string, concurrency int) map[string]Records {
+	if concurrency <= 0 {
+		concurrency = 50
+	}
+	out := make(map[string]Records, len(hosts))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				records, err := p.LookupRecords(ctx, host)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				out[records.Host] = records
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, host := range hosts {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- host
+	}
+	close(jobs)
+	wg.Wait()
+	return out
+}
+
+func uniqueSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
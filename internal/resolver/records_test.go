@@ -0,0 +1,144 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeZone maps "host TYPE" to the answer of a fake server. Missing entries
+// answer NOERROR without records; nil answers with SERVFAIL.
+type fakeZone map[string][]dnsmessage.Resource
+
+func serveFakeDNS(t *testing.T, zone fakeZone) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on udp: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			q := query.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+				Questions: query.Questions,
+			}
+			key := normalizeHost(q.Name.String()) + " " + typeName(q.Type)
+			if answers, ok := zone[key]; ok && answers == nil {
+				resp.RCode = dnsmessage.RCodeServerFailure
+			} else {
+				resp.Answers = answers
+			}
+			out, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(out, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func mxRR(t *testing.T, name, host string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(t, name), Type: dnsmessage.TypeMX, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.MXResource{Pref: 10, MX: mustName(t, host)},
+	}
+}
+
+func txtRR(t *testing.T, name string, txt ...string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(t, name), Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.TXTResource{TXT: txt},
+	}
+}
+
+func TestLookupRecords(t *testing.T) {
+	zone := fakeZone{
+		"full.test A":   {aRR(t, "full.test.", [4]byte{192, 0, 2, 1})},
+		"full.test MX":  {mxRR(t, "full.test.", "mx.full.test.")},
+		"full.test TXT": {txtRR(t, "full.test.", "v=spf1 ", "-all")},
+
+		"partial.test A":    {aRR(t, "partial.test.", [4]byte{192, 0, 2, 2})},
+		"partial.test AAAA": nil,
+		"partial.test TXT":  nil,
+
+		"noaddr.test A":    nil,
+		"noaddr.test AAAA": nil,
+		"noaddr.test MX":   nil,
+		"noaddr.test NS":   nil,
+		"noaddr.test TXT":  {txtRR(t, "noaddr.test.", "hello")},
+
+		"alias.test A": {
+			cnameRR(t, "alias.test.", "edge.cdn.test."),
+			aRR(t, "edge.cdn.test.", [4]byte{198, 51, 100, 1}),
+		},
+		"alias.test AAAA": nil,
+
+		"down.test A":    nil,
+		"down.test AAAA": nil,
+		"down.test MX":   nil,
+		"down.test NS":   nil,
+		"down.test TXT":  nil,
+	}
+	pool := NewPool([]string{serveFakeDNS(t, zone)}, time.Second, 1)
+
+	tests := []struct {
+		host    string
+		want    Records
+		wantErr bool
+	}{
+		{
+			host: "full.test",
+			want: Records{Host: "full.test", A: []string{"192.0.2.1"}, MX: []string{"mx.full.test"}, TXT: []string{"v=spf1 -all"}},
+		},
+		{
+			host: "partial.test",
+			want: Records{Host: "partial.test", A: []string{"192.0.2.2"}, Failed: []string{"AAAA", "TXT"}},
+		},
+		{
+			// The alias target is unknown when both address lookups fail.
+			host: "noaddr.test",
+			want: Records{Host: "noaddr.test", TXT: []string{"hello"}, Failed: []string{"A", "AAAA", "CNAME", "MX", "NS"}},
+		},
+		{
+			// Names with a CNAME own no other records, so MX/NS/TXT are
+			// not looked up.
+			host: "alias.test",
+			want: Records{Host: "alias.test", A: []string{"198.51.100.1"}, CNAME: []string{"edge.cdn.test"}, Failed: []string{"AAAA"}},
+		},
+		{host: "down.test", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := pool.LookupRecords(context.Background(), tt.host)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "rcode") {
+					t.Fatalf("LookupRecords() error = %v, want a server failure", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupRecords: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupRecords() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
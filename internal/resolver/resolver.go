@@ -0,0 +1,328 @@
+// Package resolver is a small DNS client used instead of external tools for
+// brute-force resolution and wildcard detection. Queries are built with
+// dnsmessage, spread across a pool of upstream resolvers and retried on
+// timeouts and server failures.
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	defaultTimeout = 3 * time.Second
+	defaultRetries = 2
+	// maxCNAMEHops bounds how far an alias chain in one answer is followed.
+	maxCNAMEHops = 8
+
+	resolvConfPath = "/etc/resolv.conf"
+)
+
+// ErrNotFound is returned for names that do not exist (NXDOMAIN or no
+// address records).
+var ErrNotFound = errors.New("no such host")
+
+// Answer is the resolved state of one hostname.
+type Answer struct {
+	Host  string
+	IPs   []string
+	CNAME string
+}
+
+// Pool resolves names through a rotating set of upstream servers. Queries
+// are sent as-is: no search list, ndots or resolv.conf options apply.
+type Pool struct {
+	servers []string
+	retries int
+	timeout time.Duration
+	next    atomic.Uint64
+}
+
+// NewPool creates a pool for servers ("ip" or "ip:port"). timeout and
+// retries fall back to defaults when not positive. Without servers the pool
+// uses the nameservers of /etc/resolv.conf.
+func NewPool(servers []string, timeout time.Duration, retries int) *Pool {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if retries <= 0 {
+		retries = defaultRetries
+	}
+	p := &Pool{retries: retries, timeout: timeout}
+	seen := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		addr := NormalizeServer(server)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		p.servers = append(p.servers, addr)
+	}
+	if len(p.servers) == 0 {
+		p.servers = systemServers()
+	}
+	return p
+}
+
+// NewPoolFromFile reads one resolver per line from path ('#' starts a
+// comment). An empty path yields a pool backed by the system nameservers.
+func NewPoolFromFile(path string) (*Pool, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return NewPool(nil, 0, 0), nil
+	}
+	servers, err := ReadServers(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("resolvers file %s contains no servers", path)
+	}
+	return NewPool(servers, 0, 0), nil
+}
+
+// ReadServers reads a resolvers file.
+func ReadServers(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open resolvers file: %v", err)
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		if addr := NormalizeServer(line); addr != "" {
+			servers = append(servers, addr)
+		}
+	}
+	return servers, scanner.Err()
+}
+
+// systemServers returns the nameservers listed in /etc/resolv.conf, or the
+// local resolver when there are none.
+func systemServers() []string {
+	var servers []string
+	if data, err := os.ReadFile(resolvConfPath); err == nil {
+		servers = parseResolvConf(string(data))
+	}
+	if len(servers) == 0 {
+		servers = []string{"127.0.0.1:53"}
+	}
+	return servers
+}
+
+// parseResolvConf returns the nameserver addresses of a resolv.conf file.
+func parseResolvConf(data string) []string {
+	var servers []string
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		// Strip an IPv6 zone; NormalizeServer only accepts plain addresses.
+		if addr := NormalizeServer(strings.SplitN(fields[1], "%", 2)[0]); addr != "" {
+			servers = append(servers, addr)
+		}
+	}
+	return servers
+}
+
+// Servers returns the upstream servers the pool queries.
+func (p *Pool) Servers() []string {
+	return append([]string(nil), p.servers...)
+}
+
+// NormalizeServer returns raw as "ip:port" (port 53 by default), or "" when
+// it is not an IP address.
+func NormalizeServer(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(raw); err == nil {
+		return raw
+	}
+	if ip := net.ParseIP(strings.Trim(raw, "[]")); ip != nil {
+		return net.JoinHostPort(ip.String(), "53")
+	}
+	return ""
+}
+
+func (p *Pool) pick() string {
+	n := p.next.Add(1)
+	return p.servers[int(n%uint64(len(p.servers)))]
+}
+
+// lookup asks for the qtype records of name and returns the answer
+// section. Each attempt uses the next server in the pool; timeouts and
+// server failures are retried, NXDOMAIN is returned as ErrNotFound.
+func (p *Pool) lookup(ctx context.Context, name string, qtype dnsmessage.Type) ([]dnsmessage.Resource, error) {
+	query, err := buildQuery(name, qtype, false, true)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for attempt := 0; attempt <= p.retries; attempt++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		resp, err := p.exchange(ctx, p.pick(), query)
+		if err == nil {
+			switch resp.RCode {
+			case dnsmessage.RCodeSuccess:
+				return resp.Answers, nil
+			case dnsmessage.RCodeNameError:
+				return nil, ErrNotFound
+			}
+			err = fmt.Errorf("lookup %s: rcode %s", name, resp.RCode)
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// exchange sends query to addr over UDP and repeats it over TCP when the
+// answer is truncated.
+func (p *Pool) exchange(ctx context.Context, addr string, query []byte) (dnsmessage.Message, error) {
+	var resp dnsmessage.Message
+	msg, err := exchangeUDP(ctx, addr, query, p.timeout)
+	if err != nil {
+		return resp, err
+	}
+	if err := resp.Unpack(msg); err != nil {
+		return resp, err
+	}
+	if !resp.Truncated {
+		return resp, nil
+	}
+	if msg, err = exchangeTCP(ctx, addr, query, p.timeout); err != nil {
+		return resp, err
+	}
+	err = resp.Unpack(msg)
+	return resp, err
+}
+
+// Resolve looks up the addresses and CNAME target of host. NXDOMAIN and
+// names without address records return ErrNotFound.
+func (p *Pool) Resolve(ctx context.Context, host string) (Answer, error) {
+	host = normalizeHost(host)
+	answer := Answer{Host: host}
+	if host == "" {
+		return answer, ErrNotFound
+	}
+
+	var lastErr error
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answers, err := p.lookup(ctx, host, qtype)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		cname, ips := followCNAME(host, answers)
+		if cname != "" {
+			answer.CNAME = cname
+		}
+		answer.IPs = append(answer.IPs, ips...)
+	}
+	answer.IPs = uniqueSorted(answer.IPs)
+	if len(answer.IPs) > 0 {
+		return answer, nil
+	}
+	if lastErr != nil {
+		return answer, lastErr
+	}
+	return answer, ErrNotFound
+}
+
+// ResolveAll resolves hosts with up to concurrency lookups in flight and
+// returns the answers of names that exist, keyed by host.
+func (p *Pool) ResolveAll(ctx context.Context, hosts []string, concurrency int) map[string]Answer {
+	if concurrency <= 0 {
+		concurrency = 50
+	}
+	out := make(map[string]Answer, len(hosts))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				answer, err := p.Resolve(ctx, host)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				out[answer.Host] = answer
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, host := range hosts {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- host
+	}
+	close(jobs)
+	wg.Wait()
+	return out
+}
+
+// followCNAME follows the CNAME chain of name through answers. It returns
+// the final target ("" when name is not an alias) and the A and AAAA
+// addresses owned by names on the chain.
+func followCNAME(name string, answers []dnsmessage.Resource) (string, []string) {
+	chain := map[string]bool{name: true}
+	target := name
+	for hop := 0; hop < maxCNAMEHops; hop++ {
+		next := ""
+		for _, rr := range answers {
+			if body, ok := rr.Body.(*dnsmessage.CNAMEResource); ok && normalizeHost(rr.Header.Name.String()) == target {
+				next = normalizeHost(body.CNAME.String())
+				break
+			}
+		}
+		if next == "" || chain[next] {
+			break
+		}
+		chain[next] = true
+		target = next
+	}
+
+	var ips []string
+	for _, rr := range answers {
+		if !chain[normalizeHost(rr.Header.Name.String())] {
+			continue
+		}
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			ips = append(ips, net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			ips = append(ips, net.IP(body.AAAA[:]).String())
+		}
+	}
+	if target == name {
+		target = ""
+	}
+	return target, ips
+}
@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestNormalizeServer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"8.8.8.8", "8.8.8.8:53"},
+		{" 1.1.1.1:5353 ", "1.1.1.1:5353"},
+		{"2001:4860:4860::8888", "[2001:4860:4860::8888]:53"},
+		{"[2001:4860:4860::8888]", "[2001:4860:4860::8888]:53"},
+		{"[::1]:5353", "[::1]:5353"},
+		{"dns.google", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeServer(tt.in); got != tt.want {
+			t.Errorf("NormalizeServer(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "nameservers only",
+			data: "# generated\nsearch corp.example.com\noptions ndots:5 attempts:3\nnameserver 10.0.0.2\nnameserver 10.0.0.3\n",
+			want: []string{"10.0.0.2:53", "10.0.0.3:53"},
+		},
+		{
+			name: "ipv6 with zone",
+			data: "nameserver fe80::1%eth0\nnameserver ::1\n",
+			want: []string{"[fe80::1]:53", "[::1]:53"},
+		},
+		{
+			name: "malformed lines",
+			data: "nameserver\nnameserver resolver.local\n  nameserver   192.0.2.53  \n",
+			want: []string{"192.0.2.53:53"},
+		},
+		{
+			name: "empty",
+			data: "",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseResolvConf(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseResolvConf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustName(t *testing.T, name string) dnsmessage.Name {
+	t.Helper()
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		t.Fatalf("NewName(%q): %v", name, err)
+	}
+	return n
+}
+
+func cnameRR(t *testing.T, name, target string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(t, name), Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.CNAMEResource{CNAME: mustName(t, target)},
+	}
+}
+
+func aRR(t *testing.T, name string, ip [4]byte) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(t, name), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.AResource{A: ip},
+	}
+}
+
+func TestFollowCNAME(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		answers    []dnsmessage.Resource
+		wantTarget string
+		wantIPs    []string
+	}{
+		{
+			name:    "direct address",
+			host:    "www.example.com",
+			answers: []dnsmessage.Resource{aRR(t, "www.example.com.", [4]byte{192, 0, 2, 1})},
+			wantIPs: []string{"192.0.2.1"},
+		},
+		{
+			name: "alias chain",
+			host: "www.example.com",
+			answers: []dnsmessage.Resource{
+				cnameRR(t, "www.example.com.", "edge.cdn.test."),
+				cnameRR(t, "edge.cdn.test.", "pop1.cdn.test."),
+				aRR(t, "pop1.cdn.test.", [4]byte{198, 51, 100, 7}),
+			},
+			wantTarget: "pop1.cdn.test",
+			wantIPs:    []string{"198.51.100.7"},
+		},
+		{
+			name: "addresses of unrelated names are ignored",
+			host: "www.example.com",
+			answers: []dnsmessage.Resource{
+				cnameRR(t, "www.example.com.", "edge.cdn.test."),
+				aRR(t, "other.test.", [4]byte{203, 0, 113, 1}),
+			},
+			wantTarget: "edge.cdn.test",
+		},
+		{
+			name: "loop stops",
+			host: "a.example.com",
+			answers: []dnsmessage.Resource{
+				cnameRR(t, "a.example.com.", "b.example.com."),
+				cnameRR(t, "b.example.com.", "a.example.com."),
+			},
+			wantTarget: "b.example.com",
+		},
+		{
+			name: "no answers",
+			host: "www.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, ips := followCNAME(tt.host, tt.answers)
+			if target != tt.wantTarget || !reflect.DeepEqual(ips, tt.wantIPs) {
+				t.Errorf("followCNAME() = (%q, %v), want (%q, %v)", target, ips, tt.wantTarget, tt.wantIPs)
+			}
+		})
+	}
+}
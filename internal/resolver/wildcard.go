@@ -0,0 +1,235 @@
+package resolver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// wildcardProbes is how many random labels are resolved per parent zone.
+// Several probes catch wildcards that rotate through a set of addresses.
+const wildcardProbes = 3
+
+// WildcardZone is a parent zone whose random labels resolve.
+type WildcardZone struct {
+	Zone  string   `json:"zone"`
+	IPs   []string `json:"ips"`
+	CNAME string   `json:"cname,omitempty"`
+}
+
+type zoneFingerprint struct {
+	wildcard bool
+	ips      map[string]bool
+	cname    string
+}
+
+// WildcardDetector fingerprints wildcard DNS per parent label. A name is a
+// wildcard hit when its parent answers random labels and the name resolves
+// to nothing but the addresses (or CNAME target) those random labels got.
+type WildcardDetector struct {
+	pool *Pool
+
+	mu    sync.Mutex
+	zones map[string]*zoneFingerprint
+	locks map[string]*sync.Mutex
+}
+
+// NewWildcardDetector creates a detector that resolves through pool.
+func NewWildcardDetector(pool *Pool) *WildcardDetector {
+	return &WildcardDetector{
+		pool:  pool,
+		zones: make(map[string]*zoneFingerprint),
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// IsWildcardHit reports whether answer for host is explained by a wildcard
+// record on host's parent.
+func (d *WildcardDetector) IsWildcardHit(ctx context.Context, answer Answer) bool {
+	parent := parentZone(answer.Host)
+	if parent == "" {
+		return false
+	}
+	fp := d.fingerprint(ctx, parent)
+	if !fp.wildcard {
+		return false
+	}
+	if answer.CNAME != "" && fp.cname != "" && answer.CNAME == fp.cname {
+		return true
+	}
+	if len(answer.IPs) == 0 {
+		return false
+	}
+	for _, ip := range answer.IPs {
+		if !fp.ips[ip] {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter resolves hosts and splits them into real names and wildcard hits.
+// Hosts that do not resolve are kept; they are not wildcard noise.
+// Parents are fingerprinted first so only hosts under a wildcard zone are
+// resolved.
+func (d *WildcardDetector) Filter(ctx context.Context, hosts []string, concurrency int) (kept, dropped []string) {
+	parents := make(map[string]bool)
+	for _, host := range hosts {
+		if parent := parentZone(normalizeHost(host)); parent != "" {
+			parents[parent] = true
+		}
+	}
+	parentList := make([]string, 0, len(parents))
+	for parent := range parents {
+		parentList = append(parentList, parent)
+	}
+	d.fingerprintAll(ctx, parentList, concurrency)
+
+	var suspects []string
+	for _, host := range hosts {
+		parent := parentZone(normalizeHost(host))
+		if parent != "" && d.fingerprint(ctx, parent).wildcard {
+			suspects = append(suspects, normalizeHost(host))
+		}
+	}
+	if len(suspects) == 0 {
+		return hosts, nil
+	}
+
+	answers := d.pool.ResolveAll(ctx, suspects, concurrency)
+	drop := make(map[string]bool)
+	for _, host := range suspects {
+		if answer, ok := answers[host]; ok && d.IsWildcardHit(ctx, answer) {
+			drop[host] = true
+		}
+	}
+	kept = make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if drop[normalizeHost(host)] {
+			dropped = append(dropped, host)
+			continue
+		}
+		kept = append(kept, host)
+	}
+	return kept, dropped
+}
+
+// FilterWildcards splits hosts into real names and wildcard hits, resolving
+// through the resolvers listed in resolversFile (see NewPoolFromFile). It
+// also returns the wildcard zones behind the hits.
+func FilterWildcards(ctx context.Context, resolversFile string, hosts []string, concurrency int) (kept, dropped []string, zones []WildcardZone, err error) {
+	pool, err := NewPoolFromFile(resolversFile)
+	if err != nil {
+		return hosts, nil, nil, err
+	}
+	detector := NewWildcardDetector(pool)
+	kept, dropped = detector.Filter(ctx, hosts, concurrency)
+	return kept, dropped, detector.Zones(), nil
+}
+
+// Zones returns every wildcard zone detected so far.
+func (d *WildcardDetector) Zones() []WildcardZone {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []WildcardZone
+	for zone, fp := range d.zones {
+		if !fp.wildcard {
+			continue
+		}
+		ips := make([]string, 0, len(fp.ips))
+		for ip := range fp.ips {
+			ips = append(ips, ip)
+		}
+		sort.Strings(ips)
+		out = append(out, WildcardZone{Zone: zone, IPs: ips, CNAME: fp.cname})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Zone < out[j].Zone })
+	return out
+}
+
+func (d *WildcardDetector) fingerprintAll(ctx context.Context, zones []string, concurrency int) {
+	if concurrency <= 0 {
+		concurrency = 20
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, zone := range zones {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(zone string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.fingerprint(ctx, zone)
+		}(zone)
+	}
+	wg.Wait()
+}
+
+// fingerprint probes zone once and caches the outcome. Concurrent callers
+// for the same zone wait for the first probe.
+func (d *WildcardDetector) fingerprint(ctx context.Context, zone string) *zoneFingerprint {
+	d.mu.Lock()
+	if fp, ok := d.zones[zone]; ok {
+		d.mu.Unlock()
+		return fp
+	}
+	lock, ok := d.locks[zone]
+	if !ok {
+		lock = &sync.Mutex{}
+		d.locks[zone] = lock
+	}
+	d.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+	d.mu.Lock()
+	if fp, ok := d.zones[zone]; ok {
+		d.mu.Unlock()
+		return fp
+	}
+	d.mu.Unlock()
+
+	fp := &zoneFingerprint{ips: make(map[string]bool)}
+	for i := 0; i < wildcardProbes; i++ {
+		answer, err := d.pool.Resolve(ctx, randomLabel()+"."+zone)
+		if err != nil {
+			continue
+		}
+		fp.wildcard = true
+		for _, ip := range answer.IPs {
+			fp.ips[ip] = true
+		}
+		if answer.CNAME != "" {
+			fp.cname = answer.CNAME
+		}
+	}
+	if ctx.Err() != nil {
+		// Do not cache an interrupted probe as "not a wildcard".
+		return fp
+	}
+	d.mu.Lock()
+	d.zones[zone] = fp
+	d.mu.Unlock()
+	return fp
+}
+
+func parentZone(host string) string {
+	idx := strings.Index(host, ".")
+	if idx < 0 || !strings.Contains(host[idx+1:], ".") {
+		return ""
+	}
+	return host[idx+1:]
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func randomLabel() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "wc-" + hex.EncodeToString(buf)
+}
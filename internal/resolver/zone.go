@@ -0,0 +1,359 @@
+package resolver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	typeNSEC  dnsmessage.Type = 47
+	typeNSEC3 dnsmessage.Type = 50
+
+	// maxTransferRecords bounds an AXFR so a hostile server cannot stream
+	// forever.
+	maxTransferRecords = 500000
+	// maxWalkSteps bounds an NSEC walk.
+	maxWalkSteps = 20000
+)
+
+var (
+	// ErrTransferRefused is returned when a server declines AXFR.
+	ErrTransferRefused = errors.New("zone transfer refused")
+	// ErrNotWalkable is returned when a zone does not serve plain NSEC
+	// records (unsigned, NSEC3, or online-signed "white lies").
+	ErrNotWalkable = errors.New("zone is not NSEC-walkable")
+)
+
+// Gate is called before each query sent to a nameserver and returns a
+// function that ends the query. Callers use it to apply a rate limit; a nil
+// Gate lets every query through.
+type Gate func(ctx context.Context) (func(), error)
+
+// ZoneRecord is one owner name seen in a zone, with its record type.
+type ZoneRecord struct {
+	Name string
+	Type string
+}
+
+// Transfer performs an AXFR of zone against server ("ip" or "ip:port") and
+// returns every record owner in the zone.
+func Transfer(ctx context.Context, server, zone string, timeout time.Duration) ([]ZoneRecord, error) {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	addr := NormalizeServer(server)
+	if addr == "" {
+		return nil, fmt.Errorf("invalid nameserver address %q", server)
+	}
+	query, err := buildQuery(zone, dnsmessage.TypeAXFR, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := writeTCPMessage(conn, query); err != nil {
+		return nil, err
+	}
+
+	var records []ZoneRecord
+	soaSeen := 0
+	for soaSeen < 2 {
+		msg, err := readTCPMessage(conn)
+		if err != nil {
+			if len(records) > 0 && errors.Is(err, io.EOF) {
+				break
+			}
+			return records, err
+		}
+		var p dnsmessage.Parser
+		header, err := p.Start(msg)
+		if err != nil {
+			return records, err
+		}
+		if header.RCode != dnsmessage.RCodeSuccess {
+			return nil, fmt.Errorf("%w: rcode %s", ErrTransferRefused, header.RCode)
+		}
+		if err := p.SkipAllQuestions(); err != nil {
+			return records, err
+		}
+		answers := 0
+		for {
+			h, err := p.AnswerHeader()
+			if errors.Is(err, dnsmessage.ErrSectionDone) {
+				break
+			}
+			if err != nil {
+				return records, err
+			}
+			if err := p.SkipAnswer(); err != nil {
+				return records, err
+			}
+			answers++
+			if h.Type == dnsmessage.TypeSOA {
+				soaSeen++
+			}
+			records = append(records, ZoneRecord{Name: normalizeHost(h.Name.String()), Type: typeName(h.Type)})
+			if len(records) >= maxTransferRecords {
+				return records, nil
+			}
+		}
+		if answers == 0 {
+			if len(records) == 0 {
+				return nil, fmt.Errorf("%w: empty answer", ErrTransferRefused)
+			}
+			break
+		}
+	}
+	return records, nil
+}
+
+// WalkNSEC enumerates zone by following the NSEC chain on server. It stops
+// when the chain wraps back to the apex. Each query passes through gate.
+func WalkNSEC(ctx context.Context, server, zone string, timeout time.Duration, gate Gate) ([]string, error) {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	addr := NormalizeServer(server)
+	if addr == "" {
+		return nil, fmt.Errorf("invalid nameserver address %q", server)
+	}
+	zone = normalizeHost(zone)
+
+	var names []string
+	seen := map[string]bool{zone: true}
+	current := zone
+	for step := 0; step < maxWalkSteps; step++ {
+		if ctx.Err() != nil {
+			return names, ctx.Err()
+		}
+		release := func() {}
+		if gate != nil {
+			var err error
+			if release, err = gate(ctx); err != nil {
+				return names, err
+			}
+		}
+		next, err := queryNSECNext(ctx, addr, current, timeout)
+		release()
+		if err != nil {
+			if len(names) > 0 {
+				return names, err
+			}
+			return nil, err
+		}
+		if next == zone || seen[next] {
+			return names, nil
+		}
+		if !strings.HasSuffix(next, "."+zone) {
+			return names, nil
+		}
+		seen[next] = true
+		names = append(names, next)
+		current = next
+	}
+	return names, nil
+}
+
+// queryNSECNext asks server for the NSEC record owned by name and returns
+// its "next domain name".
+func queryNSECNext(ctx context.Context, addr, name string, timeout time.Duration) (string, error) {
+	query, err := buildQuery(name, typeNSEC, true, false)
+	if err != nil {
+		return "", err
+	}
+	msg, err := exchangeUDP(ctx, addr, query, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil {
+		return "", err
+	}
+	if header.Truncated {
+		if msg, err = exchangeTCP(ctx, addr, query, timeout); err != nil {
+			return "", err
+		}
+		if header, err = p.Start(msg); err != nil {
+			return "", err
+		}
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return "", fmt.Errorf("%w: rcode %s for %s", ErrNotWalkable, header.RCode, name)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return "", err
+	}
+	for {
+		h, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if h.Type != typeNSEC || normalizeHost(h.Name.String()) != name {
+			if err := p.SkipAnswer(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		body, err := p.UnknownResource()
+		if err != nil {
+			return "", err
+		}
+		next, ok := parseWireName(body.Data)
+		if !ok {
+			// Online signers answer with synthesized "\000." names that
+			// reveal nothing.
+			return "", fmt.Errorf("%w: synthesized NSEC for %s", ErrNotWalkable, name)
+		}
+		return next, nil
+	}
+	if err := p.SkipAllAnswers(); err != nil {
+		return "", err
+	}
+	for {
+		h, err := p.AuthorityHeader()
+		if err != nil {
+			break
+		}
+		if h.Type == typeNSEC3 {
+			return "", fmt.Errorf("%w: zone uses NSEC3", ErrNotWalkable)
+		}
+		if err := p.SkipAuthority(); err != nil {
+			break
+		}
+	}
+	return "", fmt.Errorf("%w: no NSEC record for %s", ErrNotWalkable, name)
+}
+
+// buildQuery packs a single-question query for name. dnssec sets the DO
+// bit; recursive sets RD for queries sent to recursive resolvers.
+func buildQuery(name string, qtype dnsmessage.Type, dnssec, recursive bool) ([]byte, error) {
+	qname, err := dnsmessage.NewName(strings.TrimSuffix(normalizeHost(name), ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+	var id [2]byte
+	_, _ = rand.Read(id[:])
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: binary.BigEndian.Uint16(id[:]), RecursionDesired: recursive},
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	if dnssec {
+		var opt dnsmessage.ResourceHeader
+		if err := opt.SetEDNS0(4096, dnsmessage.RCodeSuccess, true); err != nil {
+			return nil, err
+		}
+		msg.Additionals = []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}}
+	}
+	return msg.Pack()
+}
+
+func exchangeUDP(ctx context.Context, addr string, query []byte, timeout time.Duration) ([]byte, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore stray datagrams that do not answer this query.
+		if n >= 2 && buf[0] == query[0] && buf[1] == query[1] {
+			return buf[:n], nil
+		}
+	}
+}
+
+func exchangeTCP(ctx context.Context, addr string, query []byte, timeout time.Duration) ([]byte, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if err := writeTCPMessage(conn, query); err != nil {
+		return nil, err
+	}
+	return readTCPMessage(conn)
+}
+
+func writeTCPMessage(conn net.Conn, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := conn.Write(buf)
+	return err
+}
+
+func readTCPMessage(conn net.Conn) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parseWireName decodes the uncompressed name at the start of an NSEC
+// RDATA. Names with labels that are not plain hostname characters are
+// rejected.
+func parseWireName(data []byte) (string, bool) {
+	var labels []string
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+		if n == 0 {
+			return strings.Join(labels, "."), len(labels) > 0
+		}
+		if n > 63 || i+n > len(data) {
+			return "", false
+		}
+		label := strings.ToLower(string(data[i : i+n]))
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' && r != '*' {
+				return "", false
+			}
+		}
+		labels = append(labels, label)
+		i += n
+	}
+	return "", false
+}
+
+func typeName(t dnsmessage.Type) string {
+	return strings.TrimPrefix(t.String(), "Type")
+}
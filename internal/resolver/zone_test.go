@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func wireName(labels ...string) []byte {
+	var out []byte
+	for _, label := range labels {
+		out = append(out, byte(len(label)))
+		out = append(out, label...)
+	}
+	return append(out, 0)
+}
+
+func TestParseWireName(t *testing.T) {
+	// NSEC RDATA is the next name followed by the type bitmap.
+	bitmap := []byte{0, 6, 0x40, 0x01, 0, 0, 0, 0x03}
+	tests := []struct {
+		name   string
+		data   []byte
+		want   string
+		wantOK bool
+	}{
+		{"plain name", wireName("www", "example", "com"), "www.example.com", true},
+		{"trailing type bitmap", append(wireName("Mail", "Example", "com"), bitmap...), "mail.example.com", true},
+		{"wildcard and underscore", wireName("*", "_sip", "example", "com"), "*._sip.example.com", true},
+		{"synthesized name", wireName("\x00", "example", "com"), "", false},
+		{"root only", []byte{0}, "", false},
+		{"truncated label", []byte{5, 'a', 'b'}, "", false},
+		{"missing terminator", []byte{3, 'w', 'w', 'w'}, "", false},
+		{"label too long", append([]byte{64}, make([]byte, 64)...), "", false},
+		{"compression pointer", []byte{0xc0, 0x0c}, "", false},
+		{"empty", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseWireName(tt.data)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseWireName() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		host      string
+		qtype     dnsmessage.Type
+		dnssec    bool
+		recursive bool
+		wantName  string
+	}{
+		{"recursive A", "WWW.Example.com", dnsmessage.TypeA, false, true, "www.example.com."},
+		{"nsec walk", "example.com.", typeNSEC, true, false, "example.com."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := buildQuery(tt.host, tt.qtype, tt.dnssec, tt.recursive)
+			if err != nil {
+				t.Fatalf("buildQuery: %v", err)
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(raw); err != nil {
+				t.Fatalf("Unpack: %v", err)
+			}
+			if len(msg.Questions) != 1 {
+				t.Fatalf("questions = %d, want 1", len(msg.Questions))
+			}
+			q := msg.Questions[0]
+			if q.Name.String() != tt.wantName || q.Type != tt.qtype || q.Class != dnsmessage.ClassINET {
+				t.Errorf("question = %s %s %s, want %s %s IN", q.Name, q.Type, q.Class, tt.wantName, tt.qtype)
+			}
+			if msg.RecursionDesired != tt.recursive {
+				t.Errorf("RD = %v, want %v", msg.RecursionDesired, tt.recursive)
+			}
+			var do bool
+			for _, rr := range msg.Additionals {
+				if rr.Header.Type == dnsmessage.TypeOPT {
+					do = rr.Header.DNSSECAllowed()
+				}
+			}
+			if do != tt.dnssec {
+				t.Errorf("DO bit = %v, want %v", do, tt.dnssec)
+			}
+		})
+	}
+}
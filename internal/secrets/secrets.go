@@ -0,0 +1,92 @@
+// Package secrets encrypts credentials stored in the database with
+// AES-256-GCM. The key comes from the SECRETS_KEY environment variable.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const sealedPrefix = "v1:"
+
+// ErrNoKey is returned when SECRETS_KEY is not set.
+var ErrNoKey = errors.New("SECRETS_KEY is not configured")
+
+// Box seals and opens secrets with one key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox creates a box for key. A 64-character hex or 44-character base64
+// key is used as is; any other value is treated as a passphrase and hashed
+// with SHA-256.
+func NewBox(key string) (*Box, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, ErrNoKey
+	}
+	raw := decodeKey(key)
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// NewBoxFromEnv creates a box from SECRETS_KEY.
+func NewBoxFromEnv() (*Box, error) {
+	return NewBox(os.Getenv("SECRETS_KEY"))
+}
+
+func decodeKey(key string) []byte {
+	if b, err := hex.DecodeString(key); err == nil && len(b) == 32 {
+		return b
+	}
+	if b, err := base64.StdEncoding.DecodeString(key); err == nil && len(b) == 32 {
+		return b
+	}
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// Seal encrypts plaintext into a printable string.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal.
+func (b *Box) Open(sealed string) (string, error) {
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return "", fmt.Errorf("unknown secret format")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	n := b.aead.NonceSize()
+	if len(raw) < n {
+		return "", fmt.Errorf("secret too short")
+	}
+	plain, err := b.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret: %v", err)
+	}
+	return string(plain), nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,9 +15,22 @@ import (
 	commonpkg "hunter/internal/common"
 	"hunter/internal/db"
 	"hunter/internal/engine"
+	"hunter/internal/pipelines"
 	"hunter/internal/plugins"
+	"hunter/internal/resolver"
 )
 
+// databaseDSN is the connection string shared by every mode that opens the database.
+const databaseDSN = "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
+
+// networkRecursion configures recursive discovery for CLI network stages.
+// Without InScope, new hosts must share a root domain with the stage input.
+var networkRecursion engine.Recursion
+
+// passiveZoneTransfer adds AXFR/NSEC walking to CLI passive collection. It is
+// set by the zonetransfer module.
+var passiveZoneTransfer bool
+
 func main() {
 	runMode := flag.String("mode", "scan", "Run mode: scan, monitor, web, or worker")
 	webAddr := flag.String("web-addr", "0.0.0.0:8080", "API server listen address (web mode)")
@@ -25,14 +39,17 @@ func main() {
 	domainList := flag.String("dL", "", "Root domain list file")
 	inputFile := flag.String("i", "", "Input file for ports/witness modules")
 
-	modules := flag.String("m", "", "Modules: subs,ports,witness (comma-separated)")
+	modules := flag.String("m", "", "Modules: subs,zonetransfer,ports,witness (comma-separated)")
+	pipelineName := flag.String("pipeline", "", "Run a named declarative pipeline instead of -m modules")
 
 	dryRun := flag.Bool("dry-run", false, "Dry-run mode; do not write database")
+	planOnly := flag.Bool("plan", false, "Print the scan plan (stages, inputs, missing tools, estimate) without running it")
 	screenshotDir := flag.String("screenshot-dir", "screenshots", "Screenshot output directory")
 	enableNuclei := flag.Bool("nuclei", false, "Enable Nuclei vulnerability scanning")
 	enableActiveSubs := flag.Bool("active-subs", false, "Enable active subdomain bruteforce after passive stage")
 	dictSize := flag.Int("dict-size", 1500, "Dictionary size cap for active subdomain bruteforce")
 	dnsResolvers := flag.String("dns-resolvers", "", "Optional dnsx resolvers file path")
+	recurseDepth := flag.Int("recurse-depth", 0, "Re-scan newly discovered in-scope hosts for up to N extra hops (0 disables)")
 	enableNotify := flag.Bool("notify", false, "Enable Feishu start/end notification")
 	monitorInterval := flag.String("monitor-interval", "6h", "Monitor interval, e.g. 30m / 1h / 6h")
 	monitorList := flag.Bool("monitor-list", false, "List current monitor targets")
@@ -47,6 +64,8 @@ func main() {
 	listScreenshots := flag.Bool("list-screenshots", false, "List domains with screenshots")
 
 	flag.Parse()
+	plugins.LoadExternalPluginsFromEnv()
+	networkRecursion = engine.Recursion{MaxDepth: *recurseDepth}
 	if *enableNotify && strings.TrimSpace(os.Getenv("FEISHU_WEBHOOK")) == "" {
 		fmt.Println("[WARN] -notify is enabled but FEISHU_WEBHOOK is not set; notifications will be disabled.")
 	}
@@ -55,8 +74,7 @@ func main() {
 
 	// ── Web API Server Mode ──
 	if mode == "web" {
-		dsn := "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
-		database, err := db.NewDatabase(dsn)
+		database, err := db.NewDatabase(databaseDSN)
 		if err != nil {
 			log.Fatalf("database connection failed: %v", err)
 		}
@@ -67,8 +85,7 @@ func main() {
 
 	// ── Worker Mode ──
 	if mode == "worker" {
-		dsn := "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
-		database, err := db.NewDatabaseNoMigrate(dsn)
+		database, err := db.NewDatabaseNoMigrate(databaseDSN)
 		if err != nil {
 			log.Fatalf("database connection failed: %v", err)
 		}
@@ -82,8 +99,7 @@ func main() {
 	}
 
 	if mode == "monitor" && (*monitorList || strings.TrimSpace(*monitorStop) != "" || strings.TrimSpace(*monitorDelete) != "") {
-		dsn := "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
-		database, err := db.NewDatabase(dsn)
+		database, err := db.NewDatabase(databaseDSN)
 		if err != nil {
 			log.Fatalf("database connection failed: %v", err)
 		}
@@ -138,8 +154,7 @@ func main() {
 	}
 
 	if mode == "scan" && (*scanListDomains || strings.TrimSpace(*scanDeleteDomain) != "") {
-		dsn := "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
-		database, err := db.NewDatabase(dsn)
+		database, err := db.NewDatabase(databaseDSN)
 		if err != nil {
 			log.Fatalf("database connection failed: %v", err)
 		}
@@ -195,8 +210,7 @@ func main() {
 		if err != nil || interval <= 0 {
 			log.Fatalf("invalid monitor-interval: %s", *monitorInterval)
 		}
-		dsn := "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
-		monDB, err := db.NewDatabase(dsn)
+		monDB, err := db.NewDatabase(databaseDSN)
 		if err != nil {
 			log.Fatalf("database connection failed: %v", err)
 		}
@@ -222,7 +236,16 @@ func main() {
 		return
 	}
 
-	enableSubs, enablePorts, enableWitness := parseModules(*modules)
+	enableSubs, enablePorts, enableWitness, enableZoneTransfer := parseModules(*modules)
+	usePipeline := strings.TrimSpace(*pipelineName) != ""
+	if usePipeline {
+		// Pipelines start from root domains (-d/-dL) or from a target list (-i/stdin).
+		enableSubs = *domain != "" || *domainList != ""
+		enablePorts = false
+		enableWitness = false
+		enableZoneTransfer = false
+	}
+	passiveZoneTransfer = enableZoneTransfer
 
 	if !validateInput(*domain, *domainList, *inputFile, enableSubs, enablePorts, enableWitness) {
 		printUsage()
@@ -245,8 +268,19 @@ func main() {
 		log.Fatalf("empty input")
 	}
 
+	if *planOnly {
+		printScanPlan(strings.TrimSpace(*projectID), input, enableSubs, enablePorts, enableWitness, *enableNuclei, *enableActiveSubs, *dictSize, *recurseDepth, strings.TrimSpace(*pipelineName))
+		return
+	}
+
 	printRunInfo(enableSubs, enablePorts, enableWitness, *enableNuclei, *enableActiveSubs, *dryRun, len(input))
 	modulesList := buildModules(enableSubs, enablePorts, enableWitness, *enableNuclei, *enableActiveSubs)
+	if passiveZoneTransfer {
+		modulesList = append(modulesList, "zonetransfer")
+	}
+	if usePipeline {
+		modulesList = []string{"pipeline:" + strings.TrimSpace(*pipelineName)}
+	}
 	notifier := plugins.NewFeishuNotifierFromEnv(*enableNotify)
 	scanStartTime := time.Now()
 	if notifier.Enabled() {
@@ -268,8 +302,7 @@ func main() {
 	var database *db.Database
 	var beforeAssetCount, beforePortCount, beforeVulnCount int64
 	if !*dryRun {
-		dsn := "host=localhost user=hunter password=hunter123 dbname=hunter port=5432 sslmode=disable"
-		database, err = db.NewDatabase(dsn)
+		database, err = db.NewDatabase(databaseDSN)
 		if err != nil {
 			failExit("database connection failed: %v", err)
 		}
@@ -284,6 +317,13 @@ func main() {
 	clampedDictSize := clampDictSize(*dictSize)
 	dnsResolversFile := strings.TrimSpace(*dnsResolvers)
 	switch {
+	case usePipeline:
+		results, err = runNamedPipeline(strings.TrimSpace(*pipelineName), input, plugins.ScannerConfig{
+			RootDomains:   input,
+			ScreenshotDir: *screenshotDir,
+			DNSResolvers:  dnsResolversFile,
+			DictSize:      clampedDictSize,
+		})
 	case enableSubs && !enablePorts && !enableWitness:
 		results, err = runSubsOnly(input, *enableActiveSubs, clampedDictSize, dnsResolversFile)
 	case !enableSubs && enablePorts && !enableWitness:
@@ -320,13 +360,14 @@ func main() {
 	printSummary(results, scanStartTime, *dryRun, database, beforeAssetCount, beforePortCount, beforeVulnCount, *screenshotDir, enableWitness)
 }
 
-func parseModules(modules string) (bool, bool, bool) {
+func parseModules(modules string) (bool, bool, bool, bool) {
 	enableSubs := false
 	enablePorts := false
 	enableWitness := false
+	enableZoneTransfer := false
 
 	if modules == "" {
-		return true, true, true
+		return true, true, true, false
 	}
 
 	modList := strings.Split(strings.ToLower(modules), ",")
@@ -334,16 +375,19 @@ func parseModules(modules string) (bool, bool, bool) {
 		switch strings.TrimSpace(m) {
 		case "subs":
 			enableSubs = true
+		case "zonetransfer", "axfr", "nsec_walk":
+			enableSubs = true
+			enableZoneTransfer = true
 		case "ports":
 			enablePorts = true
 		case "witness":
 			enableWitness = true
 		default:
-			log.Fatalf("unknown module: %s (valid: subs, ports, witness)", m)
+			log.Fatalf("unknown module: %s (valid: subs, zonetransfer, ports, witness)", m)
 		}
 	}
 
-	return enableSubs, enablePorts, enableWitness
+	return enableSubs, enablePorts, enableWitness, enableZoneTransfer
 }
 
 func validateInput(domain, domainList, inputFile string, subs, ports, witness bool) bool {
@@ -407,15 +451,17 @@ func printUsage() {
 	fmt.Println("  List scan domains: go run . -mode scan -scan-list-domains")
 	fmt.Println("  Delete scan data:  go run . -mode scan -scan-delete-domain example.com")
 	fmt.Println()
-	fmt.Println("Modules (-m): subs, ports, witness")
+	fmt.Println("Modules (-m): subs, zonetransfer, ports, witness")
 	fmt.Println("Examples:")
 	fmt.Println("  go run . -m subs -d example.com")
 	fmt.Println("  go run . -m ports -i subdomains.txt -nuclei")
 	fmt.Println("  go run . -m witness -i urls.txt")
 	fmt.Println("  go run . -m subs,ports -d example.com -active-subs -dict-size 800")
+	fmt.Println("  go run . -pipeline httpx-nuclei-200 -d example.com")
 	fmt.Println()
 	fmt.Println("Main flags:")
 	fmt.Println("  -project           Project ID for data isolation (default: default)")
+	fmt.Println("  -pipeline          Run a named pipeline (built-in or PIPELINE_DEFS_DIR)")
 	fmt.Println("  -active-subs       Enable active subdomain bruteforce (passive-token + dnsx)")
 	fmt.Println("  -dict-size         Dictionary cap for active bruteforce (default 800)")
 	fmt.Println("  -dns-resolvers     Optional resolvers file for dnsx")
@@ -524,6 +570,95 @@ func buildModules(subs, ports, witness, nuclei, activeSubs bool) []string {
 	return mods
 }
 
+// printScanPlan prints what a CLI scan would run. CLI network stages always
+// use naabu+nmap.
+func printScanPlan(projectID string, input []string, subs, ports, witness, nuclei, activeSubs bool, dictSize, recurseDepth int, pipelineName string) {
+	in := api.ScanPlanInput{
+		ProjectID:    projectID,
+		Pipeline:     pipelineName,
+		EnableNuclei: nuclei,
+		ActiveSubs:   activeSubs,
+		DictSize:     dictSize,
+		RecurseDepth: recurseDepth,
+		PortEngine:   "naabu_nmap",
+	}
+	if subs {
+		in.RootDomains = input
+		in.Modules = append(in.Modules, "subs")
+		if passiveZoneTransfer {
+			in.Modules = append(in.Modules, "zonetransfer")
+		}
+		if activeSubs {
+			in.Modules = append(in.Modules, "dnsx_bruteforce")
+		}
+	} else {
+		in.Hosts = input
+		seenRoots := make(map[string]bool)
+		for _, item := range input {
+			if root := plugins.ExtractRootDomain(extractDomainFromURL(item)); root != "" && !seenRoots[root] {
+				seenRoots[root] = true
+				in.RootDomains = append(in.RootDomains, root)
+			}
+		}
+	}
+	if ports {
+		in.Modules = append(in.Modules, "ports")
+	}
+	if witness {
+		in.Modules = append(in.Modules, "witness")
+	}
+	if ports || witness {
+		in.Modules = append(in.Modules, "httpx")
+	}
+	if nuclei {
+		in.Modules = append(in.Modules, "nuclei")
+	}
+
+	database, err := db.NewDatabaseNoMigrate(databaseDSN)
+	if err != nil {
+		fmt.Printf("[WARN] database connection failed, planning without history: %v\n", err)
+		database = nil
+	}
+	plan, err := api.BuildScanPlan(database, in)
+	if err != nil {
+		log.Fatalf("failed to build scan plan: %v", err)
+	}
+
+	fmt.Println("================================================")
+	fmt.Println("Scan plan (nothing will be run)")
+	fmt.Printf("Project: %s\n", plan.ProjectID)
+	fmt.Printf("Input count: %d\n", len(input))
+	if plan.Pipeline != "" {
+		fmt.Printf("Pipeline: %s\n", plan.Pipeline)
+	} else {
+		fmt.Printf("Modules: %s\n", strings.Join(plan.Modules, ","))
+	}
+	fmt.Printf("Known subdomains: %d, live URLs: %d\n", plan.KnownSubdomains, plan.LiveURLs)
+	fmt.Println("Stages:")
+	for _, stage := range plan.Stages {
+		line := fmt.Sprintf("  %-28s input=%-6d tools=%s", stage.Stage, stage.InputCount, strings.Join(stage.Tools, ","))
+		if stage.Samples > 0 {
+			line += fmt.Sprintf(" est=%s", (time.Duration(stage.EstimatedSec) * time.Second).String())
+		}
+		if len(stage.MissingTools) > 0 {
+			line += " missing=" + strings.Join(stage.MissingTools, ",")
+		}
+		fmt.Println(line)
+	}
+	if len(plan.MissingTools) > 0 {
+		fmt.Printf("Missing from PATH: %s\n", strings.Join(plan.MissingTools, ", "))
+	}
+	if plan.EstimateSource == "none" {
+		fmt.Println("Estimated duration: unknown (no scan history)")
+	} else {
+		fmt.Printf("Estimated duration: %s (from %s)\n", (time.Duration(plan.EstimatedDurationSec) * time.Second).String(), plan.EstimateSource)
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("[WARN] %s\n", warning)
+	}
+	fmt.Println("================================================")
+}
+
 func printRunInfo(subs, ports, witness, nuclei, activeSubs, dryRun bool, inputCount int) {
 	var mods []string
 	if subs {
@@ -568,10 +703,11 @@ func runSubsOnly(domains []string, activeSubs bool, dictSize int, dnsResolvers s
 func runPortsOnly(subdomains []string, nucleiEnabled bool) ([]engine.Result, error) {
 	fmt.Println("==> [Stage] Port Scan")
 	pipeline := engine.NewPipeline()
-
-	pipeline.SetHttpxScanner(plugins.NewHttpxPlugin())
+	pipeline.SetRecursion(networkRecursion)
+	pipeline.SetHttpxScanner(plugins.NewWebProbePlugin())
 	pipeline.AddPortScanner(plugins.NewNaabuPlugin())
 	pipeline.AddPortScanner(plugins.NewNmapPlugin())
+	pipeline.SetTLSScanner(plugins.NewTLSGrabPlugin())
 	if nucleiEnabled {
 		pipeline.SetVulnScanner(plugins.NewNucleiPlugin())
 	}
@@ -601,9 +737,11 @@ func runSubsAndPorts(domains []string, nucleiEnabled, activeSubs bool, dictSize
 	}
 
 	pipeline := engine.NewPipeline()
-	pipeline.SetHttpxScanner(plugins.NewHttpxPlugin())
+	pipeline.SetRecursion(networkRecursion)
+	pipeline.SetHttpxScanner(plugins.NewWebProbePlugin())
 	pipeline.AddPortScanner(plugins.NewNaabuPlugin())
 	pipeline.AddPortScanner(plugins.NewNmapPlugin())
+	pipeline.SetTLSScanner(plugins.NewTLSGrabPlugin())
 	if nucleiEnabled {
 		pipeline.SetVulnScanner(plugins.NewNucleiPlugin())
 	}
@@ -620,7 +758,8 @@ func runSubsAndWitness(domains []string, screenshotDir string, nucleiEnabled, ac
 	}
 
 	pipeline := engine.NewPipeline()
-	pipeline.SetHttpxScanner(plugins.NewHttpxPlugin())
+	pipeline.SetRecursion(networkRecursion)
+	pipeline.SetHttpxScanner(plugins.NewWebProbePlugin())
 	pipeline.SetScreenshotScanner(plugins.NewGowitnessPlugin(screenshotDir))
 	if nucleiEnabled {
 		pipeline.SetVulnScanner(plugins.NewNucleiPlugin())
@@ -633,10 +772,11 @@ func runSubsAndWitness(domains []string, screenshotDir string, nucleiEnabled, ac
 func runPortsAndWitness(subdomains []string, screenshotDir string, nucleiEnabled bool) ([]engine.Result, error) {
 	fmt.Println("==> [Stage] Port Scan + Web Screenshot")
 	pipeline := engine.NewPipeline()
-
-	pipeline.SetHttpxScanner(plugins.NewHttpxPlugin())
+	pipeline.SetRecursion(networkRecursion)
+	pipeline.SetHttpxScanner(plugins.NewWebProbePlugin())
 	pipeline.AddPortScanner(plugins.NewNaabuPlugin())
 	pipeline.AddPortScanner(plugins.NewNmapPlugin())
+	pipeline.SetTLSScanner(plugins.NewTLSGrabPlugin())
 	pipeline.SetScreenshotScanner(plugins.NewGowitnessPlugin(screenshotDir))
 	if nucleiEnabled {
 		pipeline.SetVulnScanner(plugins.NewNucleiPlugin())
@@ -653,9 +793,11 @@ func runFullPipeline(domains []string, screenshotDir string, nucleiEnabled, acti
 	}
 
 	pipeline := engine.NewPipeline()
-	pipeline.SetHttpxScanner(plugins.NewHttpxPlugin())
+	pipeline.SetRecursion(networkRecursion)
+	pipeline.SetHttpxScanner(plugins.NewWebProbePlugin())
 	pipeline.AddPortScanner(plugins.NewNaabuPlugin())
 	pipeline.AddPortScanner(plugins.NewNmapPlugin())
+	pipeline.SetTLSScanner(plugins.NewTLSGrabPlugin())
 	pipeline.SetScreenshotScanner(plugins.NewGowitnessPlugin(screenshotDir))
 	if nucleiEnabled {
 		pipeline.SetVulnScanner(plugins.NewNucleiPlugin())
@@ -665,6 +807,21 @@ func runFullPipeline(domains []string, screenshotDir string, nucleiEnabled, acti
 	return append(subResults, networkResults...), err
 }
 
+func runNamedPipeline(name string, input []string, cfg plugins.ScannerConfig) ([]engine.Result, error) {
+	fmt.Printf("==> [Stage] Pipeline %s\n", name)
+	def, err := pipelines.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := engine.NewDAGPipeline(def, func(node engine.PipelineNode) (engine.Scanner, error) {
+		return plugins.NewScannerByName(node.Scanner, cfg, node.Options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.Execute(context.Background(), input)
+}
+
 func collectSubdomainsWithOptionalActive(rootDomains []string, activeSubs bool, dictSize int, dnsResolvers string) ([]engine.Result, []string, error) {
 	passiveResults, passiveSubdomains, err := runPassiveSubdomainCollection(rootDomains)
 	if err != nil {
@@ -674,23 +831,44 @@ func collectSubdomainsWithOptionalActive(rootDomains []string, activeSubs bool,
 	allResults := append([]engine.Result{}, passiveResults...)
 	finalSubdomains := append([]string{}, passiveSubdomains...)
 
-	if !activeSubs {
-		return allResults, finalSubdomains, nil
+	if activeSubs {
+		activeResults, activeSubdomains, err := runActiveSubdomainExpansion(rootDomains, passiveSubdomains, dictSize, dnsResolvers)
+		allResults = append(allResults, activeResults...)
+		if err != nil {
+			return allResults, nil, err
+		}
+
+		finalSubdomains = mergeUniqueDomains(passiveSubdomains, activeSubdomains)
+		added := len(finalSubdomains) - len(passiveSubdomains)
+		if added < 0 {
+			added = 0
+		}
+		fmt.Printf("[ActiveSubs] Added %d active subdomains (total=%d)\n", added, len(finalSubdomains))
 	}
 
-	activeResults, activeSubdomains, err := runActiveSubdomainExpansion(rootDomains, passiveSubdomains, dictSize, dnsResolvers)
-	allResults = append(allResults, activeResults...)
+	allResults, finalSubdomains = filterWildcardSubdomains(allResults, finalSubdomains, dnsResolvers)
+	return allResults, finalSubdomains, nil
+}
+
+// filterWildcardSubdomains removes wildcard hits from a CLI scan before its
+// results are saved. Set WILDCARD_FILTER=false to keep them.
+func filterWildcardSubdomains(results []engine.Result, subdomains []string, dnsResolvers string) ([]engine.Result, []string) {
+	if enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("WILDCARD_FILTER"))); (err == nil && !enabled) || len(subdomains) == 0 {
+		return results, subdomains
+	}
+	kept, dropped, zones, err := resolver.FilterWildcards(context.Background(), dnsResolvers, subdomains, 50)
 	if err != nil {
-		return allResults, nil, err
+		fmt.Printf("[Wildcard] Filter skipped: %v\n", err)
+		return results, subdomains
 	}
-
-	finalSubdomains = mergeUniqueDomains(passiveSubdomains, activeSubdomains)
-	added := len(finalSubdomains) - len(passiveSubdomains)
-	if added < 0 {
-		added = 0
+	for _, zone := range zones {
+		fmt.Printf("[Wildcard] Zone *.%s -> ips=%s cname=%s\n", zone.Zone, strings.Join(zone.IPs, ","), zone.CNAME)
 	}
-	fmt.Printf("[ActiveSubs] Added %d active subdomains (total=%d)\n", added, len(finalSubdomains))
-	return allResults, finalSubdomains, nil
+	if len(dropped) == 0 {
+		return results, subdomains
+	}
+	fmt.Printf("[Wildcard] Dropped %d wildcard subdomains (kept=%d)\n", len(dropped), len(kept))
+	return engine.WithoutDomains(results, dropped), kept
 }
 
 func runPassiveSubdomainCollection(domains []string) ([]engine.Result, []string, error) {
@@ -702,6 +880,13 @@ func runPassiveSubdomainCollection(domains []string) ([]engine.Result, []string,
 	pipeline.AddDomainScanner(plugins.NewFindomainPlugin())
 	pipeline.AddDomainScanner(plugins.NewBBOTPlugin(true))
 	pipeline.AddDomainScanner(plugins.NewShosubgoPlugin())
+	pipeline.AddDomainScanner(plugins.NewCTLogsPlugin("", ""))
+	if passiveZoneTransfer {
+		pipeline.AddDomainScanner(plugins.NewZoneTransferPlugin())
+	}
+	for _, scanner := range plugins.ExternalScanners(plugins.CategorySubdomain, plugins.ScannerConfig{RootDomains: domains}) {
+		pipeline.AddDomainScanner(scanner)
+	}
 
 	results, err := pipeline.Execute(context.Background(), domains)
 	subdomains := extractDomainResults(results)
@@ -724,13 +909,13 @@ func runActiveSubdomainExpansion(rootDomains, passiveSubdomains []string, dictSi
 	}
 	fmt.Printf("[ActiveSubs] Built %d words from passive subdomains\n", len(words))
 
-	brutePlugin := plugins.NewDNSXBruteforcePlugin(rootDomains, dnsResolvers)
+	brutePlugin := plugins.NewActiveBruteforcePlugin(rootDomains, dnsResolvers)
 	bruteResults, bruteErr := executeScannerWithStatus(brutePlugin, words)
 	allResults = append(allResults, bruteResults...)
 
 	if bruteErr != nil {
 		if strings.Contains(bruteErr.Error(), "not found in PATH") {
-			fmt.Printf("[DNSXBruteforce] Tool missing, skip active brute-force: %v\n", bruteErr)
+			fmt.Printf("[%s] Tool missing, skip active brute-force: %v\n", brutePlugin.Name(), bruteErr)
 			return allResults, []string{}, nil
 		}
 		return allResults, nil, bruteErr
@@ -912,6 +1097,14 @@ func saveResultsWithContext(database *db.Database, results []engine.Result, proj
 	}
 
 	for _, result := range results {
+		if result.Type == engine.ResultTypeZoneExposure {
+			exposure, err := engine.DecodeResult[engine.ZoneExposure](result)
+			if err != nil {
+				recordFailure("dns_zone_exposure", err)
+				continue
+			}
+			result = exposure.Vulnerability().Result()
+		}
 		sourceModule := result.Type
 		switch result.Type {
 		case "domain":
@@ -925,6 +1118,8 @@ func saveResultsWithContext(database *db.Database, results []engine.Result, proj
 				}
 				normalizeRoot(record)
 				recordFailure("asset_candidate(domain)", database.SaveOrUpdateAssetCandidate(record))
+				rootDomain, _ := record["root_domain"].(string)
+				recordFailure("asset_source(domain)", database.RecordAssetSources(projectID, rootDomain, subdomain, sourceJobID, plugins.ResultSources(result)))
 			}
 		case "web_service":
 			if data, ok := result.Data.(map[string]interface{}); ok {
@@ -976,6 +1171,41 @@ func saveResultsWithContext(database *db.Database, results []engine.Result, proj
 				normalizeRoot(data)
 				recordFailure("vulnerability", database.SaveOrUpdateVulnerability(data))
 			}
+		case engine.ResultTypeDNSRecords:
+			records, err := engine.DecodeResult[engine.DNSRecords](result)
+			if err == nil {
+				record := map[string]interface{}{"host": records.Host}
+				normalizeRoot(record)
+				rootDomain, _ := record["root_domain"].(string)
+				_, _, err = database.SyncDNSRecords(projectID, rootDomain, records.Host, sourceJobID, records.ByType(), records.Failed)
+			}
+			recordFailure("dns_records", err)
+		case engine.ResultTypeTLSCertificate:
+			cert, err := engine.DecodeResult[engine.TLSCertificate](result)
+			if err != nil {
+				recordFailure("tls_certificate", err)
+				continue
+			}
+			data := cert.Result().Data.(map[string]interface{})
+			data["project_id"] = projectID
+			data["source_job_id"] = sourceJobID
+			normalizeRoot(data)
+			_, err = database.SaveCertificate(data)
+			recordFailure("tls_certificate", err)
+			rootDomain, _ := data["root_domain"].(string)
+			for _, san := range api.CertificateSANCandidates(nil, cert, rootDomain) {
+				record := map[string]interface{}{
+					"project_id":    projectID,
+					"source_job_id": sourceJobID,
+					"source_module": sourceModule,
+					"domain":        san,
+					"verify_status": "pending",
+				}
+				normalizeRoot(record)
+				recordFailure("asset_candidate(tls_certificate)", database.SaveOrUpdateAssetCandidate(record))
+				sanRoot, _ := record["root_domain"].(string)
+				recordFailure("asset_source(tls_certificate)", database.RecordAssetSources(projectID, sanRoot, san, sourceJobID, []string{sourceModule}))
+			}
 		}
 	}
 
@@ -1081,7 +1311,7 @@ func collectResultCounts(results []engine.Result) map[string]int {
 			counts["web_services"]++
 		case "port_service", "open_port":
 			counts["ports"]++
-		case "vulnerability":
+		case "vulnerability", engine.ResultTypeZoneExposure:
 			counts["vulnerabilities"]++
 		case "screenshot":
 			if data, ok := result.Data.(map[string]interface{}); ok {